@@ -89,6 +89,7 @@ func run() (bool, error) {
 		cmds.Group("record", "record commands", func() {
 			cmds.New("show", "show a record", new(cmdRecordShow))
 			cmds.New("invalidate", "invalidate a record", new(cmdRecordInvalidate))
+			cmds.New("invalidate-many", "invalidate records listed in a file with a shared reason", new(cmdRecordInvalidateMany))
 			cmds.New("unpublish", "unpublish a record", new(cmdRecordUnpublish))
 			cmds.New("delete", "delete a record", new(cmdRecordDelete))
 		})
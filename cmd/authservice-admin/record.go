@@ -107,6 +107,54 @@ func (cmd *cmdRecordInvalidate) Execute(ctx context.Context) error {
 	return authClient.Invalidate(ctx, cmd.key, cmd.reason)
 }
 
+type cmdRecordInvalidateMany struct {
+	authClientConfig authadminclient.Config
+	satAdminClients  map[string]*satelliteadminclient.Client
+	output           string
+	inputFilePath    string
+	reason           string
+}
+
+func (cmd *cmdRecordInvalidateMany) Setup(params clingy.Parameters) {
+	cmd.authClientConfig = getAuthAdminClientConfig(params)
+	cmd.satAdminClients = mustSatAdminClients(params)
+	cmd.output = params.Flag("output", "output format (either json or leave empty to output as text)", "", clingy.Short('o')).(string)
+	cmd.inputFilePath = params.Arg("input", "input file path with an access key ID or key hash per line").(string)
+	cmd.reason = params.Arg("reason", "invalidation reason").(string)
+}
+
+func (cmd *cmdRecordInvalidateMany) Execute(ctx context.Context) error {
+	keys, err := scanURLs(cmd.inputFilePath)
+	if err != nil {
+		return err
+	}
+
+	authClient, err := authadminclient.Open(ctx, cmd.authClientConfig, zapLogger)
+	if err != nil {
+		return errs.New("open auth admin client: %w", err)
+	}
+	defer func() { _ = authClient.Close() }()
+
+	results, err := authClient.InvalidateMany(ctx, keys, cmd.reason)
+	if err != nil {
+		return err
+	}
+
+	switch cmd.output {
+	case "json":
+		return json.NewEncoder(os.Stdout).Encode(&results)
+	default:
+		for _, r := range results {
+			status := "invalidated"
+			if r.Error != "" {
+				status = r.Error
+			}
+			printFixed(r.Key, status)
+		}
+		return nil
+	}
+}
+
 type cmdRecordUnpublish struct {
 	authClientConfig authadminclient.Config
 	satAdminClients  map[string]*satelliteadminclient.Client
@@ -5,6 +5,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -61,9 +62,16 @@ var (
 	confDir string
 
 	registerCfg struct {
-		Address   string `help:"authservice to register access to" dev:"drpc://localhost:20002" release:"drpcs://auth.storjshare.io:7777"`
-		Public    bool   `help:"whether access grant can be retrieved from authservice by providing only Access Key ID without Secret Access Key" default:"false"`
-		FormatEnv bool   `help:"environmental-variable format of credentials; for using in scripts" default:"false"`
+		Address   string        `help:"authservice to register access to" dev:"drpc://localhost:20002" release:"drpcs://auth.storjshare.io:7777"`
+		Public    bool          `help:"whether access grant can be retrieved from authservice by providing only Access Key ID without Secret Access Key" default:"false"`
+		TTL       time.Duration `help:"time after which the credentials expire, even if the access grant expires later; requires an HTTP address. 0 means they expire with the access grant" default:"0"`
+		FormatEnv bool          `help:"environmental-variable format of credentials; for using in scripts; same as --format env" default:"false"`
+		Format    string        `help:"format of credentials: text, env or json; env and json are meant for scripts" default:"text"`
+
+		CACert             string `help:"path to a PEM file of CA certificates trusted in addition to the system ones for drpcs:// and https:// addresses, e.g. of self-hosted authservices" default:""`
+		InsecureSkipVerify bool   `help:"don't verify the certificate of the authservice; for testing only" default:"false"`
+
+		ValidateOnly bool `help:"only check that the access grant is valid and its satellite accepts it, without registering it" default:"false"`
 	}
 )
 
@@ -155,7 +163,24 @@ func cmdSetup(cmd *cobra.Command, _ []string) error {
 func cmdRegister(cmd *cobra.Command, args []string) error {
 	ctx, _ := process.Ctx(cmd)
 
-	res, err := register.Access(ctx, registerCfg.Address, args[0], registerCfg.Public)
+	format := registerCfg.Format
+	if registerCfg.FormatEnv {
+		format = formatEnv
+	}
+	switch format {
+	case formatText, formatEnv, formatJSON:
+	default:
+		return errs.New("unknown format %q", format)
+	}
+
+	if registerCfg.ValidateOnly {
+		return validateAccess(ctx, args[0], format)
+	}
+
+	res, err := register.AccessWithTLS(ctx, registerCfg.Address, args[0], registerCfg.Public, registerCfg.TTL, register.TLSOptions{
+		CACertPath:         registerCfg.CACert,
+		InsecureSkipVerify: registerCfg.InsecureSkipVerify,
+	})
 	if err != nil {
 		return err
 	}
@@ -166,22 +191,70 @@ func cmdRegister(cmd *cobra.Command, args []string) error {
 				"Upgrade to a Pro account to remove expiration limits.",
 			res.FreeTierRestrictedExpiration.Format(time.RFC3339),
 		)
-		if registerCfg.FormatEnv {
-			notice = bashComment(notice)
+		switch format {
+		case formatEnv:
+			fmt.Println(bashComment(notice))
+		case formatJSON:
+			// the expiration is part of the JSON output; keep stdout parsable.
+			fmt.Fprintln(os.Stderr, notice)
+		default:
+			fmt.Println(notice)
 		}
-		fmt.Println(notice)
 	}
 
-	if registerCfg.FormatEnv {
+	switch format {
+	case formatEnv:
 		fmt.Printf("AWS_ACCESS_KEY_ID=%s\nAWS_SECRET_ACCESS_KEY=%s\nAWS_ENDPOINT=%s\n",
 			res.AccessKeyID, res.SecretKey, res.Endpoint)
-	} else {
+	case formatJSON:
+		out, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	default:
 		fmt.Println(res)
 	}
 
 	return nil
 }
 
+// validateAccess prints the diagnostics of access in format, and returns an
+// error if it's invalid.
+func validateAccess(ctx context.Context, access, format string) error {
+	diagnostics, validationErr := register.Validate(ctx, access)
+
+	switch format {
+	case formatJSON:
+		out := struct {
+			register.Diagnostics
+			Valid bool   `json:"valid"`
+			Error string `json:"error,omitempty"`
+		}{Diagnostics: diagnostics, Valid: validationErr == nil}
+		if validationErr != nil {
+			out.Error = validationErr.Error()
+		}
+		b, err := json.Marshal(out)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	case formatEnv:
+		fmt.Println(bashComment(diagnostics.String()))
+	default:
+		fmt.Println(diagnostics)
+	}
+
+	return validationErr
+}
+
+// Formats of the credentials printed by the register command.
+const (
+	formatText = "text"
+	formatEnv  = "env"
+	formatJSON = "json"
+)
+
 func bashComment(s string) string {
 	lines := strings.Split(s, "\n")
 	for i, line := range lines {
@@ -108,6 +108,7 @@ func cmdRun(cmd *cobra.Command, _ []string) (err error) {
 	} else {
 		trustedClientIPs = trustedip.NewListUntrustAll()
 	}
+	trustedClientIPs = trustedClientIPs.WithTrustedHops(runCfg.ClientTrustedHops)
 
 	corsAllowedOrigins := strings.Split(runCfg.CorsOrigins, ",")
 
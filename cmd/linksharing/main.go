@@ -5,6 +5,7 @@ package main
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -21,10 +22,13 @@ import (
 	"storj.io/common/identity"
 	"storj.io/common/process"
 	"storj.io/edge/pkg/authclient"
+	"storj.io/edge/pkg/bucketconfig"
 	"storj.io/edge/pkg/httpserver"
 	"storj.io/edge/pkg/linksharing"
+	"storj.io/edge/pkg/linksharing/objectranger"
 	"storj.io/edge/pkg/linksharing/sharing"
 	"storj.io/edge/pkg/linksharing/sharing/assets"
+	gwmiddleware "storj.io/edge/pkg/server/middleware"
 	"storj.io/edge/pkg/tierquery"
 	"storj.io/edge/pkg/uplinkutil"
 	"storj.io/uplink"
@@ -40,24 +44,51 @@ type LinkSharing struct {
 	InsecureDisableTLS     bool          `user:"true" help:"listen using insecure connections only" releaseDefault:"false" devDefault:"true"`
 	CertFile               string        `user:"true" help:"server certificate file"`
 	KeyFile                string        `user:"true" help:"server key file"`
+	SNICertificates        []string      `user:"true" help:"list of certificates (comma separated) served for specific hosts instead of the default certificate. Usage (colon-delimited): host:cert_file:key_file. host may start with *. to match any subdomain"`
+	CertReloadInterval     time.Duration `user:"true" help:"how often to check --cert-file, --key-file and --sni-certificates for changes and reload them without a restart; 0 disables reloading" default:"1m0s"`
+	MinTLSVersion          string        `user:"true" help:"minimum TLS version accepted: 1.2 or 1.3" default:"1.2"`
+	OCSPStapling           bool          `user:"true" help:"staple OCSP responses to the certificates from --cert-file and --sni-certificates; certificates managed by CertMagic always have them stapled" default:"false"`
 	PublicURL              string        `user:"true" help:"comma separated list of public urls for the server" devDefault:"http://localhost:20020" releaseDefault:""`
 	GeoLocationDB          string        `user:"true" help:"maxmind database file path"`
+	GeoLocationASNDB       string        `user:"true" help:"maxmind ASN database file path; optional, requires --geo-location-db"`
 	TXTRecordTTL           time.Duration `user:"true" help:"max ttl (seconds) for website hosting txt record cache" devDefault:"10s" releaseDefault:"1h"`
+	TXTRecordNegativeTTL   time.Duration `user:"true" help:"ttl for caching that the domain of a website hosting txt record lookup doesn't exist; 0 disables it" default:"30s"`
+	MapCacheExpiration     time.Duration `help:"how long the locations of the nodes storing an object are cached for rendering its map" default:"10m"`
+	MapCacheCapacity       int           `help:"maximum number of objects whose node locations are cached for rendering maps; 0 disables the cache" default:"1000"`
 	AuthService            authclient.Config
+	BucketConfigs          bucketconfig.Config
 	DNSServer              string        `user:"true" help:"dns server address to use for TXT resolution" default:"1.1.1.1:53"`
 	LandingRedirectTarget  string        `user:"true" help:"the url to redirect empty requests to" default:"https://www.storj.io/"`
+	LandingTemplate        string        `user:"true" help:"path to an HTML template rendered with .Host for empty requests instead of redirecting them to --landing-redirect-target"`
 	RedirectHTTPS          bool          `user:"true" help:"redirect to HTTPS" devDefault:"false" releaseDefault:"true"`
+	RedirectHTTPSStatus    int           `user:"true" help:"status code of redirects to HTTPS: 301, 302, 307 or 308. 301 and 302 are replaced by the method-preserving 308 and 307 for other requests than GET and HEAD" default:"308"`
 	DialTimeout            time.Duration `help:"timeout for dials" default:"10s"`
 	IdleTimeout            time.Duration `help:"timeout for idle connections" default:"60s"`
-	ClientTrustedIPSList   []string      `user:"true" help:"list of clients IPs (comma separated) which are trusted; usually used when the service run behinds gateways, load balancers, etc."`
+	ReadHeaderTimeout      time.Duration `help:"maximum time to read request headers; zero means no timeout" default:"0s"`
+	MaxConcurrentStreams   int           `help:"maximum number of concurrent HTTP/2 streams per connection; zero uses the default of 250" default:"0"`
+	ClientTrustedIPSList   []string      `user:"true" help:"list of clients IPs or CIDR ranges (comma separated) which are trusted; usually used when the service run behinds gateways, load balancers, etc."`
+	ClientTrustedHops      int           `user:"true" help:"number of proxies in front of the service, including the one connecting to it, whose X-Forwarded-For entries are skipped from the right to find the client IP; 0 uses the first entry" default:"0"`
 	UseClientIPHeaders     bool          `user:"true" help:"use the headers sent by the client to identify its IP. When true the list of IPs set by --client-trusted-ips-list, when not empty, is used" default:"true"`
 	StandardRendersContent bool          `user:"true" help:"enable standard (non-hosting) requests to render content and not only download it" default:"false"`
 	StandardViewsHTML      bool          `user:"true" help:"serve HTML as text/html instead of text/plain for standard (non-hosting) requests" default:"false"`
+	InlineContentTypes     string        `user:"true" help:"a comma separated list of content types rendered inline for standard (non-hosting) requests in addition to images and PDFs, e.g. image/webp,video/*"`
+	AttachmentContentTypes string        `user:"true" help:"a comma separated list of content types always downloaded as attachments for standard (non-hosting) requests, even with --standard-renders-content, e.g. text/html,application/javascript"`
+	ContentTypeOverrides   string        `user:"true" help:"a comma separated list of file extensions and the content types served for objects stored without one, e.g. .wasm=application/wasm,.md=text/markdown"`
+	SniffContentType       bool          `user:"true" help:"detect the content type of objects stored without a known one from their first 512 bytes" default:"false"`
 	ListPageLimit          int           `help:"maximum number of paths to list on a single page" default:"100"`
+	ListingDisabled        bool          `help:"respond with 404 Not Found to requests of prefixes instead of listing their objects" default:"false"`
+	DisableMap             bool          `user:"true" help:"disable the map of the nodes storing an object and geolocation lookups, even with --geo-location-db" default:"false"`
 	DownloadPrefixEnabled  bool          `help:"whether downloading a prefix as a zip or tar file is enabled" default:"false"`
 	DownloadZipLimit       int           `help:"maximum number of files from a prefix that can be packaged into a downloadable zip" default:"1000"`
 	DynamicAssetsDir       string        `help:"use a assets dir that is reparsed for every request" default:""`
 	BlockedPaths           string        `help:"a comma separated list of hosts and request uris to return unauthorized errors for. e.g. link.storjshare.io/raw/accesskey/bucket/path1"`
+	StrictQueryParams      bool          `user:"true" help:"reject standard (non-hosting) requests with unknown query parameters instead of ignoring them" default:"false"`
+	AllowedQueryParams     string        `user:"true" help:"a comma separated list of additional query parameters accepted with --strict-query-params, e.g. utm_source,utm_medium"`
+	IndexDocument          string        `user:"true" help:"name of the object served for requests of prefixes, e.g. /docs/, if it exists in the prefix" default:"index.html"`
+	ErrorDocument          string        `user:"true" help:"key, relative to the root of hosted sites, of the page served with 404 for requests of missing objects" default:"404.html"`
+	NotFoundTemplate       string        `user:"true" help:"path to an HTML template rendered with .Path and .Bucket for hosting requests of missing objects when the site has no error document of its own"`
+	Compression            bool          `user:"true" help:"compress text responses, e.g. HTML, CSS and JavaScript, with gzip for clients that accept it" default:"false"`
+	SignedLinksKey         string        `user:"true" help:"secret key to validate the signatures and expirations of signed links with; when set, standard (non-hosting) requests without a valid signature are rejected. Links are signed with the sign-url command"`
 
 	Client struct {
 		Identity uplinkutil.IdentityConfig
@@ -66,6 +97,9 @@ type LinkSharing struct {
 	SatelliteConnectionPool satelliteConnectionPoolConfig
 	ConnectionPool          connectionPoolConfig
 	Limits                  limitsConfig
+	DownloadRetry           objectranger.RetryConfig
+
+	SecurityHeaders gwmiddleware.SecurityHeadersConfig
 
 	CertMagic     certMagic
 	ShutdownDelay time.Duration `user:"true" help:"time to delay server shutdown while returning 503s on the health endpoint" devDefault:"1s" releaseDefault:"45s"`
@@ -90,20 +124,25 @@ type satelliteConnectionPoolConfig struct {
 
 // limitsConfig is a config struct for configuring request limiting behavior.
 type limitsConfig struct {
-	ConcurrentRequests uint `help:"the number of concurrent requests allowed per project ID, or if unavailable, macaroon head" default:"500"`
+	ConcurrentRequests           uint `help:"the number of concurrent requests allowed per project ID, or if unavailable, macaroon head" default:"500"`
+	ConcurrentDownloads          int  `help:"the number of object and prefix downloads served at a time; further downloads are rejected with 503 Service Unavailable. 0 means no limit" default:"0"`
+	ConcurrentDownloadsPerAccess int  `help:"the number of object and prefix downloads served at a time with the same access grant or access key; further downloads are rejected with 503 Service Unavailable. 0 means no limit" default:"0"`
 }
 
 // certMagic is a config struct for configuring CertMagic options.
 type certMagic struct {
-	Enabled               bool   `user:"true" help:"use CertMagic to handle TLS certificates" default:"false"`
-	KeyFile               string `user:"true" help:"path to the service account key file"`
-	Email                 string `user:"true" help:"email address to use when creating an ACME account"`
-	Staging               bool   `user:"true" help:"use staging CA endpoints" devDefault:"true" releaseDefault:"false"`
-	Bucket                string `user:"true" help:"bucket to use for certificate storage with optional prefix (bucket/prefix)"`
-	TierServiceIdentity   identity.Config
-	TierCacheExpiration   time.Duration `user:"true" help:"expiration time for tier querying service cache" devDefault:"10s" releaseDefault:"5m"`
-	TierCacheCapacity     int           `user:"true" help:"tier querying service cache capacity" default:"10000"`
-	SkipPaidTierAllowlist []string      `user:"true" help:"comma separated list of domain names which bypass paid tier queries. Set to * to disable tier check entirely"`
+	Enabled                 bool   `user:"true" help:"use CertMagic to handle TLS certificates" default:"false"`
+	KeyFile                 string `user:"true" help:"path to the service account key file"`
+	Email                   string `user:"true" help:"email address to use when creating an ACME account"`
+	Staging                 bool   `user:"true" help:"use staging CA endpoints" devDefault:"true" releaseDefault:"false"`
+	Bucket                  string `user:"true" help:"bucket to use for certificate storage with optional prefix (bucket/prefix)"`
+	DNSProvider             string `user:"true" help:"DNS provider to perform the DNS challenge with instead of the TLS-ALPN challenge for all certificates (gcloud, cloudflare); cloudflare reads the API token from CLOUDFLARE_API_TOKEN" default:""`
+	DNSProject              string `user:"true" help:"a project where the Google Cloud DNS zone exists"`
+	ChallengeOverrideDomain string `user:"true" help:"domain to set the TXT record on, to delegate the DNS challenge to a different domain"`
+	TierServiceIdentity     identity.Config
+	TierCacheExpiration     time.Duration `user:"true" help:"expiration time for tier querying service cache" devDefault:"10s" releaseDefault:"5m"`
+	TierCacheCapacity       int           `user:"true" help:"tier querying service cache capacity" default:"10000"`
+	SkipPaidTierAllowlist   []string      `user:"true" help:"comma separated list of domain names which bypass paid tier queries. Set to * to disable tier check entirely"`
 }
 
 type startupCheck struct {
@@ -129,9 +168,21 @@ var (
 		Annotations: map[string]string{"type": "setup"},
 	}
 
+	signURLCmd = &cobra.Command{
+		Use:   "sign-url <url>",
+		Short: "Sign a standard (non-hosting) link to be served with --signed-links-key",
+		Args:  cobra.ExactArgs(1),
+		RunE:  cmdSignURL,
+	}
+
 	runCfg   LinkSharing
 	setupCfg LinkSharing
 
+	signURLCfg struct {
+		SignedLinksKey string        `help:"secret key to sign the link with, the same as the one of the service"`
+		Expires        time.Duration `help:"how long the signed link works for" default:"24h0m0s"`
+	}
+
 	confDir string
 )
 
@@ -141,8 +192,11 @@ func init() {
 	defaults := cfgstruct.DefaultsFlag(rootCmd)
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(setupCmd)
+	rootCmd.AddCommand(signURLCmd)
 	process.Bind(runCmd, &runCfg, defaults, cfgstruct.ConfDir(confDir))
 	process.Bind(setupCmd, &setupCfg, defaults, cfgstruct.ConfDir(confDir), cfgstruct.SetupMode())
+	// the key is read from the service's configuration file unless given.
+	process.Bind(signURLCmd, &signURLCfg, defaults, cfgstruct.ConfDir(confDir))
 }
 
 func cmdRun(cmd *cobra.Command, args []string) (err error) {
@@ -171,12 +225,21 @@ func cmdRun(cmd *cobra.Command, args []string) (err error) {
 
 	var tlsConfig *httpserver.TLSConfig
 	if !runCfg.InsecureDisableTLS {
+		sniCertificates, err := httpserver.ParseSNICertificates(runCfg.SNICertificates)
+		if err != nil {
+			return err
+		}
+
 		tlsConfig = &httpserver.TLSConfig{
 			CertMagic:        runCfg.CertMagic.Enabled,
 			CertMagicKeyFile: runCfg.CertMagic.KeyFile,
 			CertMagicEmail:   runCfg.CertMagic.Email,
 			CertMagicStaging: runCfg.CertMagic.Staging,
 			CertMagicBucket:  runCfg.CertMagic.Bucket,
+
+			CertMagicDNSChallengeProvider:             runCfg.CertMagic.DNSProvider,
+			CertMagicDNSChallengeWithGCloudDNSProject: runCfg.CertMagic.DNSProject,
+			CertMagicDNSChallengeOverrideDomain:       runCfg.CertMagic.ChallengeOverrideDomain,
 			TierService: tierquery.Config{
 				Identity:        runCfg.CertMagic.TierServiceIdentity,
 				CacheExpiration: runCfg.CertMagic.TierCacheExpiration,
@@ -185,6 +248,10 @@ func cmdRun(cmd *cobra.Command, args []string) (err error) {
 			SkipPaidTierAllowlist: runCfg.CertMagic.SkipPaidTierAllowlist,
 			CertFile:              runCfg.CertFile,
 			KeyFile:               runCfg.KeyFile,
+			SNICertificates:       sniCertificates,
+			CertReloadInterval:    runCfg.CertReloadInterval,
+			MinTLSVersion:         runCfg.MinTLSVersion,
+			EnableOCSPStapling:    runCfg.OCSPStapling,
 			CertMagicPublicURLs:   publicURLs,
 			ConfigDir:             confDir,
 			Ctx:                   ctx,
@@ -193,31 +260,44 @@ func cmdRun(cmd *cobra.Command, args []string) (err error) {
 
 	peer, err := linksharing.New(log, linksharing.Config{
 		Server: httpserver.Config{
-			Name:               "Link Sharing",
-			Address:            runCfg.Address,
-			AddressTLS:         runCfg.AddressTLS,
-			ProxyAddressTLS:    runCfg.ProxyAddressTLS,
-			TrafficLogging:     true,
-			TLSConfig:          tlsConfig,
-			ShutdownTimeout:    -1,
-			IdleTimeout:        runCfg.IdleTimeout,
-			StartupCheckConfig: httpserver.StartupCheckConfig(runCfg.StartupCheck),
+			Name:                 "Link Sharing",
+			Address:              runCfg.Address,
+			AddressTLS:           runCfg.AddressTLS,
+			ProxyAddressTLS:      runCfg.ProxyAddressTLS,
+			TrafficLogging:       true,
+			TLSConfig:            tlsConfig,
+			ShutdownTimeout:      -1,
+			IdleTimeout:          runCfg.IdleTimeout,
+			ReadHeaderTimeout:    runCfg.ReadHeaderTimeout,
+			MaxConcurrentStreams: uint32(runCfg.MaxConcurrentStreams),
+			StartupCheckConfig:   httpserver.StartupCheckConfig(runCfg.StartupCheck),
 		},
 		Handler: sharing.Config{
 			Assets:                  assets,
 			DynamicAssets:           dynamicAssets,
 			URLBases:                publicURLs,
 			RedirectHTTPS:           runCfg.RedirectHTTPS,
+			RedirectHTTPSStatus:     runCfg.RedirectHTTPSStatus,
 			LandingRedirectTarget:   runCfg.LandingRedirectTarget,
+			LandingTemplate:         runCfg.LandingTemplate,
 			TXTRecordTTL:            runCfg.TXTRecordTTL,
+			TXTRecordNegativeTTL:    runCfg.TXTRecordNegativeTTL,
+			MapCacheExpiration:      runCfg.MapCacheExpiration,
+			MapCacheCapacity:        runCfg.MapCacheCapacity,
 			AuthServiceConfig:       runCfg.AuthService,
+			BucketConfigs:           runCfg.BucketConfigs,
 			DNSServer:               runCfg.DNSServer,
 			SatelliteConnectionPool: sharing.ConnectionPoolConfig(runCfg.SatelliteConnectionPool),
 			ConnectionPool:          sharing.ConnectionPoolConfig(runCfg.ConnectionPool),
 			ClientTrustedIPsList:    runCfg.ClientTrustedIPSList,
+			ClientTrustedHops:       runCfg.ClientTrustedHops,
 			UseClientIPHeaders:      runCfg.UseClientIPHeaders,
 			StandardViewsHTML:       runCfg.StandardViewsHTML,
 			StandardRendersContent:  runCfg.StandardRendersContent,
+			InlineContentTypes:      strings.Split(runCfg.InlineContentTypes, ","),
+			AttachmentContentTypes:  strings.Split(runCfg.AttachmentContentTypes, ","),
+			ContentTypeOverrides:    strings.Split(runCfg.ContentTypeOverrides, ","),
+			SniffContentType:        runCfg.SniffContentType,
 			Uplink: &uplink.Config{
 				UserAgent:   "linksharing",
 				DialTimeout: runCfg.DialTimeout,
@@ -225,13 +305,28 @@ func cmdRun(cmd *cobra.Command, args []string) (err error) {
 				KeyPEM:      clientKeyPEM,
 			},
 			ListPageLimit:         runCfg.ListPageLimit,
+			ListingDisabled:       runCfg.ListingDisabled,
+			DisableMap:            runCfg.DisableMap,
 			BlockedPaths:          strings.Split(runCfg.BlockedPaths, ","),
+			StrictQueryParams:     runCfg.StrictQueryParams,
+			AllowedQueryParams:    strings.Split(runCfg.AllowedQueryParams, ","),
+			DownloadRetry:         runCfg.DownloadRetry,
+			IndexDocument:         runCfg.IndexDocument,
+			ErrorDocument:         runCfg.ErrorDocument,
+			NotFoundTemplate:      runCfg.NotFoundTemplate,
+			Compression:           runCfg.Compression,
+			SignedLinksKey:        runCfg.SignedLinksKey,
 			DownloadPrefixEnabled: runCfg.DownloadPrefixEnabled,
 			DownloadZipLimit:      runCfg.DownloadZipLimit,
+
+			ConcurrentDownloads:          runCfg.Limits.ConcurrentDownloads,
+			ConcurrentDownloadsPerAccess: runCfg.Limits.ConcurrentDownloadsPerAccess,
 		},
 		ConcurrentRequestLimit: runCfg.Limits.ConcurrentRequests,
 		GeoLocationDB:          runCfg.GeoLocationDB,
+		GeoLocationASNDB:       runCfg.GeoLocationASNDB,
 		ShutdownDelay:          runCfg.ShutdownDelay,
+		SecurityHeaders:        runCfg.SecurityHeaders,
 	})
 	if err != nil {
 		return err
@@ -253,6 +348,23 @@ func cmdRun(cmd *cobra.Command, args []string) (err error) {
 	return g.Wait()
 }
 
+func cmdSignURL(cmd *cobra.Command, args []string) (err error) {
+	if signURLCfg.SignedLinksKey == "" {
+		return errs.New("--signed-links-key must be set")
+	}
+	if signURLCfg.Expires <= 0 {
+		return errs.New("--expires must be positive")
+	}
+
+	link, err := url.Parse(args[0])
+	if err != nil {
+		return errs.New("invalid link: %w", err)
+	}
+
+	_, err = fmt.Println(sharing.SignURL([]byte(signURLCfg.SignedLinksKey), link, time.Now().Add(signURLCfg.Expires)))
+	return err
+}
+
 func cmdSetup(cmd *cobra.Command, args []string) (err error) {
 	setupDir, err := filepath.Abs(confDir)
 	if err != nil {
@@ -35,3 +35,24 @@ func APIKeyExpiration(apiKey *macaroon.APIKey) (*time.Time, error) {
 
 	return expiration, nil
 }
+
+// APIKeyAllowsWrites returns whether apiKey allows uploads or deletes, i.e.
+// whether none of its caveats disallow writes or none disallow deletes.
+func APIKeyAllowsWrites(apiKey *macaroon.APIKey) (bool, error) {
+	mac, err := macaroon.ParseMacaroon(apiKey.SerializeRaw())
+	if err != nil {
+		return false, err
+	}
+
+	var disallowWrites, disallowDeletes bool
+	for _, cavbuf := range mac.Caveats() {
+		var cav macaroon.Caveat
+		if err := cav.UnmarshalBinary(cavbuf); err != nil {
+			return false, err
+		}
+		disallowWrites = disallowWrites || cav.DisallowWrites
+		disallowDeletes = disallowDeletes || cav.DisallowDeletes
+	}
+
+	return !disallowWrites || !disallowDeletes, nil
+}
@@ -77,6 +77,29 @@ func TestAPIKeyExpiration_Invalid(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestAPIKeyAllowsWrites(t *testing.T) {
+	unrestricted, err := macaroon.NewAPIKey([]byte("test"))
+	require.NoError(t, err)
+
+	allowsWrites, err := internalAccess.APIKeyAllowsWrites(unrestricted)
+	require.NoError(t, err)
+	require.True(t, allowsWrites)
+
+	noWrites, err := unrestricted.Restrict(macaroon.Caveat{DisallowWrites: true})
+	require.NoError(t, err)
+
+	allowsWrites, err = internalAccess.APIKeyAllowsWrites(noWrites)
+	require.NoError(t, err)
+	require.True(t, allowsWrites) // deletes are still allowed
+
+	readOnly, err := noWrites.Restrict(macaroon.Caveat{DisallowDeletes: true})
+	require.NoError(t, err)
+
+	allowsWrites, err = internalAccess.APIKeyAllowsWrites(readOnly)
+	require.NoError(t, err)
+	require.False(t, allowsWrites)
+}
+
 func combineNotAfterCaveats(t *testing.T, unrestricted *macaroon.APIKey, times ...time.Time) *macaroon.APIKey {
 	var (
 		restricted = unrestricted
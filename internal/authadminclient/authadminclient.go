@@ -144,6 +144,32 @@ func (c *Client) Invalidate(ctx context.Context, encodedKey, reason string) erro
 	})
 }
 
+// InvalidateResult is the outcome of invalidating a single record with
+// InvalidateMany.
+type InvalidateResult struct {
+	Key   string `json:"key"`
+	Error string `json:"error,omitempty"`
+}
+
+// InvalidateMany invalidates records on all configured authservice databases
+// with a shared reason. It doesn't stop at the first failure and returns a
+// result for every key, in the order they were given.
+func (c *Client) InvalidateMany(ctx context.Context, encodedKeys []string, reason string) ([]InvalidateResult, error) {
+	if len(c.admins) == 0 {
+		return nil, Error.New("no databases configured")
+	}
+
+	results := make([]InvalidateResult, len(encodedKeys))
+	for i, encodedKey := range encodedKeys {
+		results[i].Key = encodedKey
+		if err := c.Invalidate(ctx, encodedKey, reason); err != nil {
+			results[i].Error = err.Error()
+		}
+	}
+
+	return results, nil
+}
+
 // Unpublish unpublishes a record on all configured authservice databases.
 func (c *Client) Unpublish(ctx context.Context, encodedKey string) error {
 	return c.withDBs(ctx, "unpublish", encodedKey, func(ctx context.Context, keyInfo parsedKey, admin authdb.StorageAdmin) error {
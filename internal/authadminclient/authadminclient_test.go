@@ -117,6 +117,48 @@ func TestInvalidateRecord(t *testing.T) {
 	})
 }
 
+func TestInvalidateManyRecords(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	withEnvironment(ctx, t, func(ctx *testcontext.Context, t *testing.T, env *environment) {
+		records, keys := createFullRecords(ctx, t, env.storage, 5)
+
+		invalidatedKeys := keys[:3]
+		reason := "leaked"
+
+		noAddrClient, err := authadminclient.Open(ctx, authadminclient.Config{}, zap.NewNop())
+		require.NoError(t, err)
+		_, err = noAddrClient.InvalidateMany(ctx, []string{invalidatedKeys[0].ToHex()}, reason)
+		require.Error(t, err)
+
+		input := []string{invalidatedKeys[0].ToHex(), "invalid", invalidatedKeys[1].ToHex(), invalidatedKeys[2].ToHex()}
+		results, err := env.adminClient.InvalidateMany(ctx, input, reason)
+		require.NoError(t, err)
+		require.Len(t, results, len(input))
+		for i, result := range results {
+			require.Equal(t, input[i], result.Key)
+			if i == 1 {
+				require.NotEmpty(t, result.Error)
+			} else {
+				require.Empty(t, result.Error)
+			}
+		}
+
+		for _, key := range invalidatedKeys {
+			_, err = env.storage.Get(ctx, key)
+			require.True(t, authdb.Invalid.Has(err))
+
+			record, err := env.adminClient.Get(ctx, key.ToHex())
+			require.NoError(t, err)
+			require.Equal(t, reason, record.InvalidationReason)
+
+			delete(records, key)
+		}
+		verifyRecords(ctx, t, env, records)
+	})
+}
+
 func TestUnpublishRecord(t *testing.T) {
 	ctx := testcontext.New(t)
 	defer ctx.Cleanup()
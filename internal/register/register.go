@@ -6,10 +6,13 @@ package register
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
 	"time"
 
 	"github.com/zeebo/errs"
@@ -32,7 +35,7 @@ type Credentials struct {
 	AccessKeyID                  string     `json:"access_key_id"`
 	SecretKey                    string     `json:"secret_key"`
 	Endpoint                     string     `json:"endpoint"`
-	FreeTierRestrictedExpiration *time.Time `json:"freeTierRestrictedExpiration"`
+	FreeTierRestrictedExpiration *time.Time `json:"free_tier_restricted_expiration"`
 }
 
 func (c Credentials) String() string {
@@ -40,21 +43,75 @@ func (c Credentials) String() string {
 		c.AccessKeyID, c.SecretKey, c.Endpoint)
 }
 
-// Access registers access at authservice at authAddr.
-func Access(ctx context.Context, authAddr, access string, public bool) (Credentials, error) {
+// TLSOptions configure how the certificate of authservices at drpcs:// and
+// https:// addresses is verified, e.g. for self-hosted authservices with
+// certificates of a private CA.
+type TLSOptions struct {
+	// CACertPath is the path to a PEM file of CA certificates that are
+	// trusted in addition to the system ones.
+	CACertPath string
+	// InsecureSkipVerify disables the verification of the certificate. It's
+	// only meant for testing.
+	InsecureSkipVerify bool
+}
+
+// config returns the TLS config for opts, or nil if they are the defaults.
+func (opts TLSOptions) config() (*tls.Config, error) {
+	if opts == (TLSOptions{}) {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		InsecureSkipVerify: opts.InsecureSkipVerify, //nolint:gosec // opted into for testing.
+	}
+	if opts.CACertPath != "" {
+		certs, err := os.ReadFile(opts.CACertPath)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(certs) {
+			return nil, Error.New("no certificates found in %s", opts.CACertPath)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// Access registers access at authservice at authAddr. If ttl is positive, the
+// credentials expire after ttl even if access expires later. Only HTTP
+// authservice addresses support ttl.
+func Access(ctx context.Context, authAddr, access string, public bool, ttl time.Duration) (Credentials, error) {
+	return AccessWithTLS(ctx, authAddr, access, public, ttl, TLSOptions{})
+}
+
+// AccessWithTLS is like Access, but verifies the certificate of the
+// authservice as configured by tlsOpts.
+func AccessWithTLS(ctx context.Context, authAddr, access string, public bool, ttl time.Duration, tlsOpts TLSOptions) (Credentials, error) {
 	u, err := url.Parse(authAddr)
 	if err != nil {
 		return Credentials{}, Error.Wrap(err)
 	}
+	tlsConfig, err := tlsOpts.config()
+	if err != nil {
+		return Credentials{}, err
+	}
 	if u.Scheme == "drpc" || u.Scheme == "drpcs" {
-		return registerDRPC(ctx, u.Host, u.Scheme == "drpcs", access, public)
+		if ttl != 0 {
+			return Credentials{}, Error.New("ttl isn't supported by DRPC, use an HTTP address")
+		}
+		return registerDRPC(ctx, u.Host, u.Scheme == "drpcs", tlsConfig, access, public)
 	}
 	u.Path = "/v1/access"
-	return registerHTTP(ctx, u.String(), access, public)
+	return registerHTTP(ctx, u.String(), tlsConfig, access, public, ttl)
 }
 
-func registerDRPC(ctx context.Context, addr string, secure bool, access string, public bool) (Credentials, error) {
+func registerDRPC(ctx context.Context, addr string, secure bool, tlsConfig *tls.Config, access string, public bool) (Credentials, error) {
 	d := rpc.NewDefaultDialer(nil)
+	d.HostnameTLSConfig = tlsConfig
 	c := rpc.NewHybridConnector()
 	c.SetSendDRPCMuxHeader(false)
 	d.Connector = c
@@ -90,13 +147,15 @@ func registerDRPC(ctx context.Context, addr string, secure bool, access string,
 	}, nil
 }
 
-func registerHTTP(ctx context.Context, adrr, access string, public bool) (Credentials, error) {
+func registerHTTP(ctx context.Context, adrr string, tlsConfig *tls.Config, access string, public bool, ttl time.Duration) (Credentials, error) {
 	payload := struct {
 		AccessGrant string `json:"access_grant"`
 		Public      bool   `json:"public"`
+		TTLSeconds  int64  `json:"ttl_seconds,omitempty"`
 	}{
 		AccessGrant: access,
 		Public:      public,
+		TTLSeconds:  int64(ttl / time.Second),
 	}
 
 	var ret Credentials
@@ -109,7 +168,14 @@ func registerHTTP(ctx context.Context, adrr, access string, public bool) (Creden
 	if err != nil {
 		return ret, Error.Wrap(err)
 	}
-	res, err := (&http.Client{}).Do(req)
+	client := &http.Client{}
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client.Transport = transport
+	}
+
+	res, err := client.Do(req)
 	if err != nil {
 		return ret, Error.Wrap(err)
 	}
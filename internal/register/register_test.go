@@ -0,0 +1,95 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package register
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/grant"
+	"storj.io/common/macaroon"
+	"storj.io/common/testcontext"
+	"storj.io/common/testrand"
+)
+
+func TestAccessWithTLS(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	expected := Credentials{AccessKeyID: "id", SecretKey: "secret", Endpoint: "https://gateway.test"}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewEncoder(w).Encode(expected))
+	}))
+	defer server.Close()
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caPath, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw,
+	}), 0600))
+
+	_, err := Access(ctx, server.URL, "access", false, 0)
+	require.Error(t, err)
+
+	creds, err := AccessWithTLS(ctx, server.URL, "access", false, 0, TLSOptions{CACertPath: caPath})
+	require.NoError(t, err)
+	require.Equal(t, expected, creds)
+
+	creds, err = AccessWithTLS(ctx, server.URL, "access", false, 0, TLSOptions{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	require.Equal(t, expected, creds)
+
+	emptyPath := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(emptyPath, nil, 0600))
+
+	_, err = AccessWithTLS(ctx, server.URL, "access", false, 0, TLSOptions{CACertPath: emptyPath})
+	require.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	_, err := Validate(ctx, "invalid")
+	require.Error(t, err)
+
+	apiKey, err := macaroon.NewAPIKey([]byte("secret"))
+	require.NoError(t, err)
+
+	expiration := time.Now().Add(-time.Hour).Truncate(time.Second)
+	apiKey, err = apiKey.Restrict(macaroon.Caveat{NotAfter: &expiration})
+	require.NoError(t, err)
+
+	satelliteAddress := testrand.NodeID().String() + "@satellite.test:7777"
+	expired, err := (&grant.Access{
+		SatelliteAddress: satelliteAddress,
+		APIKey:           apiKey,
+		EncAccess:        grant.NewEncryptionAccess(),
+	}).Serialize()
+	require.NoError(t, err)
+
+	diagnostics, err := Validate(ctx, expired)
+	require.ErrorContains(t, err, "expired")
+	require.Equal(t, satelliteAddress, diagnostics.SatelliteAddress)
+	require.NotNil(t, diagnostics.Expiration)
+	require.True(t, expiration.Equal(*diagnostics.Expiration))
+	require.Empty(t, diagnostics.PublicProjectID)
+}
+
+func TestCredentialsJSON(t *testing.T) {
+	var creds Credentials
+	require.NoError(t, json.Unmarshal([]byte(`{"access_key_id":"id","secret_key":"secret","endpoint":"https://gateway.test","free_tier_restricted_expiration":"2025-01-02T03:04:05Z"}`), &creds))
+	require.Equal(t, "id", creds.AccessKeyID)
+	require.Equal(t, "secret", creds.SecretKey)
+	require.Equal(t, "https://gateway.test", creds.Endpoint)
+	require.NotNil(t, creds.FreeTierRestrictedExpiration)
+	require.True(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC).Equal(*creds.FreeTierRestrictedExpiration))
+}
@@ -0,0 +1,76 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package register
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"storj.io/edge/internal/access"
+	"storj.io/uplink"
+	privateAccess "storj.io/uplink/private/access"
+	"storj.io/uplink/private/project"
+)
+
+// Diagnostics describe an access grant checked by Validate.
+type Diagnostics struct {
+	SatelliteAddress string     `json:"satellite_address"`
+	PublicProjectID  string     `json:"public_project_id"`
+	Expiration       *time.Time `json:"expiration"`
+	AllowsWrites     bool       `json:"allows_writes"`
+}
+
+func (d Diagnostics) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Satellite: %s\nPublic Project ID: %s\n", d.SatelliteAddress, d.PublicProjectID)
+	if d.Expiration != nil {
+		fmt.Fprintf(&b, "Expiration: %s\n", d.Expiration.Format(time.RFC3339))
+	} else {
+		b.WriteString("Expiration: never\n")
+	}
+	fmt.Fprintf(&b, "Allows Writes: %t", d.AllowsWrites)
+	return b.String()
+}
+
+// Validate checks that serializedAccess is well-formed, hasn't expired, and
+// that its satellite is reachable and accepts its API key, without
+// registering it at any authservice.
+//
+// Diagnostics are returned as far as they could be determined, even if the
+// access grant is invalid.
+func Validate(ctx context.Context, serializedAccess string) (Diagnostics, error) {
+	var diagnostics Diagnostics
+
+	parsed, err := uplink.ParseAccess(serializedAccess)
+	if err != nil {
+		return diagnostics, Error.Wrap(err)
+	}
+	diagnostics.SatelliteAddress = parsed.SatelliteAddress()
+
+	apiKey := privateAccess.APIKey(parsed)
+
+	diagnostics.Expiration, err = access.APIKeyExpiration(apiKey)
+	if err != nil {
+		return diagnostics, Error.Wrap(err)
+	}
+	if diagnostics.Expiration != nil && diagnostics.Expiration.Before(time.Now()) {
+		return diagnostics, Error.New("access grant expired at %s", diagnostics.Expiration.Format(time.RFC3339))
+	}
+
+	diagnostics.AllowsWrites, err = access.APIKeyAllowsWrites(apiKey)
+	if err != nil {
+		return diagnostics, Error.Wrap(err)
+	}
+
+	// only the satellite can tell whether the API key is valid.
+	id, err := project.GetPublicID(ctx, uplink.Config{}, parsed)
+	if err != nil {
+		return diagnostics, Error.New("satellite %q: %v", diagnostics.SatelliteAddress, err)
+	}
+	diagnostics.PublicProjectID = id.String()
+
+	return diagnostics, nil
+}
@@ -17,6 +17,7 @@ import (
 	"go.uber.org/zap"
 
 	"storj.io/common/encryption"
+	"storj.io/common/macaroon"
 	"storj.io/common/storj"
 	"storj.io/common/uuid"
 	"storj.io/common/version"
@@ -133,6 +134,8 @@ type Config struct {
 	AllowedSatelliteURLs    map[storj.NodeURL]struct{}
 	RetrievePublicProjectID bool
 	FreeTierAccessLimit     FreeTierAccessLimitConfig
+	RegistrationPolicy      RegistrationPolicyConfig
+	MaxTTL                  time.Duration
 }
 
 // FreeTierAccessLimitConfig contains settings for restricting the access grants of free tier users.
@@ -141,6 +144,14 @@ type FreeTierAccessLimitConfig struct {
 	TierQuery   tierquery.Config
 }
 
+// RegistrationPolicyConfig contains rules that access grants must follow to be
+// registered.
+type RegistrationPolicyConfig struct {
+	PublicReadOnly          bool `help:"reject public access grants that allow uploads or deletes" default:"false"`
+	PublicRequireExpiration bool `help:"reject public access grants without an expiration" default:"false"`
+	RequireExpiration       bool `help:"reject access grants without an expiration" default:"false"`
+}
+
 // Database wraps Storage implementation and uses it to store encrypted accesses
 // and secrets.
 type Database struct {
@@ -202,8 +213,13 @@ type PutResult struct {
 //
 // If the access grant's owner is a free-tier user, expiration date restrictions
 // may be imposed on the access grant according to the FreeTierAccessLimitConfig
-// used when constructing the database.
-func (db *Database) Put(ctx context.Context, key EncryptionKey, accessGrant string, public bool) (result PutResult, err error) {
+// used when constructing the database. Access grants that violate the
+// RegistrationPolicyConfig are rejected.
+//
+// If ttl is positive, the record expires after ttl even if the access grant
+// expires later. The configured MaxTTL is used if ttl is zero, and ttl can't
+// exceed it.
+func (db *Database) Put(ctx context.Context, key EncryptionKey, accessGrant string, public bool, ttl time.Duration) (result PutResult, err error) {
 	defer mon.Task()(&ctx)(&err)
 
 	access, err := uplink.ParseAccess(accessGrant)
@@ -261,6 +277,15 @@ func (db *Database) Put(ctx context.Context, key EncryptionKey, accessGrant stri
 		}
 	}
 
+	expiration, err = db.applyTTL(expiration, ttl)
+	if err != nil {
+		return PutResult{}, err
+	}
+
+	if err := db.checkRegistrationPolicy(apiKey, expiration, public); err != nil {
+		return PutResult{}, err
+	}
+
 	if _, err := rand.Read(result.SecretKey[:]); err != nil {
 		return PutResult{}, errs.Wrap(err)
 	}
@@ -306,6 +331,61 @@ func (db *Database) Put(ctx context.Context, key EncryptionKey, accessGrant stri
 	return result, nil
 }
 
+// applyTTL returns the expiration of a record whose access grant expires at
+// expiration and that's registered with ttl.
+func (db *Database) applyTTL(expiration *time.Time, ttl time.Duration) (*time.Time, error) {
+	switch {
+	case ttl < 0:
+		return nil, ErrAccessGrant.New("ttl cannot be negative")
+	case ttl == 0:
+		ttl = db.config.MaxTTL
+	case db.config.MaxTTL > 0 && ttl > db.config.MaxTTL:
+		return nil, ErrAccessGrant.New("ttl cannot be longer than %s", db.config.MaxTTL)
+	}
+	if ttl == 0 {
+		return expiration, nil
+	}
+	if ttl < time.Minute {
+		return nil, ErrAccessGrant.New("ttl cannot be shorter than a minute")
+	}
+
+	ttlExpiration := time.Now().Add(ttl)
+	if expiration != nil && expiration.Before(ttlExpiration) {
+		return expiration, nil
+	}
+	return &ttlExpiration, nil
+}
+
+// checkRegistrationPolicy returns an ErrAccessGrant error describing the first
+// rule of the RegistrationPolicyConfig that the access grant violates.
+func (db *Database) checkRegistrationPolicy(apiKey *macaroon.APIKey, expiration *time.Time, public bool) error {
+	policy := db.config.RegistrationPolicy
+
+	if expiration == nil {
+		if policy.RequireExpiration {
+			mon.Event("registration_policy_rejected_no_expiration")
+			return ErrAccessGrant.New("access grants must have an expiration")
+		}
+		if public && policy.PublicRequireExpiration {
+			mon.Event("registration_policy_rejected_public_no_expiration")
+			return ErrAccessGrant.New("public access grants must have an expiration")
+		}
+	}
+
+	if public && policy.PublicReadOnly {
+		allowsWrites, err := internalAccess.APIKeyAllowsWrites(apiKey)
+		if err != nil {
+			return ErrAccessGrant.Wrap(err)
+		}
+		if allowsWrites {
+			mon.Event("registration_policy_rejected_public_writes")
+			return ErrAccessGrant.New("public access grants must not allow uploads or deletes")
+		}
+	}
+
+	return nil
+}
+
 // Get retrieves an access grant and secret key, looked up by the hash of the
 // access key, and then decrypted.
 func (db *Database) Get(ctx context.Context, accessKeyID EncryptionKey) (result ResultRecord, err error) {
@@ -100,9 +100,9 @@ func TestPutSatelliteValidation(t *testing.T) {
 	key, err := NewEncryptionKey()
 	require.NoError(t, err)
 
-	_, err = db.Put(ctx, key, validGrant, false)
+	_, err = db.Put(ctx, key, validGrant, false, 0)
 	require.NoError(t, err)
-	_, err = db.Put(ctx, key, invalidGrant, false)
+	_, err = db.Put(ctx, key, invalidGrant, false, 0)
 	require.Error(t, err)
 }
 
@@ -131,12 +131,163 @@ func TestPutShortExpiration(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	_, err = db.Put(context.TODO(), enc, s, true)
+	_, err = db.Put(context.TODO(), enc, s, true, 0)
 	t.Log(err)
 	require.Error(t, err)
 	require.True(t, ErrAccessGrant.Has(err))
 }
 
+func TestPutRegistrationPolicy(t *testing.T) {
+	eu1 := "12L9ZFwhzVpuEKMUNUqkaTLGzwY9G24tbiigLiXpmZWKwmcNDDs@eu1.storj.io:7777"
+
+	url, err := storj.ParseNodeURL(eu1)
+	require.NoError(t, err)
+
+	enc, err := NewEncryptionKey()
+	require.NoError(t, err)
+
+	unrestricted, err := macaroon.NewAPIKey(nil)
+	require.NoError(t, err)
+
+	readOnly, err := unrestricted.Restrict(macaroon.Caveat{DisallowWrites: true, DisallowDeletes: true})
+	require.NoError(t, err)
+
+	expiring := combineNotAfterCaveats(t, readOnly, time.Now().Add(time.Hour))
+
+	serialize := func(apiKey *macaroon.APIKey) string {
+		g := grant.Access{
+			SatelliteAddress: eu1,
+			EncAccess:        grant.NewEncryptionAccess(),
+			APIKey:           apiKey,
+		}
+		s, err := g.Serialize()
+		require.NoError(t, err)
+		return s
+	}
+
+	testCases := []struct {
+		desc   string
+		policy RegistrationPolicyConfig
+		apiKey *macaroon.APIKey
+		public bool
+		err    string
+	}{
+		{desc: "no policy", apiKey: unrestricted, public: true},
+		{desc: "public writes", policy: RegistrationPolicyConfig{PublicReadOnly: true}, apiKey: unrestricted, public: true, err: "public access grants must not allow uploads or deletes"},
+		{desc: "private writes", policy: RegistrationPolicyConfig{PublicReadOnly: true}, apiKey: unrestricted},
+		{desc: "public read-only", policy: RegistrationPolicyConfig{PublicReadOnly: true}, apiKey: readOnly, public: true},
+		{desc: "public no expiration", policy: RegistrationPolicyConfig{PublicRequireExpiration: true}, apiKey: readOnly, public: true, err: "public access grants must have an expiration"},
+		{desc: "private no expiration", policy: RegistrationPolicyConfig{PublicRequireExpiration: true}, apiKey: readOnly},
+		{desc: "public expiration", policy: RegistrationPolicyConfig{PublicRequireExpiration: true}, apiKey: expiring, public: true},
+		{desc: "no expiration", policy: RegistrationPolicyConfig{RequireExpiration: true}, apiKey: readOnly, err: "access grants must have an expiration"},
+		{desc: "expiration", policy: RegistrationPolicyConfig{RequireExpiration: true}, apiKey: expiring},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			db, err := NewDatabase(zaptest.NewLogger(t), mockStorage{}, Config{
+				AllowedSatelliteURLs: map[storj.NodeURL]struct{}{url: {}},
+				RegistrationPolicy:   tc.policy,
+			})
+			require.NoError(t, err)
+
+			_, err = db.Put(context.TODO(), enc, serialize(tc.apiKey), tc.public, 0)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, ErrAccessGrant.Has(err))
+			require.EqualError(t, err, "access grant: "+tc.err)
+		})
+	}
+}
+
+func TestPutTTL(t *testing.T) {
+	eu1 := "12L9ZFwhzVpuEKMUNUqkaTLGzwY9G24tbiigLiXpmZWKwmcNDDs@eu1.storj.io:7777"
+
+	url, err := storj.ParseNodeURL(eu1)
+	require.NoError(t, err)
+
+	enc, err := NewEncryptionKey()
+	require.NoError(t, err)
+
+	api, err := macaroon.NewAPIKey(nil)
+	require.NoError(t, err)
+
+	serialize := func(apiKey *macaroon.APIKey) string {
+		g := grant.Access{
+			SatelliteAddress: eu1,
+			EncAccess:        grant.NewEncryptionAccess(),
+			APIKey:           apiKey,
+		}
+		s, err := g.Serialize()
+		require.NoError(t, err)
+		return s
+	}
+
+	grantExpiration := time.Now().Add(2 * time.Hour)
+	unlimited, expiring := serialize(api), serialize(combineNotAfterCaveats(t, api, grantExpiration))
+
+	testCases := []struct {
+		desc        string
+		maxTTL      time.Duration
+		accessGrant string
+		ttl         time.Duration
+		expiration  time.Duration // 0 means none, -1 the access grant's
+		err         string
+	}{
+		{desc: "no ttl", accessGrant: unlimited},
+		{desc: "ttl", accessGrant: unlimited, ttl: time.Hour, expiration: time.Hour},
+		{desc: "ttl after grant expiration", accessGrant: expiring, ttl: 3 * time.Hour, expiration: -1},
+		{desc: "max ttl", maxTTL: time.Hour, accessGrant: unlimited, expiration: time.Hour},
+		{desc: "ttl below max ttl", maxTTL: time.Hour, accessGrant: unlimited, ttl: 30 * time.Minute, expiration: 30 * time.Minute},
+		{desc: "ttl above max ttl", maxTTL: time.Hour, accessGrant: unlimited, ttl: 2 * time.Hour, err: "ttl cannot be longer than 1h0m0s"},
+		{desc: "negative ttl", accessGrant: unlimited, ttl: -time.Hour, err: "ttl cannot be negative"},
+		{desc: "short ttl", accessGrant: unlimited, ttl: time.Second, err: "ttl cannot be shorter than a minute"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			storage := &recordingStorage{}
+			db, err := NewDatabase(zaptest.NewLogger(t), storage, Config{
+				AllowedSatelliteURLs: map[storj.NodeURL]struct{}{url: {}},
+				MaxTTL:               tc.maxTTL,
+			})
+			require.NoError(t, err)
+
+			start := time.Now()
+			_, err = db.Put(context.TODO(), enc, tc.accessGrant, false, tc.ttl)
+			if tc.err != "" {
+				require.True(t, ErrAccessGrant.Has(err))
+				require.EqualError(t, err, "access grant: "+tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			switch tc.expiration {
+			case 0:
+				require.Nil(t, storage.record.ExpiresAt)
+			case -1:
+				require.NotNil(t, storage.record.ExpiresAt)
+				require.WithinDuration(t, grantExpiration, *storage.record.ExpiresAt, time.Second)
+			default:
+				require.NotNil(t, storage.record.ExpiresAt)
+				require.WithinDuration(t, start.Add(tc.expiration), *storage.record.ExpiresAt, time.Second)
+			}
+		})
+	}
+}
+
+type recordingStorage struct {
+	mockStorage
+	record *Record
+}
+
+func (s *recordingStorage) Put(ctx context.Context, keyHash KeyHash, record *Record) error {
+	s.record = record
+	return nil
+}
+
 type mockStorage struct{}
 
 func (mockStorage) Put(ctx context.Context, keyHash KeyHash, record *Record) (err error) { return nil }
@@ -48,6 +48,33 @@ type Config struct {
 	// ConflictBackoff configures retries for conflicting transactions that may
 	// occur when the underlying storage engine is under heavy load.
 	ConflictBackoff backoff.ExponentialBackoff
+
+	// GC configures the garbage collection of the value log.
+	GC GCConfig
+}
+
+// GCConfig configures the garbage collection of the value log. Zero Interval
+// and DiscardRatio use their defaults.
+type GCConfig struct {
+	Interval     time.Duration `user:"true" help:"how often to garbage collect the value log" default:"1h0m0s"`
+	DiscardRatio float64       `user:"true" help:"fraction of a value log file that must be garbage for it to be rewritten, between 0 and 1" default:"0.5"`
+	MaxBackoff   time.Duration `user:"true" help:"maximum time between garbage collections of the value log if they keep reclaiming nothing; the time doubles with every such garbage collection. Not larger than the interval disables backing off" default:"8h0m0s"`
+}
+
+func (config *GCConfig) init() error {
+	if config.Interval == 0 {
+		config.Interval = time.Hour
+	}
+	if config.DiscardRatio == 0 {
+		config.DiscardRatio = .5
+	}
+	if config.Interval < 0 {
+		return errs.New("value log GC interval must be positive, got %s", config.Interval)
+	}
+	if config.DiscardRatio <= 0 || config.DiscardRatio >= 1 {
+		return errs.New("value log GC discard ratio must be between 0 and 1, got %v", config.DiscardRatio)
+	}
+	return nil
 }
 
 // DB is a Storage implementation using BadgerDB.
@@ -59,6 +86,11 @@ type DB struct {
 
 	gcCycle    sync2.Cycle
 	gcErrGroup errgroup.Group
+	// gcIdleRuns is the number of garbage collections in a row that
+	// reclaimed nothing, and gcSkip the number of cycles left to skip
+	// because of them.
+	gcIdleRuns int
+	gcSkip     int
 
 	closed uint32
 }
@@ -68,6 +100,9 @@ func Open(log *zap.Logger, config Config) (*DB, error) {
 	if log == nil {
 		return nil, Error.New("needs non-nil logger")
 	}
+	if err := config.GC.init(); err != nil {
+		return nil, Error.Wrap(err)
+	}
 
 	db := &DB{
 		log:    log,
@@ -106,7 +141,7 @@ func Open(log *zap.Logger, config Config) (*DB, error) {
 		return nil, Error.New("prepare: %w", err)
 	}
 
-	db.gcCycle.SetInterval(time.Hour)
+	db.gcCycle.SetInterval(config.GC.Interval)
 	db.gcCycle.Start(context.TODO(), &db.gcErrGroup, db.gcValueLog)
 
 	return db, nil
@@ -160,9 +195,22 @@ func (db *DB) prepare() (err error) {
 }
 
 // gcValueLog garbage collects value log. It always returns a nil error.
+//
+// Garbage collections that reclaim nothing make the following ones back off
+// up to config.GC.MaxBackoff.
 func (db *DB) gcValueLog(ctx context.Context) (err error) {
 	defer mon.Task()(&ctx)(nil)
 
+	if db.gcSkip > 0 {
+		db.gcSkip--
+		mon.Event("gc_skipped")
+		return nil
+	}
+
+	mon.IntVal("gc_last_run_unix").Observe(time.Now().Unix())
+	_, vlogSizeBefore := db.db.Size()
+
+	var rewrites int64
 gcLoop:
 	for err == nil {
 		gcFinished := mon.TaskNamed("gc")(&ctx)
@@ -171,18 +219,58 @@ gcLoop:
 			err = ctx.Err()
 		default:
 			// Run GC and optionally silence ErrNoRewrite errors:
-			if err = db.db.RunValueLogGC(.5); errs.Is(err, badger.ErrNoRewrite) {
+			if err = db.db.RunValueLogGC(db.config.GC.DiscardRatio); errs.Is(err, badger.ErrNoRewrite) {
 				gcFinished(nil)
 				err = nil
 				break gcLoop
 			}
+			if err == nil {
+				rewrites++
+			}
 		}
 		gcFinished(&err)
 	}
-	db.log.Info("value log garbage collection finished", zap.Error(err))
+
+	_, vlogSizeAfter := db.db.Size()
+	reclaimed := max(vlogSizeBefore-vlogSizeAfter, 0)
+
+	mon.IntVal("gc_rewrites").Observe(rewrites)
+	mon.IntVal("gc_reclaimed_bytes").Observe(reclaimed)
+	if err != nil {
+		mon.Counter("gc_errors").Inc(1)
+	}
+
+	if err == nil && rewrites == 0 {
+		db.gcIdleRuns++
+	} else {
+		db.gcIdleRuns = 0
+	}
+	db.gcSkip = gcCyclesToSkip(db.gcIdleRuns, db.config.GC.Interval, db.config.GC.MaxBackoff)
+
+	db.log.Info("value log garbage collection finished",
+		zap.Int64("rewrites", rewrites),
+		zap.Int64("reclaimed_bytes", reclaimed),
+		zap.Int("cycles_to_skip", db.gcSkip),
+		zap.Error(err))
 	return nil
 }
 
+// gcCyclesToSkip returns the number of garbage collection cycles to skip after
+// idleRuns garbage collections in a row reclaimed nothing, so that the time
+// between them doubles with every one, up to maxBackoff.
+func gcCyclesToSkip(idleRuns int, interval, maxBackoff time.Duration) int {
+	if idleRuns <= 0 || interval <= 0 || maxBackoff <= interval {
+		return 0
+	}
+
+	maxCycles := int64(maxBackoff / interval)
+	cycles := int64(1)
+	for i := 0; i < idleRuns && cycles < maxCycles; i++ {
+		cycles *= 2
+	}
+	return int(min(cycles, maxCycles)) - 1
+}
+
 func (db *DB) txnWithBackoff(ctx context.Context, f func(txn *badger.Txn) error) error {
 	// db.config.ConflictBackoff needs to be copied. Otherwise, we are using one
 	// for all queries.
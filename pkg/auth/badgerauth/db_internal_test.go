@@ -0,0 +1,51 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package badgerauth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCCyclesToSkip(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		idleRuns   int
+		interval   time.Duration
+		maxBackoff time.Duration
+		expected   int
+	}{
+		{idleRuns: 0, interval: time.Hour, maxBackoff: 8 * time.Hour, expected: 0},
+		{idleRuns: 1, interval: time.Hour, maxBackoff: 8 * time.Hour, expected: 1},
+		{idleRuns: 2, interval: time.Hour, maxBackoff: 8 * time.Hour, expected: 3},
+		{idleRuns: 3, interval: time.Hour, maxBackoff: 8 * time.Hour, expected: 7},
+		{idleRuns: 100, interval: time.Hour, maxBackoff: 8 * time.Hour, expected: 7},
+		{idleRuns: 2, interval: time.Hour, maxBackoff: 3 * time.Hour, expected: 2},
+		{idleRuns: 5, interval: time.Hour, maxBackoff: time.Hour, expected: 0},
+		{idleRuns: 5, interval: time.Hour, maxBackoff: 0, expected: 0},
+	} {
+		assert.Equal(t, tc.expected, gcCyclesToSkip(tc.idleRuns, tc.interval, tc.maxBackoff), tc)
+	}
+}
+
+func TestGCConfigInit(t *testing.T) {
+	t.Parallel()
+
+	var config GCConfig
+	require.NoError(t, config.init())
+	assert.Equal(t, time.Hour, config.Interval)
+	assert.Equal(t, .5, config.DiscardRatio)
+
+	for _, config := range []GCConfig{
+		{Interval: -time.Second},
+		{DiscardRatio: -.5},
+		{DiscardRatio: 1},
+	} {
+		assert.Error(t, config.init(), config)
+	}
+}
@@ -23,11 +23,13 @@ import (
 	"storj.io/common/memory"
 	"storj.io/common/pb"
 	"storj.io/common/rpc/rpcstatus"
+	"storj.io/drpc/drpcctx"
 	"storj.io/drpc/drpcmanager"
 	"storj.io/drpc/drpcmux"
 	"storj.io/drpc/drpcserver"
 	"storj.io/drpc/drpcwire"
 	"storj.io/edge/pkg/auth/authdb"
+	"storj.io/edge/pkg/auth/ratelimit"
 )
 
 var mon = monkit.Package()
@@ -44,20 +46,24 @@ type Server struct {
 	db                   *authdb.Database
 	endpoint             *url.URL
 	accessGrantSizeLimit memory.Size
+	registrationLimit    *ratelimit.Limiter
 }
 
 // NewServer creates a Server that is not running.
+// If registrationLimit is nil then registrations won't be rate-limited.
 func NewServer(
 	log *zap.Logger,
 	db *authdb.Database,
 	endpoint *url.URL,
 	accessGrantSizeLimit memory.Size,
+	registrationLimit *ratelimit.Limiter,
 ) *Server {
 	return &Server{
 		log:                  log,
 		db:                   db,
 		endpoint:             endpoint,
 		accessGrantSizeLimit: accessGrantSizeLimit,
+		registrationLimit:    registrationLimit,
 	}
 }
 
@@ -71,6 +77,10 @@ func (g *Server) RegisterAccess(
 
 	g.log.Debug("DRPC RegisterAccess request")
 
+	if !g.registrationLimit.Allow(ctx, remoteIP(ctx)) {
+		return nil, g.wrapError("too many registrations, please try again later", "DRPC/RegisterAccess", rpcstatus.ResourceExhausted)
+	}
+
 	// NOTE(artur): DRPC's default message limit is 4 MiB, so we will read such
 	// messages anyway, but Auth Service would blow up memory consumption
 	// because it copies this access grant several times later on. Avoiding
@@ -86,6 +96,9 @@ func (g *Server) RegisterAccess(
 		if errs2.IsCanceled(err) {
 			return nil, g.wrapError(err.Error(), "DRPC/RegisterAccess", rpcstatus.Canceled)
 		}
+		if authdb.ErrAccessGrant.Has(err) {
+			return nil, g.wrapError(err.Error(), "DRPC/RegisterAccess", rpcstatus.InvalidArgument)
+		}
 		return nil, g.wrapError(err.Error(), "DRPC/RegisterAccess", rpcstatus.Internal)
 	}
 
@@ -105,7 +118,7 @@ func (g *Server) registerAccessImpl(
 		return nil, err
 	}
 
-	putResult, err := g.db.Put(ctx, accessKey, request.AccessGrant, request.Public)
+	putResult, err := g.db.Put(ctx, accessKey, request.AccessGrant, request.Public, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -120,6 +133,25 @@ func (g *Server) registerAccessImpl(
 	return &response, nil
 }
 
+// remoteIP returns the IP of the peer of the DRPC connection ctx belongs to
+// or an empty string if it's unknown. DRPC requests don't go through proxies
+// that would forward the client IP, so the peer is the client.
+func remoteIP(ctx context.Context) string {
+	tr, ok := drpcctx.Transport(ctx)
+	if !ok {
+		return ""
+	}
+	conn, ok := tr.(interface{ RemoteAddr() net.Addr })
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
 func (g *Server) wrapError(msg, method string, code rpcstatus.StatusCode) error {
 	g.log.Info("writing error", zap.String("msg", msg), zap.String("method", method), zap.String("code", code.String()))
 	switch code {
@@ -52,7 +52,7 @@ func createBackend(t *testing.T, sizeLimit memory.Size) (_ *Server, _ *authdb.Da
 	endpoint, err := url.Parse("http://gateway.test")
 	require.NoError(t, err)
 
-	return NewServer(logger, db, endpoint, sizeLimit), db, func() error {
+	return NewServer(logger, db, endpoint, sizeLimit, nil), db, func() error {
 		return errs.Combine(storage.Close(), logger.Sync())
 	}
 }
@@ -117,7 +117,7 @@ func TestRegisterAccessContextCanceled(t *testing.T) {
 	endpoint, err := url.Parse("http://gateway.test")
 	require.NoError(t, err)
 
-	server := NewServer(logger, db, endpoint, 4*memory.KiB)
+	server := NewServer(logger, db, endpoint, 4*memory.KiB, nil)
 
 	canceledCtx, cancel := context.WithCancel(ctx)
 	cancel()
@@ -4,6 +4,7 @@
 package httpauth
 
 import (
+	"context"
 	"crypto/subtle"
 	"encoding/json"
 	"errors"
@@ -19,7 +20,9 @@ import (
 	"storj.io/common/memory"
 	"storj.io/common/uuid"
 	"storj.io/edge/pkg/auth/authdb"
+	"storj.io/edge/pkg/auth/ratelimit"
 	"storj.io/edge/pkg/httplog"
+	"storj.io/edge/pkg/trustedip"
 )
 
 // Resources wrap a database and expose methods over HTTP.
@@ -32,20 +35,30 @@ type Resources struct {
 	id            *Arg
 	postSizeLimit memory.Size
 
-	log *zap.Logger
+	registrationLimit *ratelimit.Limiter
+
+	log             *zap.Logger
+	auditLog        *zap.Logger
+	auditTrustedIPs trustedip.List
 
 	startup    int32
 	inShutdown int32
 }
 
 // New constructs Resources for some database.
-// If getAccessRL is nil then GetAccess endpoint won't be rate-limited.
+// If registrationLimit is nil then registrations won't be rate-limited.
+// If auditLog is not nil, every retrieval of a public access grant is logged
+// to it, with the client IP taken from the headers of callers auditTrustedIPs
+// trusts.
 func New(
 	log *zap.Logger,
 	db *authdb.Database,
 	endpoint *url.URL,
 	authToken []string,
 	postSizeLimit memory.Size,
+	registrationLimit *ratelimit.Limiter,
+	auditLog *zap.Logger,
+	auditTrustedIPs trustedip.List,
 ) *Resources {
 	res := &Resources{
 		db:        db,
@@ -55,6 +68,10 @@ func New(
 		id:            new(Arg),
 		log:           log,
 		postSizeLimit: postSizeLimit,
+
+		registrationLimit: registrationLimit,
+		auditLog:          auditLog,
+		auditTrustedIPs:   auditTrustedIPs,
 	}
 
 	res.handler = Dir{
@@ -70,6 +87,11 @@ func New(
 						"GET": http.HandlerFunc(res.getLive),
 					},
 				},
+				"/ready": Dir{
+					"": Method{
+						"GET": http.HandlerFunc(res.getReady),
+					},
+				},
 			},
 			"/access": Dir{
 				"": Method{
@@ -88,6 +110,27 @@ func New(
 	return res
 }
 
+// HealthHandler returns an http.Handler of lightweight health checks for load
+// balancers, meant to be served on an address of its own. /health/live returns
+// 200 as long as the process serves requests, and /health/ready is like
+// /v1/health/ready.
+func (res *Resources) HealthHandler() http.Handler {
+	return Dir{
+		"/health": Dir{
+			"/live": Dir{
+				"": Method{
+					"GET": http.HandlerFunc(res.getAlive),
+				},
+			},
+			"/ready": Dir{
+				"": Method{
+					"GET": http.HandlerFunc(res.getReady),
+				},
+			},
+		},
+	}
+}
+
 // ServeHTTP makes Resources an http.Handler.
 func (res *Resources) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	// Below is a pre-flight check to make sure we don't unnecessarily read what
@@ -143,15 +186,21 @@ func (res *Resources) getStartup(w http.ResponseWriter, req *http.Request) {
 func (res *Resources) getLive(w http.ResponseWriter, req *http.Request) {
 	res.log.Debug("getLive request", zap.String("remote address", req.RemoteAddr))
 
-	// Confirm we have finished startup and are not shutting down.
-	if atomic.LoadInt32(&res.startup) == 0 || atomic.LoadInt32(&res.inShutdown) != 0 {
+	if !res.ready(req.Context()) {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		return
 	}
 
-	// Confirm we can at a minimum reach the database.
-	err := res.db.HealthCheck(req.Context())
-	if err != nil {
+	w.WriteHeader(http.StatusOK)
+}
+
+// getReady returns 200 when the service is ready to process requests, i.e. it
+// finished startup, isn't shutting down and its database responds, and 503
+// Service Unavailable otherwise.
+func (res *Resources) getReady(w http.ResponseWriter, req *http.Request) {
+	res.log.Debug("getReady request", zap.String("remote address", req.RemoteAddr))
+
+	if !res.ready(req.Context()) {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		return
 	}
@@ -159,12 +208,36 @@ func (res *Resources) getLive(w http.ResponseWriter, req *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// getAlive always returns 200; that it's able to is all it checks.
+func (res *Resources) getAlive(w http.ResponseWriter, req *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// ready returns whether the service finished startup, isn't shutting down and
+// can reach the database.
+func (res *Resources) ready(ctx context.Context) bool {
+	// Confirm we have finished startup and are not shutting down.
+	if atomic.LoadInt32(&res.startup) == 0 || atomic.LoadInt32(&res.inShutdown) != 0 {
+		return false
+	}
+
+	// Confirm we can at a minimum reach the database.
+	return res.db.HealthCheck(ctx) == nil
+}
+
 func (res *Resources) newAccess(w http.ResponseWriter, req *http.Request) {
 	res.newAccessCORS(w, req)
 	res.log.Debug("newAccess request", zap.String("remote address", req.RemoteAddr))
+
+	if !res.registrationLimit.AllowRequest(req) {
+		res.writeError(w, "newAccess", "too many registrations, please try again later", http.StatusTooManyRequests)
+		return
+	}
+
 	var request struct {
 		AccessGrant string `json:"access_grant"`
 		Public      bool   `json:"public"`
+		TTLSeconds  int64  `json:"ttl_seconds"`
 	}
 
 	reader := http.MaxBytesReader(w, req.Body, res.postSizeLimit.Int64())
@@ -187,7 +260,7 @@ func (res *Resources) newAccess(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	putResult, err := res.db.Put(req.Context(), key, request.AccessGrant, request.Public)
+	putResult, err := res.db.Put(req.Context(), key, request.AccessGrant, request.Public, time.Duration(request.TTLSeconds)*time.Second)
 	if err != nil {
 		if authdb.ErrAccessGrant.Has(err) {
 			res.writeError(w, "newAccess", err.Error(), http.StatusBadRequest)
@@ -201,22 +274,25 @@ func (res *Resources) newAccess(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	var response struct {
-		AccessKeyID                  string     `json:"access_key_id"`
-		SecretKey                    string     `json:"secret_key"`
-		Endpoint                     string     `json:"endpoint"`
-		FreeTierRestrictedExpiration *time.Time `json:"freeTierRestrictedExpiration,omitempty"`
+	response := newAccessResponse{
+		AccessKeyID:                  key.ToBase32(),
+		SecretKey:                    putResult.SecretKey.ToBase32(),
+		Endpoint:                     res.endpoint.String(),
+		FreeTierRestrictedExpiration: putResult.FreeTierRestrictedExpiration,
 	}
 
-	response.AccessKeyID = key.ToBase32()
-	response.SecretKey = putResult.SecretKey.ToBase32()
-	response.Endpoint = res.endpoint.String()
-	response.FreeTierRestrictedExpiration = putResult.FreeTierRestrictedExpiration
-
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(response)
 }
 
+// newAccessResponse is the response to creating an access.
+type newAccessResponse struct {
+	AccessKeyID                  string     `json:"access_key_id"`
+	SecretKey                    string     `json:"secret_key"`
+	Endpoint                     string     `json:"endpoint"`
+	FreeTierRestrictedExpiration *time.Time `json:"free_tier_restricted_expiration,omitempty"`
+}
+
 func (res *Resources) newAccessCORS(w http.ResponseWriter, req *http.Request) {
 	// TODO: we should be checking req.Header.Get("Origin") against
 	// an explicit allowlist and returning it here instead of "*" if it
@@ -227,6 +303,27 @@ func (res *Resources) newAccessCORS(w http.ResponseWriter, req *http.Request) {
 		"Content-Type, Accept, Accept-Language, Content-Language, Content-Length, Accept-Encoding")
 }
 
+// auditPublicAccess logs the retrieval of a public access grant to the audit
+// log, if there is one. Only the key hash is logged, never the access key, the
+// secret key or the access grant.
+//
+// Retrievals usually come from gateways or linksharing on behalf of their
+// clients, which they forward with the Forwarded header, so both the IP of the
+// client and the address of the caller are logged. The headers are only
+// believed if the caller is trusted; otherwise, anyone could make up the
+// client IP in the audit log.
+func (res *Resources) auditPublicAccess(req *http.Request, keyHash authdb.KeyHash) {
+	if res.auditLog == nil {
+		return
+	}
+	clientIP := trustedip.GetClientIP(res.auditTrustedIPs, req)
+	res.auditLog.Info("public access retrieved",
+		zap.String("key_hash", keyHash.ToHex()),
+		zap.String("client_ip", clientIP),
+		zap.String("remote_address", req.RemoteAddr),
+	)
+}
+
 func (res *Resources) requestAuthorized(req *http.Request) bool {
 	auth := req.Header.Get("Authorization")
 	if len(res.authToken) == 0 {
@@ -287,6 +384,10 @@ func (res *Resources) getAccess(w http.ResponseWriter, req *http.Request) {
 		}
 	}
 
+	if result.Public {
+		res.auditPublicAccess(req, key.Hash())
+	}
+
 	response.AccessGrant = result.AccessGrant
 	response.SecretKey = result.SecretKey.ToBase32()
 	response.Public = result.Public
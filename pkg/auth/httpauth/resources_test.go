@@ -12,11 +12,13 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 
 	"storj.io/common/grant"
 	"storj.io/common/macaroon"
@@ -26,6 +28,7 @@ import (
 	"storj.io/edge/pkg/auth/authdb"
 	"storj.io/edge/pkg/auth/badgerauth"
 	"storj.io/edge/pkg/nodelist"
+	"storj.io/edge/pkg/trustedip"
 )
 
 const minimalAccess = "13J4Upun87ATb3T5T5sDXVeQaCzWFZeF9Ly4ELfxS5hUwTL8APEkwahTEJ1wxZjyErimiDs3kgid33kDLuYPYtwaY7Toy32mCTapfrUB814X13RiA844HPWK3QLKZb9cAoVceTowmNZXWbcUMKNbkMHCURE4hn8ZrdHPE3S86yngjvDxwKmarfGx"
@@ -50,7 +53,7 @@ func TestResources_URLs(t *testing.T) {
 		req := httptest.NewRequest(method, path, nil)
 		req.Header.Set("Authorization", "Bearer authToken")
 
-		res := New(zaptest.NewLogger(t), nil, endpoint, []string{"authToken"}, 4*memory.KiB)
+		res := New(zaptest.NewLogger(t), nil, endpoint, []string{"authToken"}, 4*memory.KiB, nil, nil, trustedip.NewListUntrustAll())
 		res.ServeHTTP(rec, req)
 		return rec.Code != http.StatusNotFound && rec.Code != http.StatusMethodNotAllowed
 	}
@@ -126,13 +129,27 @@ func TestResources_CRUD(t *testing.T) {
 		require.False(t, ok)
 		_, ok = exec(res, "GET", "/v1/health/live", "")
 		require.False(t, ok)
+		_, ok = exec(res, "GET", "/v1/health/ready", "")
+		require.False(t, ok)
+		_, ok = exec(res.HealthHandler(), "GET", "/health/ready", "")
+		require.False(t, ok)
+		_, ok = exec(res.HealthHandler(), "GET", "/health/live", "")
+		require.True(t, ok)
 
 		res.SetStartupDone()
 
 		_, ok = exec(res, "GET", "/v1/health/live", "")
 		require.True(t, ok)
+		_, ok = exec(res, "GET", "/v1/health/ready", "")
+		require.True(t, ok)
+		_, ok = exec(res.HealthHandler(), "GET", "/health/ready", "")
+		require.True(t, ok)
 		_, ok = exec(res, "GET", path, "")
 		require.True(t, ok)
+
+		// the health handler serves nothing else.
+		_, ok = exec(res.HealthHandler(), "GET", "/v1/access/someid", "")
+		require.False(t, ok)
 	})
 
 	t.Run("CRUD", func(t *testing.T) {
@@ -147,6 +164,10 @@ func TestResources_CRUD(t *testing.T) {
 		createResult, ok := exec(res, "POST", "/v1/access", createRequest)
 		require.True(t, ok)
 		require.Equal(t, createResult["endpoint"], endpoint.String())
+		// the free tier restricted expiration is left out if there's none.
+		require.Len(t, createResult, 3)
+		require.Contains(t, createResult, "access_key_id")
+		require.Contains(t, createResult, "secret_key")
 		url := fmt.Sprintf("/v1/access/%s", createResult["access_key_id"])
 
 		// retrieve an access
@@ -156,6 +177,59 @@ func TestResources_CRUD(t *testing.T) {
 		require.Equal(t, createResult["secret_key"], fetchResult["secret_key"])
 	})
 
+	t.Run("AuditPublicAccess", func(t *testing.T) {
+		db, err := authdb.NewDatabase(zaptest.NewLogger(t), storage, authdb.Config{
+			AllowedSatelliteURLs: map[storj.NodeURL]struct{}{minimalAccessSatelliteID: {}},
+		})
+		require.NoError(t, err)
+
+		observedCore, observedLogs := observer.New(zap.InfoLevel)
+
+		// httptest requests come from 192.0.2.1.
+		for _, tc := range []struct {
+			trustedIPs trustedip.List
+			clientIP   string
+		}{
+			{trustedIPs: trustedip.NewList("192.0.2.1"), clientIP: "1.2.3.4"},
+			{trustedIPs: trustedip.NewList("192.0.2.2"), clientIP: "192.0.2.1"},
+			{trustedIPs: trustedip.NewListUntrustAll(), clientIP: "192.0.2.1"},
+		} {
+			res := New(logger, db, endpoint, []string{"authToken"}, 4*memory.KiB, nil, zap.New(observedCore), tc.trustedIPs)
+
+			for _, public := range []bool{false, true} {
+				createRequest := fmt.Sprintf(`{"access_grant": %q, "public": %t}`, minimalAccess, public)
+				createResult, ok := exec(res, "POST", "/v1/access", createRequest)
+				require.True(t, ok)
+
+				var key authdb.EncryptionKey
+				require.NoError(t, key.FromBase32(createResult["access_key_id"].(string)))
+
+				rec := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodGet, "/v1/access/"+key.ToBase32(), nil)
+				req.Header.Set("Authorization", "Bearer authToken")
+				req.Header.Set("Forwarded", "for=1.2.3.4")
+				res.ServeHTTP(rec, req)
+				require.Equal(t, http.StatusOK, rec.Code)
+
+				entries := observedLogs.TakeAll()
+				if !public {
+					require.Empty(t, entries)
+					continue
+				}
+				require.Len(t, entries, 1)
+
+				fields := entries[0].ContextMap()
+				require.Equal(t, key.Hash().ToHex(), fields["key_hash"])
+				require.Equal(t, tc.clientIP, fields["client_ip"])
+				require.Equal(t, req.RemoteAddr, fields["remote_address"])
+				for _, v := range fields {
+					require.NotContains(t, v, createResult["secret_key"])
+					require.NotContains(t, v, createResult["access_key_id"])
+				}
+			}
+		}
+	})
+
 	t.Run("ApprovedSatelliteID", func(t *testing.T) {
 		var unknownSatelliteID storj.NodeURL
 		unknownSatelliteID.ID[4] = 7
@@ -447,7 +521,7 @@ func TestResources_Shutdown(t *testing.T) {
 func TestResources_EntityTooLarge(t *testing.T) {
 	const path = "/v1/access"
 
-	res := New(zaptest.NewLogger(t), nil, nil, []string{""}, 1)
+	res := New(zaptest.NewLogger(t), nil, nil, []string{""}, 1, nil, nil, trustedip.NewListUntrustAll())
 
 	body := strings.NewReader("{}")
 
@@ -487,10 +561,32 @@ func TestResources_EntityTooLarge(t *testing.T) {
 	assert.Equal(t, http.StatusUnprocessableEntity, r.StatusCode)
 }
 
+func TestNewAccessResponseShape(t *testing.T) {
+	expiration := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, tc := range []struct {
+		response newAccessResponse
+		expected string
+	}{
+		{
+			response: newAccessResponse{AccessKeyID: "id", SecretKey: "secret", Endpoint: "https://gateway.test"},
+			expected: `{"access_key_id":"id","secret_key":"secret","endpoint":"https://gateway.test"}`,
+		},
+		{
+			response: newAccessResponse{AccessKeyID: "id", SecretKey: "secret", Endpoint: "https://gateway.test", FreeTierRestrictedExpiration: &expiration},
+			expected: `{"access_key_id":"id","secret_key":"secret","endpoint":"https://gateway.test","free_tier_restricted_expiration":"2025-01-02T03:04:05Z"}`,
+		},
+	} {
+		out, err := json.Marshal(tc.response)
+		require.NoError(t, err)
+		require.JSONEq(t, tc.expected, string(out))
+	}
+}
+
 func newResource(t *testing.T, logger *zap.Logger, db *authdb.Database, endpoint *url.URL) *Resources {
 	t.Helper()
 
-	return New(logger, db, endpoint, []string{"authToken"}, 4*memory.KiB)
+	return New(logger, db, endpoint, []string{"authToken"}, 4*memory.KiB, nil, nil, trustedip.NewListUntrustAll())
 }
 
 func newStorage(t *testing.T, logger *zap.Logger) (_ authdb.Storage) {
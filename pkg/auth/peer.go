@@ -13,6 +13,7 @@ import (
 
 	"github.com/pires/go-proxyproto"
 	"github.com/spacemonkeygo/monkit/v3"
+	mhttp "github.com/spacemonkeygo/monkit/v3/http"
 	"github.com/zeebo/errs"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -29,6 +30,7 @@ import (
 	"storj.io/edge/pkg/auth/badgerauth"
 	"storj.io/edge/pkg/auth/drpcauth"
 	"storj.io/edge/pkg/auth/httpauth"
+	"storj.io/edge/pkg/auth/ratelimit"
 	"storj.io/edge/pkg/auth/spannerauth"
 	"storj.io/edge/pkg/httplog"
 	"storj.io/edge/pkg/nodelist"
@@ -60,12 +62,19 @@ type Config struct {
 
 	ProxyAddrTLS string `help:"TLS address to listen on for PROXY protocol requests" default:":20005"`
 
-	CertFile                string   `user:"true" help:"server certificate file" default:""`
-	KeyFile                 string   `user:"true" help:"server key file" default:""`
-	PublicURL               []string `user:"true" help:"comma separated list of public urls for the server TLS certificates (e.g. https://auth.example.com,https://auth.us1.example.com)"`
-	RetrievePublicProjectID bool     `user:"true" help:"retrieve and store public project ID when registering access grant" default:"true"`
+	HealthListenAddr string `help:"HTTP address to serve the /health/live and /health/ready endpoints on; empty disables it" default:""`
+
+	CertFile                string        `user:"true" help:"server certificate file" default:""`
+	KeyFile                 string        `user:"true" help:"server key file" default:""`
+	PublicURL               []string      `user:"true" help:"comma separated list of public urls for the server TLS certificates (e.g. https://auth.example.com,https://auth.us1.example.com)"`
+	RetrievePublicProjectID bool          `user:"true" help:"retrieve and store public project ID when registering access grant" default:"true"`
+	MaxTTL                  time.Duration `help:"maximum time registered access keys stay valid, regardless of the expiration of their access grants. 0 means no limit" default:"0"`
+	AuditPublicAccess       bool          `help:"log every retrieval of a public access grant with its key hash and client IP to the audit logger" default:"false"`
+	AuditTrustedIPsList     []string      `help:"list of IPs or CIDR ranges (comma separated) of gateways, linksharing or load balancers whose headers identify the client IP logged to the audit logger; the remote address is logged for other callers"`
 
 	FreeTierAccessLimit authdb.FreeTierAccessLimitConfig
+	RegistrationLimit   ratelimit.Config
+	RegistrationPolicy  authdb.RegistrationPolicyConfig
 
 	CertMagic certMagic
 
@@ -99,6 +108,8 @@ type Peer struct {
 
 	proxyTLSListener net.Listener
 
+	healthListener net.Listener
+
 	config         Config
 	areSatsDynamic bool
 	endpoint       *url.URL
@@ -159,12 +170,29 @@ func New(ctx context.Context, log *zap.Logger, config Config, configDir string)
 		AllowedSatelliteURLs:    allowedSats,
 		RetrievePublicProjectID: config.RetrievePublicProjectID,
 		FreeTierAccessLimit:     config.FreeTierAccessLimit,
+		RegistrationPolicy:      config.RegistrationPolicy,
+		MaxTTL:                  config.MaxTTL,
 	})
 	if err != nil {
 		return nil, errs.Wrap(err)
 	}
 
-	res := httpauth.New(log.Named("resources"), adb, endpoint, config.AuthToken, config.POSTSizeLimit)
+	registrationLimit, err := ratelimit.New(config.RegistrationLimit)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+
+	var auditLog *zap.Logger
+	if config.AuditPublicAccess {
+		auditLog = log.Named("audit")
+	}
+
+	auditTrustedIPs := trustedip.NewListUntrustAll()
+	if len(config.AuditTrustedIPsList) > 0 {
+		auditTrustedIPs = trustedip.NewList(config.AuditTrustedIPsList...)
+	}
+
+	res := httpauth.New(log.Named("resources"), adb, endpoint, config.AuthToken, config.POSTSizeLimit, registrationLimit, auditLog, auditTrustedIPs)
 
 	tlsInfo := &TLSInfo{
 		CertFile:         config.CertFile,
@@ -185,34 +213,61 @@ func New(ctx context.Context, log *zap.Logger, config Config, configDir string)
 	}
 
 	// logging. do not log paths - paths have access keys in them.
-	handler = requestid.AddToContext(LogResponses(log, LogRequests(log, handler)))
-
-	drpcServer := drpcauth.NewServer(log, adb, endpoint, config.POSTSizeLimit)
+	handler = LogResponses(log, LogRequests(log, handler))
+	// continue traces of the clients (e.g. the gateway or linksharing) from
+	// the traceparent header instead of starting new ones.
+	handler = requestid.AddToContext(mhttp.TraceHandler(handler, mon))
+
+	drpcServer := drpcauth.NewServer(log, adb, endpoint, config.POSTSizeLimit, registrationLimit)
+
+	// listeners opened so far are closed if a later one fails to open.
+	var listeners []net.Listener
+	closeListeners := func(err error) error {
+		for _, listener := range listeners {
+			err = errs.Combine(err, listener.Close())
+		}
+		return errs.Wrap(err)
+	}
 
 	httpListener, err := net.Listen("tcp", config.ListenAddr)
 	if err != nil {
 		return nil, errs.Wrap(err)
 	}
+	listeners = append(listeners, httpListener)
+
 	drpcListener, err := net.Listen("tcp", config.DRPCListenAddr)
 	if err != nil {
-		return nil, errs.Wrap(err)
+		return nil, closeListeners(err)
+	}
+	listeners = append(listeners, drpcListener)
+
+	var healthListener net.Listener
+	if config.HealthListenAddr != "" {
+		healthListener, err = net.Listen("tcp", config.HealthListenAddr)
+		if err != nil {
+			return nil, closeListeners(err)
+		}
+		listeners = append(listeners, healthListener)
 	}
 
 	var httpsListener, drpcTLSListener, proxyTLSListener net.Listener
 	if tlsConfig != nil {
 		httpsListener, err = tls.Listen("tcp", config.ListenAddrTLS, tlsConfig)
 		if err != nil {
-			return nil, errs.Wrap(err)
+			return nil, closeListeners(err)
 		}
+		listeners = append(listeners, httpsListener)
+
 		drpcTLSListener, err = tls.Listen("tcp", config.DRPCListenAddrTLS, tlsConfig)
 		if err != nil {
-			return nil, errs.Wrap(err)
+			return nil, closeListeners(err)
 		}
+		listeners = append(listeners, drpcTLSListener)
 
 		if config.ProxyAddrTLS != "" {
 			proxyListener, err := net.Listen("tcp", config.ProxyAddrTLS)
 			if err != nil {
-				return nil, errs.Wrap(err)
+				return nil, closeListeners(err)
 			}
 
 			proxyTLSListener = tls.NewListener(&proxyproto.Listener{
@@ -240,6 +295,8 @@ func New(ctx context.Context, log *zap.Logger, config Config, configDir string)
 
 		proxyTLSListener: proxyTLSListener,
 
+		healthListener: healthListener,
+
 		config:         config,
 		areSatsDynamic: areSatsDynamic,
 		endpoint:       endpoint,
@@ -328,6 +385,13 @@ func (p *Peer) Run(ctx context.Context) (err error) {
 		return p.ServeDRPC(groupCtx, p.drpcListener)
 	})
 
+	if p.healthListener != nil {
+		group.Go(func() error {
+			p.log.Info("Starting health check server", zap.String("address", p.healthListener.Addr().String()))
+			return p.serveHTTP(groupCtx, p.healthListener, p.res.HealthHandler())
+		})
+	}
+
 	if p.tlsConfig == nil {
 		p.log.Info("not starting DRPC+TLS and HTTPS because of missing TLS configuration")
 	} else {
@@ -372,15 +436,22 @@ func (p *Peer) Close() error {
 	if p.proxyTLSListener != nil {
 		_ = p.proxyTLSListener.Close()
 	}
+	if p.healthListener != nil {
+		_ = p.healthListener.Close()
+	}
 
 	return errs.Wrap(p.storage.Close())
 }
 
 // ServeHTTP starts serving HTTP clients.
 func (p *Peer) ServeHTTP(ctx context.Context, listener net.Listener) (err error) {
+	return p.serveHTTP(ctx, listener, p.handler)
+}
+
+func (p *Peer) serveHTTP(ctx context.Context, listener net.Listener, handler http.Handler) (err error) {
 	server := http.Server{
 		IdleTimeout: p.config.IdleTimeout,
-		Handler:     p.handler,
+		Handler:     handler,
 	}
 
 	serverErr := make(chan error, 1)
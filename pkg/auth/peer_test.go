@@ -91,6 +91,49 @@ func TestPeer_BadListenerError(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestPeer_BadListenerClosesOthers(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	ts := dcsSatsTestServer()
+	defer ts.Close()
+
+	certFile, keyFile, _, _ := createSelfSignedCertificateFile(t, "localhost")
+
+	// find a free address for the health listener.
+	healthListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	healthAddr := healthListener.Addr().String()
+	require.NoError(t, healthListener.Close())
+
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ctx.Check(taken.Close)
+
+	config := Config{
+		Endpoint:          "https://example.com",
+		AllowedSatellites: []string{ts.URL + "/dcs-satellites"},
+		KVBackend:         "badger://",
+		ListenAddr:        "127.0.0.1:0",
+		DRPCListenAddr:    "127.0.0.1:0",
+		HealthListenAddr:  healthAddr,
+		ListenAddrTLS:     taken.Addr().String(),
+		DRPCListenAddrTLS: "127.0.0.1:0",
+		CertFile:          certFile.Name(),
+		KeyFile:           keyFile.Name(),
+		Node:              badgerauth.Config{FirstStart: true},
+	}
+
+	// the TLS listener fails after the health listener was opened.
+	_, err = New(ctx, zaptest.NewLogger(t), config, "")
+	require.Error(t, err)
+
+	// the health listener was closed, so its address can be used again.
+	healthListener, err = net.Listen("tcp", healthAddr)
+	require.NoError(t, err)
+	require.NoError(t, healthListener.Close())
+}
+
 type DRPCServerMock struct {
 	pb.DRPCEdgeAuthServer
 }
@@ -0,0 +1,187 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package ratelimit implements rate limiting of requests per client IP and
+// subnet.
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+
+	"storj.io/edge/internal/lrucache"
+	"storj.io/edge/pkg/trustedip"
+)
+
+var (
+	mon = monkit.Package()
+
+	// Error is the error class of this package.
+	Error = errs.Class("ratelimit")
+)
+
+// unknownIP is the key of the bucket shared by all requests whose client IP
+// is unknown or can't be parsed.
+const unknownIP = "unknown"
+
+// Config configures a Limiter.
+type Config struct {
+	Enabled              bool          `help:"whether to rate-limit requests per client IP" default:"false"`
+	Requests             int           `help:"number of requests a single client IP can make within the window" default:"100"`
+	SubnetRequests       int           `help:"number of requests all client IPs of a subnet can make within the window; 0 disables limiting subnets" default:"0"`
+	Window               time.Duration `help:"window over which requests are limited" default:"1m0s"`
+	SubnetPrefixV4       int           `help:"prefix length of IPv4 subnets" default:"24"`
+	SubnetPrefixV6       int           `help:"prefix length of IPv6 subnets" default:"64"`
+	MaxClients           int           `help:"maximum number of client IPs and subnets tracked at once; the least recently seen ones are forgotten first" default:"100000"`
+	ClientTrustedIPSList []string      `help:"list of clients IPs or CIDR ranges (comma separated) which are trusted; usually used when the service run behinds gateways, load balancers, etc."`
+	UseClientIPHeaders   bool          `help:"use the headers sent by the IPs set by --registration-limit.client-trusted-ips-list, which must not be empty, to identify the client IP" default:"false"`
+}
+
+// Limiter limits requests per client IP and, optionally, per subnet with
+// token buckets that refill over the configured window.
+//
+// A nil Limiter allows all requests.
+type Limiter struct {
+	config     Config
+	trustedIPs trustedip.List
+	now        func() time.Time
+
+	mu      sync.Mutex
+	ips     *lrucache.ExpiringLRUOf[*bucket]
+	subnets *lrucache.ExpiringLRUOf[*bucket]
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New returns a new Limiter or nil if config isn't enabled.
+func New(config Config) (*Limiter, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+
+	switch {
+	case config.Requests <= 0:
+		return nil, Error.New("requests must be positive")
+	case config.SubnetRequests < 0:
+		return nil, Error.New("subnet requests must not be negative")
+	case config.Window <= 0:
+		return nil, Error.New("window must be positive")
+	case config.SubnetPrefixV4 < 0 || config.SubnetPrefixV4 > 32:
+		return nil, Error.New("IPv4 subnet prefix length must be between 0 and 32, got %d", config.SubnetPrefixV4)
+	case config.SubnetPrefixV6 < 0 || config.SubnetPrefixV6 > 128:
+		return nil, Error.New("IPv6 subnet prefix length must be between 0 and 128, got %d", config.SubnetPrefixV6)
+	case config.MaxClients <= 0:
+		return nil, Error.New("max clients must be positive")
+	case config.UseClientIPHeaders && len(config.ClientTrustedIPSList) == 0:
+		return nil, Error.New("client IP headers can only be used with a list of trusted IPs")
+	}
+
+	trustedIPs := trustedip.NewListUntrustAll()
+	if config.UseClientIPHeaders {
+		trustedIPs = trustedip.NewList(config.ClientTrustedIPSList...)
+	}
+
+	// buckets don't expire, as their tokens refill with time anyway; the
+	// least recently used ones are evicted to stay within MaxClients.
+	newBuckets := func(name string) *lrucache.ExpiringLRUOf[*bucket] {
+		return lrucache.NewOf[*bucket](lrucache.Options{
+			Capacity: config.MaxClients,
+			Name:     name,
+		})
+	}
+
+	return &Limiter{
+		config:     config,
+		trustedIPs: trustedIPs,
+		now:        time.Now,
+		ips:        newBuckets("ratelimit_ips"),
+		subnets:    newBuckets("ratelimit_subnets"),
+	}, nil
+}
+
+// AllowRequest is like Allow for the client IP of r, which is taken from
+// headers if r comes from a trusted IP.
+func (l *Limiter) AllowRequest(r *http.Request) bool {
+	if l == nil {
+		return true
+	}
+	return l.Allow(r.Context(), trustedip.GetClientIP(l.trustedIPs, r))
+}
+
+// Allow returns whether a request of the client with the given IP is allowed
+// and consumes a token if it is. Requests whose client IP is empty or can't
+// be parsed share a single bucket.
+func (l *Limiter) Allow(ctx context.Context, ip string) bool {
+	if l == nil {
+		return true
+	}
+
+	key := unknownIP
+	parsed := net.ParseIP(ip)
+	if parsed != nil {
+		key = parsed.String()
+	} else {
+		mon.Event("ratelimit_unknown_ip")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	ipBucket := l.take(ctx, l.ips, key, l.config.Requests, now)
+	if ipBucket.tokens < 1 {
+		mon.Counter("ratelimit_rejected", monkit.NewSeriesTag("scope", "ip")).Inc(1)
+		return false
+	}
+
+	var subnetBucket *bucket
+	if l.config.SubnetRequests > 0 && parsed != nil {
+		subnetBucket = l.take(ctx, l.subnets, l.subnet(parsed), l.config.SubnetRequests, now)
+		if subnetBucket.tokens < 1 {
+			mon.Counter("ratelimit_rejected", monkit.NewSeriesTag("scope", "subnet")).Inc(1)
+			return false
+		}
+		subnetBucket.tokens--
+	}
+	ipBucket.tokens--
+
+	return true
+}
+
+// take returns the bucket for key in buckets, refilled up to limit tokens for
+// the time passed since it was last used.
+func (l *Limiter) take(ctx context.Context, buckets *lrucache.ExpiringLRUOf[*bucket], key string, limit int, now time.Time) *bucket {
+	// the function never fails, so neither does Get.
+	b, _ := buckets.Get(ctx, key, func() (*bucket, error) {
+		return &bucket{tokens: float64(limit), last: now}, nil
+	})
+
+	elapsed := now.Sub(b.last)
+	if elapsed > 0 {
+		b.tokens += float64(limit) * elapsed.Seconds() / l.config.Window.Seconds()
+		if b.tokens > float64(limit) {
+			b.tokens = float64(limit)
+		}
+		b.last = now
+	}
+	return b
+}
+
+// subnet returns the subnet of ip according to the configured prefixes.
+func (l *Limiter) subnet(ip net.IP) string {
+	mask := net.CIDRMask(l.config.SubnetPrefixV6, 128)
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, mask = ip4, net.CIDRMask(l.config.SubnetPrefixV4, 32)
+	}
+	return (&net.IPNet{IP: ip.Mask(mask), Mask: mask}).String()
+}
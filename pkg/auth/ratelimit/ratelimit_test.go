@@ -0,0 +1,154 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter(t *testing.T, config Config) (*Limiter, *time.Time) {
+	config.Enabled = true
+	if config.Window == 0 {
+		config.Window = time.Minute
+	}
+	if config.SubnetPrefixV4 == 0 {
+		config.SubnetPrefixV4 = 24
+	}
+	if config.SubnetPrefixV6 == 0 {
+		config.SubnetPrefixV6 = 64
+	}
+	if config.MaxClients == 0 {
+		config.MaxClients = 100
+	}
+
+	now := time.Unix(1700000000, 0)
+	l, err := New(config)
+	require.NoError(t, err)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	l, err := New(Config{Requests: 1, Window: time.Minute})
+	require.NoError(t, err)
+	require.Nil(t, l)
+	for i := 0; i < 10; i++ {
+		require.True(t, l.Allow(context.Background(), "1.2.3.4"))
+	}
+}
+
+func TestLimiterPerIP(t *testing.T) {
+	ctx := context.Background()
+	l, now := newTestLimiter(t, Config{Requests: 2})
+
+	require.True(t, l.Allow(ctx, "1.2.3.4"))
+	require.True(t, l.Allow(ctx, "1.2.3.4"))
+	require.False(t, l.Allow(ctx, "1.2.3.4"))
+
+	// other clients aren't affected.
+	require.True(t, l.Allow(ctx, "1.2.3.5"))
+
+	// unknown clients share a bucket.
+	require.True(t, l.Allow(ctx, ""))
+	require.True(t, l.Allow(ctx, "not an IP"))
+	require.False(t, l.Allow(ctx, ""))
+
+	// half the window refills half of the requests.
+	*now = now.Add(30 * time.Second)
+	require.True(t, l.Allow(ctx, "1.2.3.4"))
+	require.False(t, l.Allow(ctx, "1.2.3.4"))
+
+	*now = now.Add(time.Hour)
+	require.True(t, l.Allow(ctx, "1.2.3.4"))
+	require.True(t, l.Allow(ctx, "1.2.3.4"))
+	require.False(t, l.Allow(ctx, "1.2.3.4"))
+}
+
+func TestLimiterPerSubnet(t *testing.T) {
+	ctx := context.Background()
+	l, _ := newTestLimiter(t, Config{Requests: 2, SubnetRequests: 3})
+
+	require.True(t, l.Allow(ctx, "1.2.3.4"))
+	require.True(t, l.Allow(ctx, "1.2.3.5"))
+	require.True(t, l.Allow(ctx, "1.2.3.6"))
+	require.False(t, l.Allow(ctx, "1.2.3.7"))
+	require.True(t, l.Allow(ctx, "1.2.4.1"))
+
+	require.True(t, l.Allow(ctx, "2001:db8::1"))
+	require.True(t, l.Allow(ctx, "2001:db8::2"))
+	require.True(t, l.Allow(ctx, "2001:db8::3"))
+	require.False(t, l.Allow(ctx, "2001:db8::4"))
+	require.True(t, l.Allow(ctx, "2001:db8:0:1::1"))
+}
+
+func TestLimiterMaxClients(t *testing.T) {
+	ctx := context.Background()
+	l, _ := newTestLimiter(t, Config{Requests: 1, MaxClients: 2})
+
+	require.True(t, l.Allow(ctx, "1.2.3.4"))
+	require.True(t, l.Allow(ctx, "1.2.3.5"))
+	require.False(t, l.Allow(ctx, "1.2.3.4"))
+
+	// the least recently seen client is forgotten, the others aren't.
+	require.True(t, l.Allow(ctx, "1.2.3.6"))
+	require.False(t, l.Allow(ctx, "1.2.3.4"))
+	require.False(t, l.Allow(ctx, "1.2.3.6"))
+	require.True(t, l.Allow(ctx, "1.2.3.5"))
+}
+
+func TestLimiterInvalidConfig(t *testing.T) {
+	valid := Config{Enabled: true, Requests: 1, Window: time.Minute, SubnetPrefixV4: 24, SubnetPrefixV6: 64, MaxClients: 1}
+
+	for _, tc := range []struct {
+		desc   string
+		modify func(*Config)
+	}{
+		{desc: "no requests", modify: func(c *Config) { c.Requests = 0 }},
+		{desc: "negative subnet requests", modify: func(c *Config) { c.SubnetRequests = -1 }},
+		{desc: "no window", modify: func(c *Config) { c.Window = 0 }},
+		{desc: "IPv4 prefix too long", modify: func(c *Config) { c.SubnetPrefixV4 = 33 }},
+		{desc: "negative IPv4 prefix", modify: func(c *Config) { c.SubnetPrefixV4 = -1 }},
+		{desc: "IPv6 prefix too long", modify: func(c *Config) { c.SubnetPrefixV6 = 129 }},
+		{desc: "no max clients", modify: func(c *Config) { c.MaxClients = 0 }},
+		{desc: "client IP headers trusted from anyone", modify: func(c *Config) { c.UseClientIPHeaders = true }},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			config := valid
+			tc.modify(&config)
+			_, err := New(config)
+			require.Error(t, err)
+		})
+	}
+
+	l, err := New(valid)
+	require.NoError(t, err)
+	require.NotNil(t, l)
+}
+
+func TestLimiterAllowRequest(t *testing.T) {
+	l, _ := newTestLimiter(t, Config{Requests: 1, UseClientIPHeaders: true, ClientTrustedIPSList: []string{"10.0.0.1"}})
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/access", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Set("X-Forwarded-For", "1.2.3.4")
+	require.True(t, l.AllowRequest(r))
+	require.False(t, l.AllowRequest(r))
+
+	// another client behind the same proxy.
+	r.Header.Set("X-Forwarded-For", "1.2.3.5")
+	require.True(t, l.AllowRequest(r))
+
+	// untrusted peers can't pick their IP.
+	r.RemoteAddr = "10.0.0.2:1234"
+	r.Header.Set("X-Forwarded-For", "1.2.3.6")
+	require.True(t, l.AllowRequest(r))
+	require.False(t, l.AllowRequest(r))
+}
@@ -28,6 +28,10 @@ import (
 // a reasonable staleness value to use for good performance.
 const defaultExactStaleness = 15 * time.Second // [0, time.Hour)
 
+// maxExactStaleness is the upper bound of ReadStaleness (see Config), as
+// Cloud Spanner doesn't keep versions of data for longer than an hour.
+const maxExactStaleness = time.Hour
+
 var (
 	_ authdb.Storage      = (*CloudDatabase)(nil)
 	_ authdb.StorageAdmin = (*CloudDatabase)(nil)
@@ -41,6 +45,18 @@ var (
 type Config struct {
 	DatabaseName        string `user:"true" help:"name of Cloud Spanner database in the form projects/PROJECT_ID/instances/INSTANCE_ID/databases/DATABASE_ID"`
 	CredentialsFilename string `user:"true" help:"credentials file with access to Cloud Spanner database"`
+	// ReadStaleness is how stale record lookups may be, so that they can be
+	// served by any replica instead of only the leader. Zero uses
+	// defaultExactStaleness. Writes are always strongly consistent.
+	ReadStaleness time.Duration `help:"how stale record lookups may be to be served by the nearest replica; records not found in stale reads are looked up again with a strong read. It must be less than an hour" default:"15s"`
+	// SessionPoolMinOpened and SessionPoolMaxOpened bound the number of
+	// sessions, i.e. connections, of the client. Zero uses the defaults of
+	// the Cloud Spanner client.
+	SessionPoolMinOpened int `help:"minimum number of sessions kept open to Cloud Spanner; 0 uses the client default of 100" default:"0"`
+	SessionPoolMaxOpened int `help:"maximum number of sessions open to Cloud Spanner at once; 0 uses the client default of 400" default:"0"`
+	// OperationTimeout bounds every read and write, including the strong read
+	// that may follow a stale one. Zero disables it.
+	OperationTimeout time.Duration `help:"maximum time a single record read or write may take; 0 disables the timeout" default:"10s"`
 
 	// Address is used for Cloud Spanner Emulator in tests.
 	Address string `internal:"true"`
@@ -52,24 +68,51 @@ type CloudDatabase struct {
 	logger *zap.Logger
 	client *spanner.Client
 
-	table string
+	table            string
+	readStaleness    time.Duration
+	operationTimeout time.Duration
 }
 
 // Open returns initialized CloudDatabase connected to Cloud Spanner. If address
 // is specified in config, it configures options for Cloud Spanner Emulator.
 func Open(ctx context.Context, logger *zap.Logger, config Config) (*CloudDatabase, error) {
+	readStaleness := config.ReadStaleness
+	if readStaleness == 0 {
+		readStaleness = defaultExactStaleness
+	}
+	if readStaleness < 0 || readStaleness >= maxExactStaleness {
+		return nil, Error.New("read staleness must be between 0 and %s, got %s", maxExactStaleness, readStaleness)
+	}
+
+	if config.SessionPoolMinOpened < 0 || config.SessionPoolMaxOpened < 0 || config.OperationTimeout < 0 {
+		return nil, Error.New("session pool limits and operation timeout must not be negative")
+	}
+	sessionPool := spanner.DefaultSessionPoolConfig
+	if config.SessionPoolMinOpened > 0 {
+		sessionPool.MinOpened = uint64(config.SessionPoolMinOpened)
+	}
+	if config.SessionPoolMaxOpened > 0 {
+		sessionPool.MaxOpened = uint64(config.SessionPoolMaxOpened)
+	}
+	if sessionPool.MinOpened > sessionPool.MaxOpened {
+		return nil, Error.New("session pool min opened (%d) must not exceed max opened (%d)", sessionPool.MinOpened, sessionPool.MaxOpened)
+	}
+
 	opts := []option.ClientOption{option.WithCredentialsFile(config.CredentialsFilename)}
 	if config.Address != "" {
 		opts = append(opts, EmulatorOpts(config.Address)...)
 	}
 	c, err := spanner.NewClientWithConfig(ctx, config.DatabaseName, spanner.ClientConfig{
-		Logger:      zap.NewStdLog(logger),
-		Compression: "gzip",
+		SessionPoolConfig: sessionPool,
+		Logger:            zap.NewStdLog(logger),
+		Compression:       "gzip",
 	}, opts...)
 	return &CloudDatabase{
-		logger: logger,
-		client: c,
-		table:  "records",
+		logger:           logger,
+		client:           c,
+		table:            "records",
+		readStaleness:    readStaleness,
+		operationTimeout: config.OperationTimeout,
 	}, Error.Wrap(err)
 }
 
@@ -88,6 +131,9 @@ func EmulatorOpts(addr string) []option.ClientOption {
 func (d *CloudDatabase) Put(ctx context.Context, keyHash authdb.KeyHash, record *authdb.Record) (err error) {
 	defer mon.Task()(&ctx)(&err)
 
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
 	in := map[string]interface{}{
 		"encryption_key_hash": keyHash.Bytes(),
 		// "created_at" has default value
@@ -147,6 +193,9 @@ func (d *CloudDatabase) Get(ctx context.Context, keyHash authdb.KeyHash) (_ *aut
 func (d *CloudDatabase) GetFullRecord(ctx context.Context, keyHash authdb.KeyHash) (_ *authdb.FullRecord, err error) {
 	defer mon.Task()(&ctx)(&err)
 
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
 	key := spanner.Key{keyHash.Bytes()}
 	col := []string{
 		"public",
@@ -161,7 +210,7 @@ func (d *CloudDatabase) GetFullRecord(ctx context.Context, keyHash authdb.KeyHas
 		"invalidated_at",
 	}
 
-	boundedTx := d.client.Single().WithTimestampBound(spanner.ExactStaleness(defaultExactStaleness))
+	boundedTx := d.client.Single().WithTimestampBound(spanner.ExactStaleness(d.readStaleness))
 	defer boundedTx.Close()
 
 	row, err := boundedTx.ReadRow(ctx, d.table, key, col)
@@ -262,6 +311,9 @@ func (d *CloudDatabase) Close() error {
 func (d *CloudDatabase) Invalidate(ctx context.Context, keyHash authdb.KeyHash, reason string) (err error) {
 	defer mon.Task()(&ctx)(&err)
 
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
 	t, err := d.client.Apply(ctx, []*spanner.Mutation{spanner.UpdateMap(d.table, map[string]interface{}{
 		"encryption_key_hash": keyHash.Bytes(),
 		"invalidation_reason": reason,
@@ -280,6 +332,9 @@ func (d *CloudDatabase) Invalidate(ctx context.Context, keyHash authdb.KeyHash,
 func (d *CloudDatabase) Unpublish(ctx context.Context, keyHash authdb.KeyHash) (err error) {
 	defer mon.Task()(&ctx)(&err)
 
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
 	t, err := d.client.Apply(ctx, []*spanner.Mutation{spanner.UpdateMap(d.table, map[string]interface{}{
 		"encryption_key_hash": keyHash.Bytes(),
 		"public":              false,
@@ -297,6 +352,9 @@ func (d *CloudDatabase) Unpublish(ctx context.Context, keyHash authdb.KeyHash) (
 func (d *CloudDatabase) Delete(ctx context.Context, keyHash authdb.KeyHash) (err error) {
 	defer mon.Task()(&ctx)(&err)
 
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
 	t, err := d.client.Apply(ctx, []*spanner.Mutation{spanner.Delete(d.table, spanner.Key{keyHash.Bytes()})})
 	if err != nil {
 		return Error.Wrap(err)
@@ -307,6 +365,14 @@ func (d *CloudDatabase) Delete(ctx context.Context, keyHash authdb.KeyHash) (err
 	return nil
 }
 
+// withTimeout returns ctx bounded by the operation timeout, if there is one.
+func (d *CloudDatabase) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d.operationTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d.operationTimeout)
+}
+
 func isRecordNotFound(err error) bool {
 	return spanner.ErrCode(err) == codes.NotFound
 }
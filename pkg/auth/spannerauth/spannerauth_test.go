@@ -295,3 +295,92 @@ func createRandomRecord(t *testing.T, expiresAt time.Time, forcePublic bool) *au
 	}
 	return &r
 }
+
+func TestReadStaleness(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	logger := zaptest.NewLogger(t)
+	defer ctx.Check(logger.Sync)
+
+	server, err := spannerauthtest.ConfigureTestServer(ctx, logger)
+	require.NoError(t, err)
+	defer server.Close()
+
+	for _, staleness := range []time.Duration{-time.Second, time.Hour} {
+		_, err := spannerauth.Open(ctx, logger, spannerauth.Config{
+			DatabaseName:  "projects/P/instances/I/databases/D",
+			Address:       server.Addr,
+			ReadStaleness: staleness,
+		})
+		require.Error(t, err)
+	}
+
+	db, err := spannerauth.Open(ctx, logger, spannerauth.Config{
+		DatabaseName:  "projects/P/instances/I/databases/D",
+		Address:       server.Addr,
+		ReadStaleness: time.Second,
+	})
+	require.NoError(t, err)
+	defer ctx.Check(db.Close)
+
+	var k authdb.KeyHash
+	testrand.Read(k[:])
+
+	record := createRandomRecord(t, time.Time{}, true)
+	require.NoError(t, db.Put(ctx, k, record))
+
+	// the record was just inserted, so it's only found by the strong read
+	// that follows the stale one.
+	actual, err := db.Get(ctx, k)
+	require.NoError(t, err)
+	require.Equal(t, record.MacaroonHead, actual.MacaroonHead)
+}
+
+func TestSessionPoolAndTimeout(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	logger := zaptest.NewLogger(t)
+	defer ctx.Check(logger.Sync)
+
+	server, err := spannerauthtest.ConfigureTestServer(ctx, logger)
+	require.NoError(t, err)
+	defer server.Close()
+
+	for _, config := range []spannerauth.Config{
+		{SessionPoolMinOpened: -1},
+		{SessionPoolMaxOpened: -1},
+		{OperationTimeout: -time.Second},
+		{SessionPoolMinOpened: 10, SessionPoolMaxOpened: 5},
+	} {
+		config.DatabaseName = "projects/P/instances/I/databases/D"
+		config.Address = server.Addr
+		_, err := spannerauth.Open(ctx, logger, config)
+		require.Error(t, err)
+	}
+
+	db, err := spannerauth.Open(ctx, logger, spannerauth.Config{
+		DatabaseName:         "projects/P/instances/I/databases/D",
+		Address:              server.Addr,
+		SessionPoolMinOpened: 1,
+		SessionPoolMaxOpened: 5,
+		OperationTimeout:     time.Minute,
+	})
+	require.NoError(t, err)
+	defer ctx.Check(db.Close)
+
+	var k authdb.KeyHash
+	testrand.Read(k[:])
+
+	record := createRandomRecord(t, time.Time{}, true)
+	require.NoError(t, db.Put(ctx, k, record))
+
+	actual, err := db.Get(ctx, k)
+	require.NoError(t, err)
+	require.Equal(t, record.MacaroonHead, actual.MacaroonHead)
+
+	require.NoError(t, db.Invalidate(ctx, k, "test"))
+	_, err = db.Get(ctx, k)
+	require.True(t, authdb.Invalid.Has(err))
+}
@@ -15,6 +15,9 @@ import (
 	"storj.io/edge/pkg/auth/spannerauth"
 )
 
+// supportedSchemes lists the backends OpenStorage supports, for errors.
+const supportedSchemes = "badger://, spanner://"
+
 // OpenStorage opens the underlying storage for Auth Service's database,
 // determining the backend based on the connection string.
 func OpenStorage(ctx context.Context, log *zap.Logger, config Config) (_ authdb.Storage, err error) {
@@ -22,15 +25,18 @@ func OpenStorage(ctx context.Context, log *zap.Logger, config Config) (_ authdb.
 
 	driver, _, _, err := dbutil.SplitConnStr(config.KVBackend)
 	if err != nil {
-		return nil, err
+		return nil, errs.New("invalid kv-backend %q (supported: %s): %w", config.KVBackend, supportedSchemes, err)
 	}
 
 	switch driver {
 	case "badger":
 		return badgerauth.Open(log, config.Node)
 	case "spanner":
+		if config.Spanner.DatabaseName == "" {
+			return nil, errs.New("spanner.database-name is required with kv-backend %q", config.KVBackend)
+		}
 		return spannerauth.Open(ctx, log, config.Spanner)
 	default:
-		return nil, errs.New("unknown scheme: %q", config.KVBackend)
+		return nil, errs.New("unknown scheme %q of kv-backend %q (supported: %s)", driver, config.KVBackend, supportedSchemes)
 	}
 }
@@ -0,0 +1,30 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"storj.io/common/testcontext"
+)
+
+func TestOpenStorageErrors(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	for _, tc := range []struct {
+		kvBackend string
+		contains  string
+	}{
+		{kvBackend: "", contains: "supported: badger://, spanner://"},
+		{kvBackend: "memory://", contains: `unknown scheme "memory"`},
+		{kvBackend: "postgres://localhost/db", contains: "supported: badger://, spanner://"},
+		{kvBackend: "spanner://", contains: "spanner.database-name is required"},
+	} {
+		_, err := OpenStorage(ctx, zaptest.NewLogger(t), Config{KVBackend: tc.kvBackend})
+		require.ErrorContains(t, err, tc.contains, tc.kvBackend)
+	}
+}
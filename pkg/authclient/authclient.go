@@ -11,6 +11,7 @@ import (
 	"path"
 
 	"github.com/spacemonkeygo/monkit/v3"
+	mhttp "github.com/spacemonkeygo/monkit/v3/http"
 	"github.com/zeebo/errs"
 
 	"storj.io/common/http/requestid"
@@ -71,6 +72,7 @@ func (a *AuthClient) Resolve(ctx context.Context, accessKeyID string, clientIP s
 	req.Header.Set("Authorization", "Bearer "+a.Token)
 	req.Header.Set("Forwarded", "for="+clientIP)
 	requestid.Propagate(ctx, req)
+	mhttp.TraceInfoFromSpan(monkit.SpanFromCtx(ctx)).SetHeader(req.Header)
 
 	delay := a.BackOff
 	for {
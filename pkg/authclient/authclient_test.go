@@ -12,6 +12,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/spacemonkeygo/monkit/v3"
+	mhttp "github.com/spacemonkeygo/monkit/v3/http"
+	"github.com/spacemonkeygo/monkit/v3/present"
 	"github.com/stretchr/testify/require"
 
 	"storj.io/edge/pkg/errdata"
@@ -159,3 +162,28 @@ func TestBadStatusPassedThrough(t *testing.T) {
 func GetTestAuthClient(t *testing.T, baseURL, token string, timeout time.Duration) (*AuthClient, error) {
 	return New(Config{BaseURL: baseURL, Token: token, Timeout: timeout}), nil
 }
+
+func TestTracePropagated(t *testing.T) {
+	var info mhttp.TraceInfo
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info = mhttp.TraceInfoFromHeader(r.Header)
+		_, err := w.Write([]byte(`{"public":true,"secret_key":"mysecretkey","access_grant":"myaccessgrant"}`))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	client, err := GetTestAuthClient(t, ts.URL, "token", 2*time.Second)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	trace := monkit.NewTrace(monkit.NewId())
+	trace.Set(present.SampledKey, true)
+	defer mon.Func().RemoteTrace(&ctx, 0, trace)(nil)
+
+	_, err = client.Resolve(ctx, testKey, "127.0.0.1")
+	require.NoError(t, err)
+
+	require.NotNil(t, info.TraceId)
+	require.Equal(t, trace.Id(), *info.TraceId)
+	require.True(t, info.Sampled)
+}
@@ -0,0 +1,200 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package bucketconfig stores the configurations of buckets the edge services
+// implement themselves, e.g. CORS configurations, out of band in a bucket of
+// the operator, where tenants can't list, overwrite or delete them.
+package bucketconfig
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+
+	"storj.io/edge/internal/lrucache"
+	"storj.io/uplink"
+)
+
+// MaxSize is the maximum size of a stored configuration.
+const MaxSize = 64 << 10
+
+var (
+	mon = monkit.Package()
+
+	// Error is the error class of this package.
+	Error = errs.Class("bucket config")
+
+	// ErrNotFound is returned when a bucket has no stored configuration.
+	ErrNotFound = errs.New("the bucket configuration does not exist")
+
+	// ErrNotConfigured is returned when configurations are changed while no
+	// operator bucket is configured to store them in.
+	ErrNotConfigured = errs.New("bucket configurations aren't enabled")
+
+	// ErrUnknownProject is returned when the public ID of the project whose
+	// configurations are accessed isn't known, e.g. because the record of an
+	// access key hasn't been backfilled with it yet.
+	ErrUnknownProject = errs.New("the public ID of the project is unknown")
+)
+
+// Config configures where bucket configurations are stored.
+type Config struct {
+	Access          string        `help:"access grant of the operator's bucket that configurations of tenants' buckets, e.g. CORS, are stored in; empty disables changing them" default:""`
+	Bucket          string        `help:"operator's bucket that configurations of tenants' buckets are stored in" default:"bucket-configs"`
+	CacheExpiration time.Duration `help:"how long configurations of tenants' buckets are cached for" default:"1m0s"`
+	CacheCapacity   int           `help:"maximum number of cached configurations of tenants' buckets; 0 disables the cache" default:"10000"`
+}
+
+// Store stores configurations of buckets under the key of their project.
+//
+// A nil Store has no configurations.
+type Store struct {
+	uplinkConfig uplink.Config
+	access       *uplink.Access
+	bucket       string
+
+	cache *lrucache.ExpiringLRUOf[[]byte]
+}
+
+// Open returns a Store for config or nil if config has no access grant.
+func Open(config Config, uplinkConfig uplink.Config) (*Store, error) {
+	if config.Access == "" {
+		return nil, nil
+	}
+
+	access, err := uplink.ParseAccess(config.Access)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if config.Bucket == "" {
+		return nil, Error.New("bucket must be set")
+	}
+
+	return &Store{
+		uplinkConfig: uplinkConfig,
+		access:       access,
+		bucket:       config.Bucket,
+		cache: lrucache.NewOf[[]byte](lrucache.Options{
+			Expiration: config.CacheExpiration,
+			Capacity:   config.CacheCapacity,
+			Name:       "bucket_configs",
+		}),
+	}, nil
+}
+
+// ProjectKey returns the key identifying a project in the Store, its public
+// ID, or ErrUnknownProject if it isn't known. Nothing derived from API keys is
+// used instead, as different API keys of the project would then read and
+// write different configurations.
+func ProjectKey(publicProjectID string) (string, error) {
+	if publicProjectID == "" {
+		return "", ErrUnknownProject
+	}
+	return publicProjectID, nil
+}
+
+// Get returns the configuration called name of bucket in the project with
+// projectKey, or ErrNotFound if there's none.
+func (s *Store) Get(ctx context.Context, projectKey, bucket, name string) (_ []byte, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if s == nil {
+		return nil, ErrNotFound
+	}
+
+	key := objectKey(projectKey, bucket, name)
+
+	// missing configurations are cached as nil, so that the lookups done for
+	// every request of a bucket without one are cached too.
+	data, err := s.cache.Get(ctx, key, func() (_ []byte, err error) {
+		project, err := s.uplinkConfig.OpenProject(ctx, s.access)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { err = errs.Combine(err, project.Close()) }()
+
+		download, err := project.DownloadObject(ctx, s.bucket, key, nil)
+		if err != nil {
+			if errors.Is(err, uplink.ErrObjectNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		defer func() { err = errs.Combine(err, download.Close()) }()
+
+		return io.ReadAll(io.LimitReader(download, MaxSize))
+	})
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if data == nil {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
+
+// Put stores data as the configuration called name of bucket in the project
+// with projectKey.
+func (s *Store) Put(ctx context.Context, projectKey, bucket, name string, data []byte) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if s == nil {
+		return ErrNotConfigured
+	}
+	if len(data) > MaxSize {
+		return Error.New("configuration exceeds %d bytes", MaxSize)
+	}
+
+	key := objectKey(projectKey, bucket, name)
+	defer s.cache.Delete(ctx, key)
+
+	project, err := s.uplinkConfig.OpenProject(ctx, s.access)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, project.Close()) }()
+
+	upload, err := project.UploadObject(ctx, s.bucket, key, nil)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	if _, err = upload.Write(data); err != nil {
+		return Error.Wrap(errs.Combine(err, upload.Abort()))
+	}
+	return Error.Wrap(upload.Commit())
+}
+
+// Delete deletes the configuration called name of bucket in the project with
+// projectKey, if there's one.
+func (s *Store) Delete(ctx context.Context, projectKey, bucket, name string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if s == nil {
+		return ErrNotConfigured
+	}
+
+	key := objectKey(projectKey, bucket, name)
+	defer s.cache.Delete(ctx, key)
+
+	project, err := s.uplinkConfig.OpenProject(ctx, s.access)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, project.Close()) }()
+
+	if _, err = project.DeleteObject(ctx, s.bucket, key); err != nil && !errors.Is(err, uplink.ErrObjectNotFound) {
+		return Error.Wrap(err)
+	}
+	return nil
+}
+
+// objectKey returns the key of the object the configuration called name of
+// bucket in the project with projectKey is stored under.
+func objectKey(projectKey, bucket, name string) string {
+	return path.Join(projectKey, bucket, name)
+}
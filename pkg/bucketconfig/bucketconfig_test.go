@@ -0,0 +1,19 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package bucketconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectKey(t *testing.T) {
+	key, err := ProjectKey("project-id")
+	require.NoError(t, err)
+	require.Equal(t, "project-id", key)
+
+	_, err = ProjectKey("")
+	require.ErrorIs(t, err, ErrUnknownProject)
+}
@@ -0,0 +1,237 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package bucketwebsite implements the website configuration of buckets, which
+// is set with the PutBucketWebsite action of the gateway and consulted by
+// linksharing when hosting a website from the bucket.
+package bucketwebsite
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/zeebo/errs"
+)
+
+const (
+	// ConfigName is the name a bucket's website configuration is stored
+	// under in the bucket configuration store.
+	ConfigName = "website.xml"
+
+	// MaxConfigSize is the maximum size of a website configuration.
+	MaxConfigSize = 64 << 10
+
+	// maxRoutingRules is the maximum number of routing rules, as documented
+	// for AWS S3.
+	maxRoutingRules = 50
+)
+
+var (
+	// Error is the error class of invalid website configurations.
+	Error = errs.Class("website configuration")
+
+	// ErrMalformedXML is returned for website configurations that aren't
+	// well-formed XML or miss required elements.
+	ErrMalformedXML = errors.New("the XML you provided was not well-formed or did not validate against our published schema")
+)
+
+// Configuration is the WebsiteConfiguration XML document of the
+// PutBucketWebsite and GetBucketWebsite actions.
+type Configuration struct {
+	XMLName               xml.Name               `xml:"WebsiteConfiguration"`
+	IndexDocument         *IndexDocument         `xml:"IndexDocument,omitempty"`
+	ErrorDocument         *ErrorDocument         `xml:"ErrorDocument,omitempty"`
+	RedirectAllRequestsTo *RedirectAllRequestsTo `xml:"RedirectAllRequestsTo,omitempty"`
+	RoutingRules          []RoutingRule          `xml:"RoutingRules>RoutingRule,omitempty"`
+}
+
+// IndexDocument is the object served for requests of prefixes.
+type IndexDocument struct {
+	Suffix string `xml:"Suffix"`
+}
+
+// ErrorDocument is the object served for requests of missing objects.
+type ErrorDocument struct {
+	Key string `xml:"Key"`
+}
+
+// RedirectAllRequestsTo redirects all requests to another host.
+type RedirectAllRequestsTo struct {
+	HostName string `xml:"HostName"`
+	Protocol string `xml:"Protocol,omitempty"`
+}
+
+// RoutingRule redirects the requests matching its condition.
+type RoutingRule struct {
+	Condition *Condition `xml:"Condition,omitempty"`
+	Redirect  Redirect   `xml:"Redirect"`
+}
+
+// Condition is the condition of a routing rule. Every set element must match.
+type Condition struct {
+	KeyPrefixEquals             string `xml:"KeyPrefixEquals,omitempty"`
+	HTTPErrorCodeReturnedEquals string `xml:"HttpErrorCodeReturnedEquals,omitempty"`
+}
+
+// Redirect is where a routing rule redirects requests to.
+type Redirect struct {
+	HostName             string `xml:"HostName,omitempty"`
+	HTTPRedirectCode     string `xml:"HttpRedirectCode,omitempty"`
+	Protocol             string `xml:"Protocol,omitempty"`
+	ReplaceKeyPrefixWith string `xml:"ReplaceKeyPrefixWith,omitempty"`
+	ReplaceKeyWith       string `xml:"ReplaceKeyWith,omitempty"`
+}
+
+// Parse parses and validates a WebsiteConfiguration XML document.
+func Parse(r io.Reader) (config Configuration, err error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxConfigSize+1))
+	if err != nil {
+		return Configuration{}, err
+	}
+	if len(data) > MaxConfigSize {
+		return Configuration{}, Error.New("must not exceed 64 KB")
+	}
+
+	if err = xml.Unmarshal(data, &config); err != nil {
+		return Configuration{}, ErrMalformedXML
+	}
+
+	return config, config.Validate()
+}
+
+// Validate returns an error if config isn't a valid website configuration.
+func (config Configuration) Validate() error {
+	if redirect := config.RedirectAllRequestsTo; redirect != nil {
+		if config.IndexDocument != nil || config.ErrorDocument != nil || len(config.RoutingRules) > 0 {
+			return Error.New("RedirectAllRequestsTo cannot be provided in conjunction with other elements")
+		}
+		if redirect.HostName == "" {
+			return ErrMalformedXML
+		}
+		return validateProtocol(redirect.Protocol)
+	}
+
+	if config.IndexDocument == nil || config.IndexDocument.Suffix == "" {
+		return Error.New("a value for IndexDocument Suffix must be provided if RedirectAllRequestsTo is empty")
+	}
+	if strings.Contains(config.IndexDocument.Suffix, "/") {
+		return Error.New("the IndexDocument Suffix must not contain slashes")
+	}
+	if config.ErrorDocument != nil && config.ErrorDocument.Key == "" {
+		return Error.New("the ErrorDocument Key must not be empty")
+	}
+
+	if len(config.RoutingRules) > maxRoutingRules {
+		return Error.New("must not have more than %d routing rules", maxRoutingRules)
+	}
+	for _, rule := range config.RoutingRules {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (rule RoutingRule) validate() error {
+	if rule.Condition != nil && rule.Condition.HTTPErrorCodeReturnedEquals != "" {
+		code, err := strconv.Atoi(rule.Condition.HTTPErrorCodeReturnedEquals)
+		if err != nil || code < 400 || code > 599 {
+			return Error.New("the HttpErrorCodeReturnedEquals %q is not a valid HTTP error code", rule.Condition.HTTPErrorCodeReturnedEquals)
+		}
+	}
+
+	redirect := rule.Redirect
+	if redirect == (Redirect{}) {
+		return Error.New("a routing rule must redirect somewhere")
+	}
+	if redirect.ReplaceKeyPrefixWith != "" && redirect.ReplaceKeyWith != "" {
+		return Error.New("ReplaceKeyPrefixWith and ReplaceKeyWith cannot both be provided")
+	}
+	if redirect.HTTPRedirectCode != "" {
+		code, err := strconv.Atoi(redirect.HTTPRedirectCode)
+		if err != nil || code < 300 || code > 399 {
+			return Error.New("the HttpRedirectCode %q is not a valid HTTP redirect code", redirect.HTTPRedirectCode)
+		}
+	}
+
+	return validateProtocol(redirect.Protocol)
+}
+
+func validateProtocol(protocol string) error {
+	switch protocol {
+	case "", "http", "https":
+		return nil
+	default:
+		return Error.New("the Protocol %q must be http or https", protocol)
+	}
+}
+
+// Route returns the first routing rule matching key, which is relative to
+// the root of the website, and the HTTP status code of the response, or nil
+// if none does. statusCode is zero before the object is looked up, so that
+// only rules without an HttpErrorCodeReturnedEquals condition match.
+func (config Configuration) Route(key string, statusCode int) *RoutingRule {
+	for i, rule := range config.RoutingRules {
+		if rule.Condition == nil {
+			return &config.RoutingRules[i]
+		}
+		if !strings.HasPrefix(key, rule.Condition.KeyPrefixEquals) {
+			continue
+		}
+		if code := rule.Condition.HTTPErrorCodeReturnedEquals; code != "" && code != strconv.Itoa(statusCode) {
+			continue
+		}
+		return &config.RoutingRules[i]
+	}
+	return nil
+}
+
+// Target returns the location and status code of the redirect of a request
+// of key, which is relative to the root of the website. current is the URL of
+// the request, whose scheme and host are kept unless the rule replaces them.
+func (rule RoutingRule) Target(current *url.URL, key string) (location string, statusCode int) {
+	target := url.URL{Scheme: current.Scheme, Host: current.Host}
+
+	redirect := rule.Redirect
+	if redirect.Protocol != "" {
+		target.Scheme = redirect.Protocol
+	}
+	if redirect.HostName != "" {
+		target.Host = redirect.HostName
+	}
+
+	switch {
+	case redirect.ReplaceKeyWith != "":
+		key = redirect.ReplaceKeyWith
+	case redirect.ReplaceKeyPrefixWith != "":
+		var prefix string
+		if rule.Condition != nil {
+			prefix = rule.Condition.KeyPrefixEquals
+		}
+		key = redirect.ReplaceKeyPrefixWith + strings.TrimPrefix(key, prefix)
+	}
+	target.Path = "/" + key
+
+	statusCode = 301
+	if redirect.HTTPRedirectCode != "" {
+		// validated to be a 3xx code.
+		statusCode, _ = strconv.Atoi(redirect.HTTPRedirectCode)
+	}
+
+	return target.String(), statusCode
+}
+
+// Target returns the location of the redirect of a request of current.
+func (redirect RedirectAllRequestsTo) Target(current *url.URL) string {
+	target := *current
+	target.Host = redirect.HostName
+	if redirect.Protocol != "" {
+		target.Scheme = redirect.Protocol
+	}
+	return target.String()
+}
@@ -0,0 +1,112 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package bucketwebsite_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/edge/pkg/bucketwebsite"
+)
+
+func TestParse(t *testing.T) {
+	config, err := bucketwebsite.Parse(strings.NewReader(`
+<WebsiteConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+	<IndexDocument><Suffix>index.htm</Suffix></IndexDocument>
+	<ErrorDocument><Key>errors/404.html</Key></ErrorDocument>
+	<RoutingRules>
+		<RoutingRule>
+			<Condition><KeyPrefixEquals>docs/</KeyPrefixEquals></Condition>
+			<Redirect><ReplaceKeyPrefixWith>documents/</ReplaceKeyPrefixWith></Redirect>
+		</RoutingRule>
+		<RoutingRule>
+			<Condition><HttpErrorCodeReturnedEquals>404</HttpErrorCodeReturnedEquals></Condition>
+			<Redirect><HostName>example.com</HostName><Protocol>https</Protocol><HttpRedirectCode>302</HttpRedirectCode></Redirect>
+		</RoutingRule>
+	</RoutingRules>
+</WebsiteConfiguration>`))
+	require.NoError(t, err)
+	require.Equal(t, "index.htm", config.IndexDocument.Suffix)
+	require.Equal(t, "errors/404.html", config.ErrorDocument.Key)
+	require.Len(t, config.RoutingRules, 2)
+
+	config, err = bucketwebsite.Parse(strings.NewReader(`<WebsiteConfiguration><RedirectAllRequestsTo><HostName>example.com</HostName></RedirectAllRequestsTo></WebsiteConfiguration>`))
+	require.NoError(t, err)
+	require.Equal(t, "example.com", config.RedirectAllRequestsTo.HostName)
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		config    string
+		malformed bool
+	}{
+		{config: `<WebsiteConfiguration>`, malformed: true},
+		{config: `<CORSConfiguration></CORSConfiguration>`, malformed: true},
+		{config: `<WebsiteConfiguration><RedirectAllRequestsTo></RedirectAllRequestsTo></WebsiteConfiguration>`, malformed: true},
+		{config: `<WebsiteConfiguration></WebsiteConfiguration>`},
+		{config: `<WebsiteConfiguration><IndexDocument><Suffix>a/index.html</Suffix></IndexDocument></WebsiteConfiguration>`},
+		{config: `<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument><ErrorDocument></ErrorDocument></WebsiteConfiguration>`},
+		{config: `<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument><RedirectAllRequestsTo><HostName>example.com</HostName></RedirectAllRequestsTo></WebsiteConfiguration>`},
+		{config: `<WebsiteConfiguration><RedirectAllRequestsTo><HostName>example.com</HostName><Protocol>ftp</Protocol></RedirectAllRequestsTo></WebsiteConfiguration>`},
+		{config: `<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument><RoutingRules><RoutingRule><Redirect></Redirect></RoutingRule></RoutingRules></WebsiteConfiguration>`},
+		{config: `<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument><RoutingRules><RoutingRule><Redirect><HttpRedirectCode>200</HttpRedirectCode></Redirect></RoutingRule></RoutingRules></WebsiteConfiguration>`},
+		{config: `<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument><RoutingRules><RoutingRule><Condition><HttpErrorCodeReturnedEquals>301</HttpErrorCodeReturnedEquals></Condition><Redirect><HostName>example.com</HostName></Redirect></RoutingRule></RoutingRules></WebsiteConfiguration>`},
+		{config: `<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument><RoutingRules><RoutingRule><Redirect><ReplaceKeyWith>a</ReplaceKeyWith><ReplaceKeyPrefixWith>b</ReplaceKeyPrefixWith></Redirect></RoutingRule></RoutingRules></WebsiteConfiguration>`},
+		{config: `<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument>` + strings.Repeat(" ", bucketwebsite.MaxConfigSize) + `</WebsiteConfiguration>`},
+	} {
+		_, err := bucketwebsite.Parse(strings.NewReader(tt.config))
+		if tt.malformed {
+			require.ErrorIs(t, err, bucketwebsite.ErrMalformedXML, tt.config)
+		} else {
+			require.True(t, bucketwebsite.Error.Has(err), tt.config)
+		}
+	}
+}
+
+func TestRoute(t *testing.T) {
+	config := bucketwebsite.Configuration{
+		IndexDocument: &bucketwebsite.IndexDocument{Suffix: "index.html"},
+		RoutingRules: []bucketwebsite.RoutingRule{
+			{
+				Condition: &bucketwebsite.Condition{KeyPrefixEquals: "docs/"},
+				Redirect:  bucketwebsite.Redirect{ReplaceKeyPrefixWith: "documents/"},
+			},
+			{
+				Condition: &bucketwebsite.Condition{KeyPrefixEquals: "old", HTTPErrorCodeReturnedEquals: "404"},
+				Redirect:  bucketwebsite.Redirect{HostName: "archive.test", Protocol: "https", ReplaceKeyWith: "gone.html", HTTPRedirectCode: "302"},
+			},
+		},
+	}
+	current, err := url.Parse("http://site.test/docs/a.html?x=1")
+	require.NoError(t, err)
+
+	require.Nil(t, config.Route("other.html", 0))
+	require.Nil(t, config.Route("old.html", 0))
+	require.Nil(t, config.Route("old.html", 403))
+
+	rule := config.Route("docs/a.html", 0)
+	require.NotNil(t, rule)
+	location, status := rule.Target(current, "docs/a.html")
+	require.Equal(t, "http://site.test/documents/a.html", location)
+	require.Equal(t, 301, status)
+
+	rule = config.Route("old.html", 404)
+	require.NotNil(t, rule)
+	location, status = rule.Target(current, "old.html")
+	require.Equal(t, "https://archive.test/gone.html", location)
+	require.Equal(t, 302, status)
+
+	// rules without a condition match every request.
+	config.RoutingRules = []bucketwebsite.RoutingRule{{Redirect: bucketwebsite.Redirect{HostName: "new.test"}}}
+	rule = config.Route("anything", 0)
+	require.NotNil(t, rule)
+	location, _ = rule.Target(current, "anything")
+	require.Equal(t, "http://new.test/anything", location)
+
+	redirect := bucketwebsite.RedirectAllRequestsTo{HostName: "new.test", Protocol: "https"}
+	require.Equal(t, "https://new.test/docs/a.html?x=1", redirect.Target(current))
+}
@@ -0,0 +1,149 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package health implements a graded health signal based on rolling backend
+// error rates.
+package health
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zeebo/errs"
+)
+
+// Status is the graded health of a service.
+type Status string
+
+const (
+	// StatusHealthy means backend error rates are below all thresholds.
+	StatusHealthy Status = "healthy"
+	// StatusDegraded means backend error rates exceed the degraded threshold.
+	StatusDegraded Status = "degraded"
+	// StatusUnhealthy means backend error rates exceed the unhealthy
+	// threshold.
+	StatusUnhealthy Status = "unhealthy"
+)
+
+// numBuckets is the number of buckets the window is divided into.
+const numBuckets = 10
+
+// Config configures the error-rate tracker.
+type Config struct {
+	Enabled            bool          `user:"true" help:"whether to report degraded or unhealthy status on the health endpoint based on backend error rates" default:"false"`
+	Window             time.Duration `user:"true" help:"window over which backend error rates are computed" default:"1m0s"`
+	MinRequests        int64         `user:"true" help:"minimum number of requests within the window before error rates are considered" default:"100"`
+	DegradedThreshold  float64       `user:"true" help:"backend error rate (0-1) at which the service reports degraded" default:"0.05"`
+	UnhealthyThreshold float64       `user:"true" help:"backend error rate (0-1) at which the service reports unhealthy" default:"0.5"`
+}
+
+type bucket struct {
+	index  int64
+	total  int64
+	failed int64
+}
+
+// Tracker keeps a rolling count of backend requests and failures.
+type Tracker struct {
+	config Config
+	width  time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	buckets [numBuckets]bucket
+}
+
+// NewTracker returns a new Tracker. It returns nil if the tracker is
+// disabled; all methods of a nil Tracker are safe to call.
+func NewTracker(config Config) (*Tracker, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+
+	switch {
+	case config.Window <= 0:
+		return nil, errs.New("health window must be positive")
+	case config.MinRequests < 0:
+		return nil, errs.New("health minimum requests must not be negative")
+	case config.DegradedThreshold <= 0 || config.DegradedThreshold > 1:
+		return nil, errs.New("health degraded threshold must be in (0, 1], got %v", config.DegradedThreshold)
+	case config.UnhealthyThreshold <= 0 || config.UnhealthyThreshold > 1:
+		return nil, errs.New("health unhealthy threshold must be in (0, 1], got %v", config.UnhealthyThreshold)
+	case config.DegradedThreshold > config.UnhealthyThreshold:
+		return nil, errs.New("health degraded threshold must not exceed the unhealthy threshold")
+	}
+
+	width := config.Window / numBuckets
+	if width <= 0 {
+		width = 1
+	}
+
+	return &Tracker{
+		config: config,
+		width:  width,
+		now:    time.Now,
+	}, nil
+}
+
+// Observe records the outcome of a single backend request.
+func (t *Tracker) Observe(failed bool) {
+	if t == nil {
+		return
+	}
+
+	index := t.now().UnixNano() / int64(t.width)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b := &t.buckets[index%numBuckets]
+	if b.index != index {
+		*b = bucket{index: index}
+	}
+	b.total++
+	if failed {
+		b.failed++
+	}
+}
+
+// Status returns the current status and, unless the status is healthy, the
+// reason for it.
+func (t *Tracker) Status() (Status, string) {
+	if t == nil {
+		return StatusHealthy, ""
+	}
+
+	index := t.now().UnixNano() / int64(t.width)
+
+	var total, failed int64
+
+	t.mu.Lock()
+	for _, b := range t.buckets {
+		if b.index > index-numBuckets && b.index <= index {
+			total += b.total
+			failed += b.failed
+		}
+	}
+	t.mu.Unlock()
+
+	if total == 0 || total < t.config.MinRequests {
+		return StatusHealthy, ""
+	}
+
+	rate := float64(failed) / float64(total)
+
+	switch {
+	case rate >= t.config.UnhealthyThreshold:
+		return StatusUnhealthy, t.reason(rate, failed, total, t.config.UnhealthyThreshold)
+	case rate >= t.config.DegradedThreshold:
+		return StatusDegraded, t.reason(rate, failed, total, t.config.DegradedThreshold)
+	default:
+		return StatusHealthy, ""
+	}
+}
+
+func (t *Tracker) reason(rate float64, failed, total int64, threshold float64) string {
+	return fmt.Sprintf("backend error rate %.1f%% (%d/%d) over %s exceeds threshold %.1f%%",
+		rate*100, failed, total, t.config.Window, threshold*100)
+}
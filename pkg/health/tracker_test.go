@@ -0,0 +1,100 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tracker, err := NewTracker(Config{
+		Enabled:            true,
+		Window:             10 * time.Second,
+		MinRequests:        10,
+		DegradedThreshold:  0.1,
+		UnhealthyThreshold: 0.5,
+	})
+	require.NoError(t, err)
+	tracker.now = func() time.Time { return now }
+
+	status, reason := tracker.Status()
+	require.Equal(t, StatusHealthy, status)
+	require.Empty(t, reason)
+
+	// too few requests to be considered.
+	for i := 0; i < 5; i++ {
+		tracker.Observe(true)
+	}
+	status, _ = tracker.Status()
+	require.Equal(t, StatusHealthy, status)
+
+	for i := 0; i < 15; i++ {
+		tracker.Observe(false)
+	}
+	status, reason = tracker.Status()
+	require.Equal(t, StatusDegraded, status)
+	require.Contains(t, reason, "(5/20)")
+
+	for i := 0; i < 20; i++ {
+		tracker.Observe(true)
+	}
+	status, reason = tracker.Status()
+	require.Equal(t, StatusUnhealthy, status)
+	require.Contains(t, reason, "(25/40)")
+
+	// observations fall out of the window.
+	now = now.Add(11 * time.Second)
+	status, reason = tracker.Status()
+	require.Equal(t, StatusHealthy, status)
+	require.Empty(t, reason)
+
+	for i := 0; i < 10; i++ {
+		tracker.Observe(false)
+	}
+	status, _ = tracker.Status()
+	require.Equal(t, StatusHealthy, status)
+}
+
+func TestTrackerDisabled(t *testing.T) {
+	tracker, err := NewTracker(Config{})
+	require.NoError(t, err)
+	require.Nil(t, tracker)
+
+	tracker.Observe(true)
+	status, reason := tracker.Status()
+	require.Equal(t, StatusHealthy, status)
+	require.Empty(t, reason)
+}
+
+func TestTrackerInvalidConfig(t *testing.T) {
+	valid := Config{Enabled: true, Window: time.Minute, DegradedThreshold: 0.05, UnhealthyThreshold: 0.5}
+
+	for _, tc := range []struct {
+		desc   string
+		modify func(*Config)
+	}{
+		{desc: "no window", modify: func(c *Config) { c.Window = 0 }},
+		{desc: "negative min requests", modify: func(c *Config) { c.MinRequests = -1 }},
+		{desc: "zero degraded threshold", modify: func(c *Config) { c.DegradedThreshold = 0 }},
+		{desc: "unhealthy threshold above 1", modify: func(c *Config) { c.UnhealthyThreshold = 1.5 }},
+		{desc: "degraded above unhealthy", modify: func(c *Config) { c.DegradedThreshold = 0.6 }},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			config := valid
+			tc.modify(&config)
+			_, err := NewTracker(config)
+			require.Error(t, err)
+		})
+	}
+
+	tracker, err := NewTracker(valid)
+	require.NoError(t, err)
+	require.NotNil(t, tracker)
+}
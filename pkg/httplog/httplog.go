@@ -8,13 +8,21 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"storj.io/common/useragent"
 	xhttp "storj.io/minio/cmd/http"
 )
 
+// maxUserAgentProductLength is the maximum length in bytes of the products
+// returned by UserAgentProduct.
+const maxUserAgentProductLength = 32
+
 // Known headers and query string values that should be redacted and not logged.
 // References:
 // https://docs.aws.amazon.com/general/latest/gr/signature-version-2.html
@@ -30,12 +38,48 @@ var (
 	}
 
 	confidentialHeaders = map[string]struct{}{
-		xhttp.Authorization: {},
-		"Cookie":            {},
 		xhttp.AmzCopySource: {},
 	}
 )
 
+// defaultRedactedHeaders are the headers carrying credentials, whose values
+// are never logged, not even with confidential sanitization disabled.
+var defaultRedactedHeaders = []string{
+	xhttp.Authorization,
+	"Cookie",
+	"Proxy-Authorization",
+	"Set-Cookie",
+	xhttp.AmzSecurityToken,
+}
+
+// redactedHeaders is the central set of headers whose values are never
+// logged; see SetRedactedHeaders.
+var redactedHeaders atomic.Pointer[map[string]struct{}]
+
+func init() {
+	SetRedactedHeaders(nil)
+}
+
+// SetRedactedHeaders sets the headers whose values are never logged, not
+// even with confidential sanitization disabled, to the default ones and
+// additional. It's meant to be called once at startup.
+func SetRedactedHeaders(additional []string) {
+	headers := make(map[string]struct{}, len(defaultRedactedHeaders)+len(additional))
+	for _, name := range append(append([]string(nil), defaultRedactedHeaders...), additional...) {
+		if name = strings.TrimSpace(name); name != "" {
+			headers[http.CanonicalHeaderKey(name)] = struct{}{}
+		}
+	}
+	redactedHeaders.Store(&headers)
+}
+
+// IsRedactedHeader returns whether the values of the header called name are
+// never logged.
+func IsRedactedHeader(name string) bool {
+	_, ok := (*redactedHeaders.Load())[http.CanonicalHeaderKey(name)]
+	return ok
+}
+
 // StatusLevel takes an HTTP status and returns an appropriate log level.
 func StatusLevel(status int) zapcore.Level {
 	switch {
@@ -48,6 +92,32 @@ func StatusLevel(status int) zapcore.Level {
 	}
 }
 
+// UserAgentProduct returns the product of the first entry of the user agent
+// for logs and events, or "unknown" if there is none. Control characters
+// and invalid UTF-8 are dropped, and long products are cut at a character
+// boundary to at most 32 bytes.
+func UserAgentProduct(userAgent string) string {
+	agents, err := useragent.ParseEntries([]byte(userAgent))
+	if err != nil || len(agents) == 0 {
+		return "unknown"
+	}
+
+	var b strings.Builder
+	for _, r := range agents[0].Product {
+		if r == utf8.RuneError || unicode.IsControl(r) {
+			continue
+		}
+		if b.Len()+utf8.RuneLen(r) > maxUserAgentProductLength {
+			break
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "unknown"
+	}
+	return b.String()
+}
+
 // RequestQueryLogObject encodes a URL query string into a zap logging object.
 type RequestQueryLogObject struct {
 	Query                                   url.Values
@@ -102,6 +172,9 @@ func hideConfidentialQuery(k string, vals []string, disable bool) string {
 }
 
 func hideConfidentialHeader(k string, vals []string, disable bool) string {
+	if IsRedactedHeader(k) {
+		return "[...]"
+	}
 	if _, ok := confidentialHeaders[k]; ok && !disable {
 		return "[...]"
 	}
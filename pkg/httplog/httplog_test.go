@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -150,3 +151,57 @@ func TestConfidentalJSONFields(t *testing.T) {
 		require.Equal(t, "[...]", result[key], i)
 	}
 }
+
+func TestRedactedHeaders(t *testing.T) {
+	defer SetRedactedHeaders(nil)
+
+	headers := http.Header{
+		xhttp.Authorization:    []string{"value"},
+		"Cookie":               []string{"value"},
+		"Set-Cookie":           []string{"value"},
+		xhttp.AmzSecurityToken: []string{"value"},
+		xhttp.AmzCopySource:    []string{"value"},
+		"X-Custom-Auth":        []string{"value"},
+	}
+
+	marshal := func() map[string]string {
+		b, err := json.Marshal(&HeadersLogObject{Headers: headers, InsecureDisableConfidentialSanitization: true})
+		require.NoError(t, err)
+		result := make(map[string]string)
+		require.NoError(t, json.Unmarshal(b, &result))
+		return result
+	}
+
+	// credentials stay redacted even if sanitization is disabled.
+	result := marshal()
+	require.Equal(t, "[...]", result[xhttp.Authorization])
+	require.Equal(t, "[...]", result["Cookie"])
+	require.Equal(t, "[...]", result["Set-Cookie"])
+	require.Equal(t, "[...]", result[xhttp.AmzSecurityToken])
+	require.Equal(t, "value", result[xhttp.AmzCopySource])
+	require.Equal(t, "value", result["X-Custom-Auth"])
+
+	SetRedactedHeaders([]string{"x-custom-auth", " "})
+	require.True(t, IsRedactedHeader("X-Custom-Auth"))
+	require.True(t, IsRedactedHeader("authorization"))
+
+	result = marshal()
+	require.Equal(t, "[...]", result["X-Custom-Auth"])
+	require.Equal(t, "[...]", result[xhttp.Authorization])
+}
+
+func TestUserAgentProduct(t *testing.T) {
+	for _, tc := range []struct {
+		userAgent string
+		expected  string
+	}{
+		{userAgent: "", expected: "unknown"},
+		{userAgent: "(comment)", expected: "unknown"},
+		{userAgent: "uplink/v1.2.3 (go1.23; linux)", expected: "uplink"},
+		{userAgent: "aws-sdk-go/1.44.0 (go1.20; linux; amd64)", expected: "aws-sdk-go"},
+		{userAgent: "rclone", expected: "rclone"},
+		{userAgent: strings.Repeat("a", 40) + "/1.0", expected: strings.Repeat("a", 32)},
+	} {
+		require.Equal(t, tc.expected, UserAgentProduct(tc.userAgent), tc.userAgent)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package httpserver
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+)
+
+// certKeyPair is a pair of paths to a certificate file and its key file.
+type certKeyPair struct {
+	certFile string
+	keyFile  string
+}
+
+// reloadableCertificate is a certificate that's reloaded from its files when
+// they change.
+type reloadableCertificate struct {
+	files certKeyPair
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	certMod   time.Time
+	keyMod    time.Time
+	checkedAt time.Time
+}
+
+// certReloader selects a certificate for handshakes like crypto/tls does
+// with tls.Config.Certificates, except that the certificates are reloaded
+// from disk when their files change, so that rotated certificates are served
+// to new connections without a restart.
+//
+// Files are checked at most once per interval, during a handshake, so
+// there's no background goroutine to manage.
+type certReloader struct {
+	log      *zap.Logger
+	interval time.Duration
+	certs    []*reloadableCertificate
+}
+
+func newCertReloader(log *zap.Logger, interval time.Duration, pairs []certKeyPair) (*certReloader, error) {
+	reloader := &certReloader{
+		log:      log,
+		interval: interval,
+	}
+	for _, files := range pairs {
+		c, err := loadReloadableCertificate(files)
+		if err != nil {
+			return nil, errs.New("unable to load server keypair: %v", err)
+		}
+		reloader.certs = append(reloader.certs, c)
+	}
+	return reloader, nil
+}
+
+// loadReloadableCertificate loads the certificate from files.
+func loadReloadableCertificate(files certKeyPair) (*reloadableCertificate, error) {
+	certMod, keyMod, err := modTimes(files)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(files.certFile, files.keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &reloadableCertificate{
+		files:     files,
+		cert:      &cert,
+		certMod:   certMod,
+		keyMod:    keyMod,
+		checkedAt: time.Now(),
+	}, nil
+}
+
+// certificates returns the current certificates.
+func (r *certReloader) certificates() []tls.Certificate {
+	certs := make([]tls.Certificate, 0, len(r.certs))
+	for _, c := range r.certs {
+		certs = append(certs, *r.current(c))
+	}
+	return certs
+}
+
+// getCertificate returns the first current certificate supported by the
+// client, or the first one if none is.
+func (r *certReloader) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if len(r.certs) == 0 {
+		return nil, nil
+	}
+	for _, c := range r.certs {
+		if cert := r.current(c); hello.SupportsCertificate(cert) == nil {
+			return cert, nil
+		}
+	}
+	return r.current(r.certs[0]), nil
+}
+
+// current returns the certificate of c, reloading it first if its files
+// changed since they were last checked. If reloading fails, the previous
+// certificate is kept, and reloading is retried after the next interval. If
+// the interval isn't positive, certificates are never reloaded.
+func (r *certReloader) current(c *reloadableCertificate) *tls.Certificate {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if r.interval <= 0 || now.Sub(c.checkedAt) < r.interval {
+		return c.cert
+	}
+	c.checkedAt = now
+
+	log := r.log.With(zap.String("cert-file", c.files.certFile), zap.String("key-file", c.files.keyFile))
+
+	certMod, keyMod, err := modTimes(c.files)
+	if err != nil {
+		log.Error("unable to reload certificate", zap.Error(err))
+		return c.cert
+	}
+	if certMod.Equal(c.certMod) && keyMod.Equal(c.keyMod) {
+		return c.cert
+	}
+
+	// the files might not have been rotated together yet, e.g. the
+	// certificate was written, but not its key.
+	cert, err := tls.LoadX509KeyPair(c.files.certFile, c.files.keyFile)
+	if err != nil {
+		log.Error("unable to reload certificate", zap.Error(err))
+		return c.cert
+	}
+
+	c.cert, c.certMod, c.keyMod = &cert, certMod, keyMod
+
+	log.Info("reloaded certificate", zap.Strings("names", cert.Leaf.DNSNames), zap.Time("not-after", cert.Leaf.NotAfter))
+
+	return c.cert
+}
+
+func modTimes(files certKeyPair) (certMod, keyMod time.Time, err error) {
+	certInfo, err := os.Stat(files.certFile)
+	if err != nil {
+		return certMod, keyMod, err
+	}
+	keyInfo, err := os.Stat(files.keyFile)
+	if err != nil {
+		return certMod, keyMod, err
+	}
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
@@ -0,0 +1,137 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package httpserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCertReload(t *testing.T) {
+	dir := t.TempDir()
+
+	certPath, keyPath := writeTestCert(t, dir, "server", "first.test")
+
+	tlsConfig, err := configureTLS(zap.NewNop(), nil, Config{
+		TLSConfig: &TLSConfig{
+			CertFile:           certPath,
+			KeyFile:            keyPath,
+			CertReloadInterval: time.Nanosecond,
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, tlsConfig.GetCertificate)
+	require.Len(t, tlsConfig.Certificates, 1)
+
+	requireServed := func(expected string) {
+		t.Helper()
+		cert, err := tlsConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: expected})
+		require.NoError(t, err)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		require.Equal(t, []string{expected}, leaf.DNSNames)
+	}
+
+	// bumps the modification times in case the file system's resolution is
+	// too coarse to tell rewritten files apart.
+	touch := func(offset time.Duration) {
+		mod := time.Now().Add(offset)
+		require.NoError(t, os.Chtimes(certPath, mod, mod))
+		require.NoError(t, os.Chtimes(keyPath, mod, mod))
+	}
+
+	requireServed("first.test")
+
+	writeTestCert(t, dir, "server", "second.test")
+	touch(time.Minute)
+	requireServed("second.test")
+
+	// a broken rotation keeps the previous certificate.
+	require.NoError(t, os.WriteFile(keyPath, []byte("invalid"), 0600))
+	touch(2 * time.Minute)
+	requireServed("second.test")
+
+	require.NoError(t, os.Remove(certPath))
+	requireServed("second.test")
+
+	writeTestCert(t, dir, "server", "third.test")
+	touch(3 * time.Minute)
+	requireServed("third.test")
+}
+
+func TestCertReloadDisabled(t *testing.T) {
+	dir := t.TempDir()
+
+	certPath, keyPath := writeTestCert(t, dir, "server", "first.test")
+
+	tlsConfig, err := configureTLS(zap.NewNop(), nil, Config{
+		TLSConfig: &TLSConfig{
+			CertFile: certPath,
+			KeyFile:  keyPath,
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, tlsConfig.GetCertificate)
+	require.Len(t, tlsConfig.Certificates, 1)
+
+	_, err = configureTLS(zap.NewNop(), nil, Config{
+		TLSConfig: &TLSConfig{
+			CertFile:           certPath,
+			KeyFile:            certPath + ".missing",
+			CertReloadInterval: time.Minute,
+		},
+	})
+	require.Error(t, err)
+}
+
+func TestSNICertReload(t *testing.T) {
+	dir := t.TempDir()
+
+	defaultCert, defaultKey := writeTestCert(t, dir, "default", "default.test")
+	sniCert, sniKey := writeTestCert(t, dir, "sni", "sni.test")
+
+	newConfig := func(interval time.Duration) *tls.Config {
+		tlsConfig, err := configureTLS(zap.NewNop(), nil, Config{
+			TLSConfig: &TLSConfig{
+				CertFile:           defaultCert,
+				KeyFile:            defaultKey,
+				CertReloadInterval: interval,
+				SNICertificates: []SNICertificate{
+					{Host: "sni.test", CertFile: sniCert, KeyFile: sniKey},
+				},
+			},
+		})
+		require.NoError(t, err)
+		return tlsConfig
+	}
+
+	reloading, notReloading := newConfig(time.Nanosecond), newConfig(0)
+
+	requireServed := func(tlsConfig *tls.Config, expected string) {
+		t.Helper()
+		cert, err := tlsConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: "sni.test"})
+		require.NoError(t, err)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		require.Equal(t, []string{expected}, leaf.DNSNames)
+	}
+
+	requireServed(reloading, "sni.test")
+	requireServed(notReloading, "sni.test")
+
+	// the rotated certificate is told apart by its name.
+	writeTestCert(t, dir, "sni", "rotated.sni.test")
+	mod := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(sniCert, mod, mod))
+	require.NoError(t, os.Chtimes(sniKey, mod, mod))
+
+	requireServed(reloading, "rotated.sni.test")
+	requireServed(notReloading, "sni.test")
+}
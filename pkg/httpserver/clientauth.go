@@ -0,0 +1,109 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package httpserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"net/http"
+	"os"
+
+	"github.com/zeebo/errs"
+)
+
+// Client certificate authentication modes (see TLSConfig.ClientAuth).
+const (
+	// ClientAuthVerifyIfGiven verifies client certificates if clients send
+	// them, but doesn't require them.
+	ClientAuthVerifyIfGiven = "verify-if-given"
+	// ClientAuthRequire requires clients to send a verified certificate.
+	ClientAuthRequire = "require"
+)
+
+type clientIdentityKey struct{}
+
+// ClientIdentity is the identity of a client authenticated with a
+// certificate signed by TLSConfig.ClientCAFile.
+type ClientIdentity struct {
+	// Subject is the distinguished name of the certificate's subject.
+	Subject string
+	// SerialNumber is the serial number of the certificate.
+	SerialNumber string
+	// Fingerprint is the hex-encoded SHA-256 hash of the certificate.
+	Fingerprint string
+}
+
+// String returns the subject of the identity.
+func (identity ClientIdentity) String() string {
+	return identity.Subject
+}
+
+// ClientIdentityFromContext returns the identity of the client that sent the
+// request ctx belongs to, if the client authenticated with a certificate.
+func ClientIdentityFromContext(ctx context.Context) (ClientIdentity, bool) {
+	identity, ok := ctx.Value(clientIdentityKey{}).(ClientIdentity)
+	return identity, ok
+}
+
+// parseClientAuth parses a client certificate authentication mode.
+func parseClientAuth(mode string) (tls.ClientAuthType, error) {
+	switch mode {
+	case "":
+		return tls.NoClientCert, nil
+	case ClientAuthVerifyIfGiven:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, errs.New("unsupported client auth mode %q: expected %s or %s", mode, ClientAuthVerifyIfGiven, ClientAuthRequire)
+	}
+}
+
+// configureClientAuth configures tlsConfig to authenticate clients with
+// certificates signed by the CAs in the PEM-encoded caFile.
+func configureClientAuth(tlsConfig *tls.Config, mode, caFile string) error {
+	clientAuth, err := parseClientAuth(mode)
+	if err != nil {
+		return err
+	}
+	if clientAuth == tls.NoClientCert {
+		return nil
+	}
+	if caFile == "" {
+		return errs.New("a client CA file must be provided with client auth mode %s", mode)
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return errs.New("unable to read client CA file: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return errs.New("no certificates found in client CA file %s", caFile)
+	}
+
+	tlsConfig.ClientAuth = clientAuth
+	tlsConfig.ClientCAs = pool
+	return nil
+}
+
+// withClientIdentity adds the identity of clients that authenticated with a
+// verified certificate to the context of their requests.
+func withClientIdentity(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+			cert := r.TLS.VerifiedChains[0][0]
+			fingerprint := sha256.Sum256(cert.Raw)
+			r = r.WithContext(context.WithValue(r.Context(), clientIdentityKey{}, ClientIdentity{
+				Subject:      cert.Subject.String(),
+				SerialNumber: cert.SerialNumber.String(),
+				Fingerprint:  hex.EncodeToString(fingerprint[:]),
+			}))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
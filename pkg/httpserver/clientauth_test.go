@@ -0,0 +1,159 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package httpserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestParseClientAuth(t *testing.T) {
+	for mode, expected := range map[string]tls.ClientAuthType{
+		"":                      tls.NoClientCert,
+		ClientAuthVerifyIfGiven: tls.VerifyClientCertIfGiven,
+		ClientAuthRequire:       tls.RequireAndVerifyClientCert,
+	} {
+		parsed, err := parseClientAuth(mode)
+		require.NoError(t, err)
+		require.Equal(t, expected, parsed, mode)
+	}
+
+	_, err := parseClientAuth("request")
+	require.Error(t, err)
+}
+
+func TestClientAuth(t *testing.T) {
+	dir := t.TempDir()
+
+	serverCert, serverKey := writeTestCert(t, dir, "server", "server.test")
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Client CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, caKey.Public(), caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	caPath := filepath.Join(dir, "client-ca.pem")
+	require.NoError(t, os.WriteFile(caPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0644))
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	clientDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "client", Organization: []string{"Customer"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, clientKey.Public(), caKey)
+	require.NoError(t, err)
+	clientCert := tls.Certificate{Certificate: [][]byte{clientDER}, PrivateKey: clientKey}
+
+	serve := func(mode string) *httptest.Server {
+		tlsConfig, err := configureTLS(zap.NewNop(), nil, Config{
+			TLSConfig: &TLSConfig{
+				CertFile:     serverCert,
+				KeyFile:      serverKey,
+				ClientAuth:   mode,
+				ClientCAFile: caPath,
+			},
+		})
+		require.NoError(t, err)
+
+		server := httptest.NewUnstartedServer(withClientIdentity(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity, ok := ClientIdentityFromContext(r.Context())
+			if !ok {
+				_, _ = w.Write([]byte("anonymous"))
+				return
+			}
+			require.Equal(t, "42", identity.SerialNumber)
+			require.Len(t, identity.Fingerprint, 64)
+			_, _ = w.Write([]byte(identity.String()))
+		})))
+		server.TLS = tlsConfig
+		server.StartTLS()
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	get := func(server *httptest.Server, certs ...tls.Certificate) (string, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+			Certificates:       certs,
+		}}}
+		defer client.CloseIdleConnections()
+
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	required := serve(ClientAuthRequire)
+
+	body, err := get(required, clientCert)
+	require.NoError(t, err)
+	require.Equal(t, "CN=client,O=Customer", body)
+
+	_, err = get(required)
+	require.Error(t, err)
+
+	optional := serve(ClientAuthVerifyIfGiven)
+
+	body, err = get(optional, clientCert)
+	require.NoError(t, err)
+	require.Equal(t, "CN=client,O=Customer", body)
+
+	body, err = get(optional)
+	require.NoError(t, err)
+	require.Equal(t, "anonymous", body)
+
+	_, err = configureTLS(zap.NewNop(), nil, Config{
+		TLSConfig: &TLSConfig{
+			CertFile:   serverCert,
+			KeyFile:    serverKey,
+			ClientAuth: ClientAuthRequire,
+		},
+	})
+	require.Error(t, err)
+
+	_, err = configureTLS(zap.NewNop(), nil, Config{
+		TLSConfig: &TLSConfig{
+			CertFile:     serverCert,
+			KeyFile:      serverKey,
+			ClientAuth:   ClientAuthRequire,
+			ClientCAFile: serverKey,
+		},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,46 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package httpserver
+
+import (
+	"os"
+
+	"github.com/caddyserver/certmagic"
+	"github.com/libdns/cloudflare"
+	"github.com/libdns/googleclouddns"
+	"github.com/zeebo/errs"
+)
+
+// DNS providers the DNS challenge can be performed with.
+const (
+	// DNSProviderGCloud is Google Cloud DNS. It uses the CertMagic service
+	// account key and the zone's project.
+	DNSProviderGCloud = "gcloud"
+	// DNSProviderCloudflare is Cloudflare DNS. It reads the API token from the
+	// CLOUDFLARE_API_TOKEN environment variable.
+	DNSProviderCloudflare = "cloudflare"
+)
+
+// cloudflareAPITokenEnv is the environment variable the Cloudflare API token
+// is read from. The token needs the Zone:Read and DNS:Edit permissions for
+// the zones of the certificates' domains.
+const cloudflareAPITokenEnv = "CLOUDFLARE_API_TOKEN"
+
+func newDNSProvider(config TLSConfig) (certmagic.ACMEDNSProvider, error) {
+	switch config.CertMagicDNSChallengeProvider {
+	case DNSProviderGCloud:
+		return &googleclouddns.Provider{
+			Project:            config.CertMagicDNSChallengeWithGCloudDNSProject,
+			ServiceAccountJSON: config.CertMagicKeyFile,
+		}, nil
+	case DNSProviderCloudflare:
+		token := os.Getenv(cloudflareAPITokenEnv)
+		if token == "" {
+			return nil, errs.New("%s must be set", cloudflareAPITokenEnv)
+		}
+		return &cloudflare.Provider{APIToken: token}, nil
+	default:
+		return nil, errs.New("unsupported DNS challenge provider %q", config.CertMagicDNSChallengeProvider)
+	}
+}
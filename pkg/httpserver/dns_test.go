@@ -0,0 +1,34 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package httpserver
+
+import (
+	"testing"
+
+	"github.com/libdns/cloudflare"
+	"github.com/libdns/googleclouddns"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDNSProvider(t *testing.T) {
+	provider, err := newDNSProvider(TLSConfig{
+		CertMagicDNSChallengeProvider:             DNSProviderGCloud,
+		CertMagicDNSChallengeWithGCloudDNSProject: "project",
+		CertMagicKeyFile:                          "key.json",
+	})
+	require.NoError(t, err)
+	require.Equal(t, &googleclouddns.Provider{Project: "project", ServiceAccountJSON: "key.json"}, provider)
+
+	t.Setenv(cloudflareAPITokenEnv, "")
+	_, err = newDNSProvider(TLSConfig{CertMagicDNSChallengeProvider: DNSProviderCloudflare})
+	require.Error(t, err)
+
+	t.Setenv(cloudflareAPITokenEnv, "token")
+	provider, err = newDNSProvider(TLSConfig{CertMagicDNSChallengeProvider: DNSProviderCloudflare})
+	require.NoError(t, err)
+	require.Equal(t, &cloudflare.Provider{APIToken: "token"}, provider)
+
+	_, err = newDNSProvider(TLSConfig{CertMagicDNSChallengeProvider: "unknown"})
+	require.Error(t, err)
+}
@@ -0,0 +1,242 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ocsp"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// ocspRetryInterval is how long to wait before fetching an OCSP response
+	// again after fetching it failed.
+	ocspRetryInterval = 5 * time.Minute
+	// ocspFetchTimeout is the maximum time to spend fetching an OCSP response.
+	ocspFetchTimeout = 10 * time.Second
+	// ocspMaxResponseSize is the maximum size of an OCSP response.
+	ocspMaxResponseSize = 1 << 20
+	// ocspUnusedExpiration is how long responses of certificates that aren't
+	// served anymore, e.g. because they were rotated, are kept.
+	ocspUnusedExpiration = 24 * time.Hour
+)
+
+// ocspStaple is a cached OCSP response for a certificate.
+type ocspStaple struct {
+	raw        []byte
+	nextUpdate time.Time
+	// refreshAt is when the response should be fetched again, which is
+	// halfway through its validity period, or ocspRetryInterval after a
+	// failure.
+	refreshAt time.Time
+	// lastUsed is when the certificate was last served. It's protected by
+	// the mutex of the ocspStapler.
+	lastUsed time.Time
+}
+
+// valid returns whether the staple can be served at now.
+func (s *ocspStaple) valid(now time.Time) bool {
+	return s != nil && s.raw != nil && (s.nextUpdate.IsZero() || now.Before(s.nextUpdate))
+}
+
+// ocspStapler staples OCSP responses to certificates loaded from files, which
+// crypto/tls doesn't do on its own. The responses are fetched in the
+// background on the first handshake that needs them and refreshed before they
+// expire; handshakes meanwhile get the previous response, if it's still
+// valid, or none.
+type ocspStapler struct {
+	log    *zap.Logger
+	client *http.Client
+
+	group   singleflight.Group
+	mu      sync.Mutex
+	staples map[string]*ocspStaple
+}
+
+func newOCSPStapler(log *zap.Logger) *ocspStapler {
+	return &ocspStapler{
+		log:     log,
+		client:  http.DefaultClient,
+		staples: make(map[string]*ocspStaple),
+	}
+}
+
+// getCertificate returns the certificate returned by next, or the first of
+// certs supported by the client if next is nil or returns nil, with its OCSP
+// response stapled.
+func (s *ocspStapler) getCertificate(next func(*tls.ClientHelloInfo) (*tls.Certificate, error), certs []tls.Certificate) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		var cert *tls.Certificate
+		if next != nil {
+			var err error
+			if cert, err = next(hello); err != nil {
+				return nil, err
+			}
+		}
+		if cert == nil {
+			cert = defaultCertificate(hello, certs)
+		}
+		if cert == nil {
+			return nil, nil
+		}
+		return s.staple(cert), nil
+	}
+}
+
+// defaultCertificate selects a certificate from certs the same way crypto/tls
+// does when there's no GetCertificate callback.
+func defaultCertificate(hello *tls.ClientHelloInfo, certs []tls.Certificate) *tls.Certificate {
+	if len(certs) == 0 {
+		return nil
+	}
+	for i := range certs {
+		if hello.SupportsCertificate(&certs[i]) == nil {
+			return &certs[i]
+		}
+	}
+	return &certs[0]
+}
+
+// staple returns a copy of cert with its OCSP response stapled. It returns
+// cert unchanged if it already has one, e.g. from CertMagic, or if there's no
+// valid response.
+func (s *ocspStapler) staple(cert *tls.Certificate) *tls.Certificate {
+	// the issuer is needed to request and verify the response.
+	if len(cert.OCSPStaple) > 0 || len(cert.Certificate) < 2 {
+		return cert
+	}
+
+	key := string(cert.Certificate[0])
+	now := time.Now()
+
+	s.mu.Lock()
+	staple := s.staples[key]
+	if staple != nil {
+		staple.lastUsed = now
+	}
+	s.mu.Unlock()
+
+	if staple == nil || now.After(staple.refreshAt) {
+		// fetching can take up to ocspFetchTimeout, which handshakes must not
+		// wait for.
+		s.group.DoChan(key, func() (any, error) {
+			return s.refresh(cert, staple), nil
+		})
+	}
+
+	if !staple.valid(now) {
+		return cert
+	}
+
+	stapled := *cert
+	stapled.OCSPStaple = staple.raw
+	return &stapled
+}
+
+// refresh fetches the OCSP response for cert and caches it. If fetching it
+// fails, the previous staple is kept until it expires. Staples of certificates
+// that haven't been served for ocspUnusedExpiration are removed.
+func (s *ocspStapler) refresh(cert *tls.Certificate, previous *ocspStaple) *ocspStaple {
+	staple, err := s.fetch(cert)
+	if err != nil {
+		s.log.Warn("unable to fetch OCSP response", zap.Error(err))
+
+		staple = &ocspStaple{refreshAt: time.Now().Add(ocspRetryInterval)}
+		if previous != nil {
+			staple.raw, staple.nextUpdate = previous.raw, previous.nextUpdate
+		}
+	}
+
+	now := time.Now()
+	staple.lastUsed = now
+
+	s.mu.Lock()
+	for key, other := range s.staples {
+		if now.Sub(other.lastUsed) > ocspUnusedExpiration {
+			delete(s.staples, key)
+		}
+	}
+	s.staples[string(cert.Certificate[0])] = staple
+	s.mu.Unlock()
+
+	return staple
+}
+
+func (s *ocspStapler) fetch(cert *tls.Certificate) (*ocspStaple, error) {
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	issuer, err := x509.ParseCertificate(cert.Certificate[1])
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	if len(leaf.OCSPServer) == 0 {
+		return nil, errs.New("certificate for %v has no OCSP server", leaf.DNSNames)
+	}
+
+	req, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ocspFetchTimeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, leaf.OCSPServer[0], bytes.NewReader(req))
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	httpReq.Header.Set("Content-Type", "application/ocsp-request")
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errs.New("OCSP server %s responded with status %d", leaf.OCSPServer[0], resp.StatusCode)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, ocspMaxResponseSize))
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+
+	parsed, err := ocsp.ParseResponseForCert(raw, leaf, issuer)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	if parsed.Status != ocsp.Good {
+		return nil, errs.New("OCSP status of certificate for %v is not good: %d", leaf.DNSNames, parsed.Status)
+	}
+
+	now := time.Now()
+	staple := &ocspStaple{
+		raw:        raw,
+		nextUpdate: parsed.NextUpdate,
+		refreshAt:  now.Add(time.Hour),
+	}
+	if !parsed.NextUpdate.IsZero() {
+		staple.refreshAt = parsed.ThisUpdate.Add(parsed.NextUpdate.Sub(parsed.ThisUpdate) / 2)
+		if staple.refreshAt.Before(now) {
+			staple.refreshAt = now.Add(ocspRetryInterval)
+		}
+	}
+
+	s.log.Debug("fetched OCSP response", zap.Strings("names", leaf.DNSNames), zap.Time("next-update", parsed.NextUpdate))
+
+	return staple, nil
+}
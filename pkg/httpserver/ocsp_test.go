@@ -0,0 +1,183 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package httpserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestParseTLSVersion(t *testing.T) {
+	for version, expected := range map[string]uint16{
+		"":    tls.VersionTLS12,
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	} {
+		parsed, err := ParseTLSVersion(version)
+		require.NoError(t, err)
+		require.Equal(t, expected, parsed, version)
+	}
+
+	for _, version := range []string{"1.0", "1.1", "tls1.3"} {
+		_, err := ParseTLSVersion(version)
+		require.Error(t, err, version)
+	}
+}
+
+func TestOCSPStapling(t *testing.T) {
+	dir := t.TempDir()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, caKey.Public(), caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	var requests atomic.Int64
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		req, err := ocsp.ParseRequest(body)
+		require.NoError(t, err)
+
+		resp, err := ocsp.CreateResponse(caCert, caCert, ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+		}, caKey)
+		require.NoError(t, err)
+		_, _ = w.Write(resp)
+	}))
+	defer responder.Close()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	leafDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"ocsp.test"},
+		OCSPServer:   []string{responder.URL},
+	}, caCert, key.Public(), caKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, "ocsp.crt")
+	keyPath := filepath.Join(dir, "ocsp.key")
+	chain := append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER}), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})...)
+	require.NoError(t, os.WriteFile(certPath, chain, 0644))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	tlsConfig, err := configureTLS(zap.NewNop(), nil, Config{
+		TLSConfig: &TLSConfig{
+			CertFile:      certPath,
+			KeyFile:       keyPath,
+			MinTLSVersion: "1.3",
+		},
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, tls.VersionTLS13, tlsConfig.MinVersion)
+	require.Nil(t, tlsConfig.GetCertificate)
+
+	tlsConfig, err = configureTLS(zap.NewNop(), nil, Config{
+		TLSConfig: &TLSConfig{
+			CertFile:           certPath,
+			KeyFile:            keyPath,
+			EnableOCSPStapling: true,
+		},
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, tls.VersionTLS12, tlsConfig.MinVersion)
+	require.NotNil(t, tlsConfig.GetCertificate)
+
+	// the response is fetched in the background, so the first handshake
+	// doesn't wait for it.
+	cert, err := tlsConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: "ocsp.test"})
+	require.NoError(t, err)
+	require.NotNil(t, cert)
+
+	require.Eventually(t, func() bool {
+		cert, err := tlsConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: "ocsp.test"})
+		return err == nil && len(cert.OCSPStaple) > 0
+	}, 10*time.Second, 10*time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		cert, err := tlsConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: "ocsp.test"})
+		require.NoError(t, err)
+		require.NotEmpty(t, cert.OCSPStaple)
+
+		resp, err := ocsp.ParseResponseForCert(cert.OCSPStaple, nil, caCert)
+		require.NoError(t, err)
+		require.Equal(t, ocsp.Good, resp.Status)
+	}
+	// the response is cached until it's halfway through its validity period.
+	require.EqualValues(t, 1, requests.Load())
+	require.Empty(t, tlsConfig.Certificates[0].OCSPStaple)
+
+	// certificates without an OCSP server are served without a staple.
+	selfSignedCert, selfSignedKey := writeTestCert(t, dir, "self-signed", "self-signed.test")
+	tlsConfig, err = configureTLS(zap.NewNop(), nil, Config{
+		TLSConfig: &TLSConfig{
+			CertFile:           selfSignedCert,
+			KeyFile:            selfSignedKey,
+			EnableOCSPStapling: true,
+		},
+	})
+	require.NoError(t, err)
+	cert, err = tlsConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: "self-signed.test"})
+	require.NoError(t, err)
+	require.Empty(t, cert.OCSPStaple)
+
+	_, err = configureTLS(zap.NewNop(), nil, Config{
+		TLSConfig: &TLSConfig{
+			CertFile:      certPath,
+			KeyFile:       keyPath,
+			MinTLSVersion: "1.1",
+		},
+	})
+	require.Error(t, err)
+}
+
+func TestOCSPStaplerPrunesUnusedStaples(t *testing.T) {
+	s := newOCSPStapler(zap.NewNop())
+	s.staples["rotated"] = &ocspStaple{lastUsed: time.Now().Add(-ocspUnusedExpiration - time.Minute)}
+	s.staples["recent"] = &ocspStaple{lastUsed: time.Now()}
+
+	// fetching fails for the invalid certificate, which is cached anyway.
+	s.refresh(&tls.Certificate{Certificate: [][]byte{[]byte("leaf"), []byte("issuer")}}, nil)
+
+	require.NotContains(t, s.staples, "rotated")
+	require.Contains(t, s.staples, "recent")
+	require.Contains(t, s.staples, "leaf")
+}
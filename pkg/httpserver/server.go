@@ -16,7 +16,6 @@ import (
 	"time"
 
 	"github.com/caddyserver/certmagic"
-	"github.com/libdns/googleclouddns"
 	"github.com/mholt/acmez"
 	"github.com/pires/go-proxyproto"
 	"github.com/spacemonkeygo/monkit/v3"
@@ -73,6 +72,15 @@ type Config struct {
 	// next request when keep-alives are enabled.
 	IdleTimeout time.Duration
 
+	// ReadHeaderTimeout is the amount of time allowed to read request
+	// headers. Zero means no timeout.
+	ReadHeaderTimeout time.Duration
+
+	// MaxConcurrentStreams is the maximum number of concurrent HTTP/2 streams,
+	// i.e. requests, per connection. Zero uses the default of the http2
+	// package.
+	MaxConcurrentStreams uint32
+
 	// StartupCheckConfig configures a startup check that must pass in order for
 	// servers to start listening.
 	StartupCheckConfig StartupCheckConfig
@@ -102,10 +110,11 @@ type TLSConfig struct {
 	// CertMagicKeyFile is a path to a file containing the CertMagic service account key.
 	CertMagicKeyFile string
 
-	// CertMagicDNSChallengeWithGCloudDNS is whether to disable HTTP and TLS
-	// ALPN challenges and perform the DNS challenge with Google Cloud DNS (no
-	// other providers are supported at the moment).
-	CertMagicDNSChallengeWithGCloudDNS bool
+	// CertMagicDNSChallengeProvider is the DNS provider to perform the DNS
+	// challenge with, instead of the TLS ALPN challenge (see DNSProvider*
+	// constants). The DNS challenge allows obtaining wildcard certificates
+	// and doesn't require the server to be publicly reachable.
+	CertMagicDNSChallengeProvider string
 
 	// CertMagicDNSChallengeWithGCloudDNSProject is the project where the Google
 	// Cloud DNS zone exists.
@@ -162,6 +171,38 @@ type TLSConfig struct {
 	// KeyFile is a path to a file containing a corresponding key for CertFile.
 	KeyFile string
 
+	// CertReloadInterval is how often the files of the certificates from
+	// CertDir, CertFile/KeyFile and SNICertificates are checked for changes,
+	// during handshakes, so that rotated certificates are served without a
+	// restart. Certificates added to or removed from CertDir still require a
+	// restart. If zero, certificates aren't reloaded.
+	CertReloadInterval time.Duration
+
+	// MinTLSVersion is the minimum TLS version accepted, either 1.2 or 1.3. If
+	// empty, it defaults to 1.2.
+	MinTLSVersion string
+
+	// EnableOCSPStapling staples OCSP responses to certificates loaded from
+	// CertDir, CertFile/KeyFile and SNICertificates. CertMagic staples OCSP
+	// responses to the certificates it manages regardless.
+	EnableOCSPStapling bool
+
+	// ClientAuth is whether clients authenticate with certificates signed by
+	// ClientCAFile: ClientAuthVerifyIfGiven or ClientAuthRequire. If empty,
+	// clients aren't asked for certificates. It only applies to the TLS
+	// listeners. The identities of authenticated clients are available to
+	// handlers through ClientIdentityFromContext.
+	ClientAuth string
+
+	// ClientCAFile is a path to a file containing the PEM-encoded CA
+	// certificates client certificates are verified with.
+	ClientCAFile string
+
+	// SNICertificates are certificates served for specific host names. They
+	// take precedence over CertMagic and the default certificate from CertDir
+	// or CertFile/KeyFile, which is used for any host not listed here.
+	SNICertificates []SNICertificate
+
 	// Ctx context for the oauth2 package which gcslock and gcsops use.
 	// oauth2 stores the context passed into its constructors.
 	Ctx context.Context
@@ -239,6 +280,10 @@ func New(log *zap.Logger, handler http.Handler, decisionFunc CertMagicOnDemandDe
 		}
 	}
 
+	if tlsConfig != nil && tlsConfig.ClientAuth != tls.NoClientCert {
+		handler = withClientIdentity(handler)
+	}
+
 	// logging
 	if config.TrafficLogging {
 		handler = logResponses(log, logRequests(log, handler))
@@ -250,25 +295,42 @@ func New(log *zap.Logger, handler http.Handler, decisionFunc CertMagicOnDemandDe
 	}
 
 	server := &http.Server{
-		IdleTimeout: config.IdleTimeout,
-		Handler:     handler,
-		ErrorLog:    zap.NewStdLog(log),
+		IdleTimeout:       config.IdleTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		Handler:           handler,
+		ErrorLog:          zap.NewStdLog(log),
 	}
 
 	serverTLS := &http.Server{
-		IdleTimeout:  config.IdleTimeout,
-		Handler:      handler,
-		TLSConfig:    tlsConfig,
-		ErrorLog:     zap.NewStdLog(log),
-		TLSNextProto: nextProto,
+		IdleTimeout:       config.IdleTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ErrorLog:          zap.NewStdLog(log),
+		TLSNextProto:      nextProto,
 	}
 
 	proxyServerTLS := &http.Server{
-		IdleTimeout:  config.IdleTimeout,
-		Handler:      handler,
-		TLSConfig:    tlsConfig.Clone(),
-		ErrorLog:     zap.NewStdLog(log),
-		TLSNextProto: nextProto,
+		IdleTimeout:       config.IdleTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		Handler:           handler,
+		TLSConfig:         tlsConfig.Clone(),
+		ErrorLog:          zap.NewStdLog(log),
+		TLSNextProto:      nextProto,
+	}
+
+	// HTTP/2 is only served over TLS, where it's enabled by default, so it's
+	// only configured explicitly if it needs tuning.
+	if tlsConfig != nil && !config.DisableHTTP2 && config.MaxConcurrentStreams > 0 {
+		for _, s := range []*http.Server{serverTLS, proxyServerTLS} {
+			err = http2.ConfigureServer(s, &http2.Server{
+				MaxConcurrentStreams: config.MaxConcurrentStreams,
+				IdleTimeout:          config.IdleTimeout,
+			})
+			if err != nil {
+				return nil, errs.New("unable to configure HTTP/2: %v", err)
+			}
+		}
 	}
 
 	if config.ShutdownTimeout == 0 {
@@ -407,32 +469,85 @@ func (c Config) BaseTLSConfig() *tls.Config {
 	}
 }
 
+// ParseTLSVersion parses a TLS version accepted as TLSConfig.MinTLSVersion.
+func ParseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, errs.New("unsupported minimum TLS version %q: expected 1.2 or 1.3", version)
+	}
+}
+
 func configureTLS(log *zap.Logger, decisionFunc CertMagicOnDemandDecisionFunc, config Config) (*tls.Config, error) {
 	if config.TLSConfig == nil {
 		return nil, nil
 	}
 
+	minVersion, err := ParseTLSVersion(config.TLSConfig.MinTLSVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := configureCertificates(log, decisionFunc, config)
+	if err != nil || tlsConfig == nil {
+		return tlsConfig, err
+	}
+
+	tlsConfig.MinVersion = minVersion
+
+	if err := configureClientAuth(tlsConfig, config.TLSConfig.ClientAuth, config.TLSConfig.ClientCAFile); err != nil {
+		return nil, err
+	}
+
+	if config.TLSConfig.EnableOCSPStapling {
+		tlsConfig.GetCertificate = newOCSPStapler(log).getCertificate(tlsConfig.GetCertificate, tlsConfig.Certificates)
+	}
+
+	return tlsConfig, nil
+}
+
+func configureCertificates(log *zap.Logger, decisionFunc CertMagicOnDemandDecisionFunc, config Config) (*tls.Config, error) {
+	sniCerts, err := loadSNICertificates(log, config.TLSConfig.SNICertificates, config.TLSConfig.CertReloadInterval)
+	if err != nil {
+		return nil, err
+	}
+
 	if config.TLSConfig.CertMagic {
 		if config.TLSConfig.CertMagicEmail == "" {
 			return nil, errs.New("cert-magic.email must be provided when cert-magic is enabled")
 		}
-		return configureCertMagic(log, decisionFunc, config)
+		tlsConfig, err := configureCertMagic(log, decisionFunc, config)
+		if err != nil {
+			return nil, err
+		}
+		if !sniCerts.empty() {
+			tlsConfig.GetCertificate = sniCerts.getCertificate(tlsConfig.GetCertificate)
+		}
+		return tlsConfig, nil
 	}
 
 	tlsConfig := config.BaseTLSConfig()
+	if !sniCerts.empty() {
+		tlsConfig.GetCertificate = sniCerts.getCertificate(nil)
+	}
 
 	if config.TLSConfig.CertDir != "" {
-		certs, err := loadCertsFromDir(config.TLSConfig.CertDir)
+		pairs, err := certKeyPairsInDir(config.TLSConfig.CertDir)
 		if err != nil {
 			return nil, err
 		}
-		tlsConfig.Certificates = certs
-		return tlsConfig, nil
+		return loadCertificates(log, tlsConfig, sniCerts, pairs, config.TLSConfig.CertReloadInterval)
 	}
 
 	switch {
 	case config.TLSConfig.CertFile != "" && config.TLSConfig.KeyFile != "":
 	case config.TLSConfig.CertFile == "" && config.TLSConfig.KeyFile == "":
+		if !sniCerts.empty() {
+			return nil, errs.New("a default cert file and key file must be provided with SNI certificates")
+		}
 		return nil, nil
 	case config.TLSConfig.CertFile != "" && config.TLSConfig.KeyFile == "":
 		return nil, errs.New("key file must be provided with cert file")
@@ -440,36 +555,55 @@ func configureTLS(log *zap.Logger, decisionFunc CertMagicOnDemandDecisionFunc, c
 		return nil, errs.New("cert file must be provided with key file")
 	}
 
-	cert, err := tls.LoadX509KeyPair(config.TLSConfig.CertFile, config.TLSConfig.KeyFile)
-	if err != nil {
-		return nil, errs.New("unable to load server keypair: %v", err)
+	return loadCertificates(log, tlsConfig, sniCerts, []certKeyPair{{
+		certFile: config.TLSConfig.CertFile,
+		keyFile:  config.TLSConfig.KeyFile,
+	}}, config.TLSConfig.CertReloadInterval)
+}
+
+// loadCertificates loads the default certificates of tlsConfig from pairs.
+// If reloadInterval is positive, they're reloaded when their files change.
+func loadCertificates(log *zap.Logger, tlsConfig *tls.Config, sniCerts *sniCertificates, pairs []certKeyPair, reloadInterval time.Duration) (*tls.Config, error) {
+	if reloadInterval > 0 {
+		reloader, err := newCertReloader(log, reloadInterval, pairs)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = reloader.certificates()
+		if !sniCerts.empty() {
+			tlsConfig.GetCertificate = sniCerts.getCertificate(reloader.getCertificate)
+		} else {
+			tlsConfig.GetCertificate = reloader.getCertificate
+		}
+		return tlsConfig, nil
 	}
 
-	tlsConfig.Certificates = []tls.Certificate{cert}
+	for _, files := range pairs {
+		cert, err := tls.LoadX509KeyPair(files.certFile, files.keyFile)
+		if err != nil {
+			return nil, errs.New("unable to load server keypair: %v", err)
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	}
 	return tlsConfig, nil
 }
 
-func loadCertsFromDir(configDir string) ([]tls.Certificate, error) {
+func certKeyPairsInDir(configDir string) ([]certKeyPair, error) {
 	certFiles, err := filepath.Glob(filepath.Join(configDir, "*.crt"))
 	if err != nil {
 		return nil, errs.New("Error reading certificate directory '%s'", certFiles)
 	}
-	var certificates []tls.Certificate
+	var pairs []certKeyPair
 	for _, crt := range certFiles {
 		key := crt[0:len(crt)-4] + ".key"
 		_, err := os.Stat(key)
 		if err != nil {
 			return nil, errs.New("unable to locate key for cert %s (expecting %s): %v", crt, key, err)
 		}
-
-		cert, err := tls.LoadX509KeyPair(crt, key)
-		if err != nil {
-			return nil, errs.New("unable to load server keypair: %v", err)
-		}
-		certificates = append(certificates, cert)
+		pairs = append(pairs, certKeyPair{certFile: crt, keyFile: key})
 	}
 
-	return certificates, nil
+	return pairs, nil
 }
 
 func configureCertMagic(log *zap.Logger, decisionFunc CertMagicOnDemandDecisionFunc, config Config) (*tls.Config, error) {
@@ -546,14 +680,15 @@ func configureCertMagic(log *zap.Logger, decisionFunc CertMagicOnDemandDecisionF
 	tlsConfig := config.BaseTLSConfig()
 	tlsConfig.GetCertificate = magic.GetCertificate
 
-	if config.TLSConfig.CertMagicDNSChallengeWithGCloudDNS {
+	if config.TLSConfig.CertMagicDNSChallengeProvider != "" {
+		provider, err := newDNSProvider(*config.TLSConfig)
+		if err != nil {
+			return nil, err
+		}
 		// Enabling the DNS challenge disables the other challenges for that
 		// certmagic.ACMEIssuer instance.
 		s := &certmagic.DNS01Solver{
-			DNSProvider: &googleclouddns.Provider{
-				Project:            config.TLSConfig.CertMagicDNSChallengeWithGCloudDNSProject,
-				ServiceAccountJSON: config.TLSConfig.CertMagicKeyFile,
-			},
+			DNSProvider:    provider,
 			OverrideDomain: config.TLSConfig.CertMagicDNSChallengeOverrideDomain,
 		}
 		googleCA.DNS01Solver, letsEncryptCA.DNS01Solver = s, s
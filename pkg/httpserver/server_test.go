@@ -230,6 +230,75 @@ func TestBaseTLSConfig(t *testing.T) {
 	require.NotContains(t, serverCfg.BaseTLSConfig().NextProtos, http2.NextProtoTLS)
 }
 
+func TestHTTP2Tuning(t *testing.T) {
+	ctx := testcontext.NewWithTimeout(t, time.Minute)
+	defer ctx.Cleanup()
+
+	tempDir := t.TempDir()
+
+	keyPath := filepath.Join(tempDir, "privkey.pem")
+	require.NoError(t, os.WriteFile(keyPath, []byte(testKey), 0644))
+
+	certPath := filepath.Join(tempDir, "public.pem")
+	require.NoError(t, os.WriteFile(certPath, pkcrypto.CertToPEM(testCert), 0644))
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, r.Proto)
+	})
+
+	server, err := httpserver.New(zaptest.NewLogger(t), handler, nil, httpserver.Config{
+		Name:       "test",
+		Address:    "127.0.0.1:0",
+		AddressTLS: "127.0.0.1:0",
+		TLSConfig: &httpserver.TLSConfig{
+			CertFile:  certPath,
+			KeyFile:   keyPath,
+			ConfigDir: tempDir,
+		},
+		ReadHeaderTimeout:    time.Second,
+		MaxConcurrentStreams: 10,
+	})
+	require.NoError(t, err)
+
+	defer ctx.Check(server.Shutdown)
+
+	ctx.Go(func() error {
+		return server.Run(ctx)
+	})
+
+	client := &http.Client{
+		Transport: &http2.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:    certPoolFromCert(testCert),
+				ServerName: "127.0.0.1",
+			},
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+server.AddrTLS(), nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "HTTP/2.0", string(body))
+
+	// a client that doesn't send its headers in time is disconnected.
+	conn, err := net.Dial("tcp", server.Addr())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\n"))
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(10*time.Second)))
+	_, err = io.ReadAll(conn)
+	require.NoError(t, err)
+}
+
 type serverTestCase struct {
 	Mapper        *objectmap.IPDB
 	HandlerConfig sharing.Config
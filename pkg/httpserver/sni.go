@@ -0,0 +1,112 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package httpserver
+
+import (
+	"crypto/tls"
+	"strings"
+	"time"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+)
+
+// SNICertificate is a certificate/key pair served for a specific host name.
+type SNICertificate struct {
+	// Host is the server name the certificate is served for. A leading "*."
+	// matches any single subdomain label, e.g. *.example.com matches
+	// www.example.com, but not example.com.
+	Host string
+
+	// CertFile is a path to a file containing a corresponding cert for KeyFile.
+	CertFile string
+
+	// KeyFile is a path to a file containing a corresponding key for CertFile.
+	KeyFile string
+}
+
+// ParseSNICertificates parses a list of SNI certificates in the
+// host:cert-file:key-file form.
+func ParseSNICertificates(entries []string) ([]SNICertificate, error) {
+	var certs []SNICertificate
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return nil, errs.New("invalid SNI certificate %q: expected host:cert-file:key-file", entry)
+		}
+		certs = append(certs, SNICertificate{
+			Host:     parts[0],
+			CertFile: parts[1],
+			KeyFile:  parts[2],
+		})
+	}
+	return certs, nil
+}
+
+// sniCertificates selects a certificate based on the server name the client
+// indicated during the handshake. The certificates are reloaded like the
+// default ones when their files change.
+type sniCertificates struct {
+	reloader *certReloader
+	hosts    map[string]*reloadableCertificate
+}
+
+func loadSNICertificates(log *zap.Logger, configs []SNICertificate, reloadInterval time.Duration) (*sniCertificates, error) {
+	certs := &sniCertificates{
+		reloader: &certReloader{log: log, interval: reloadInterval},
+		hosts:    make(map[string]*reloadableCertificate, len(configs)),
+	}
+	for _, config := range configs {
+		host := strings.ToLower(strings.TrimSuffix(config.Host, "."))
+		if _, ok := certs.hosts[host]; ok {
+			return nil, errs.New("duplicate SNI certificate for %s", config.Host)
+		}
+
+		cert, err := loadReloadableCertificate(certKeyPair{certFile: config.CertFile, keyFile: config.KeyFile})
+		if err != nil {
+			return nil, errs.New("unable to load server keypair for %s: %v", config.Host, err)
+		}
+
+		certs.hosts[host] = cert
+	}
+	return certs, nil
+}
+
+// empty returns whether there are no SNI certificates.
+func (certs *sniCertificates) empty() bool {
+	return len(certs.hosts) == 0
+}
+
+// lookup returns the certificate for serverName, preferring an exact match
+// over a wildcard one. It returns nil if there's no match.
+func (certs *sniCertificates) lookup(serverName string) *tls.Certificate {
+	name := strings.ToLower(strings.TrimSuffix(serverName, "."))
+	if name == "" {
+		return nil
+	}
+	if cert, ok := certs.hosts[name]; ok {
+		return certs.reloader.current(cert)
+	}
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		if cert, ok := certs.hosts["*"+name[i:]]; ok {
+			return certs.reloader.current(cert)
+		}
+	}
+	return nil
+}
+
+// getCertificate returns the certificate matching the SNI of hello, or falls
+// back to next. If next is nil, it returns nil, which makes crypto/tls use
+// the default certificates.
+func (certs *sniCertificates) getCertificate(next func(*tls.ClientHelloInfo) (*tls.Certificate, error)) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		if cert := certs.lookup(hello.ServerName); cert != nil {
+			return cert, nil
+		}
+		if next == nil {
+			return nil, nil
+		}
+		return next(hello)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package httpserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestParseSNICertificates(t *testing.T) {
+	certs, err := ParseSNICertificates([]string{
+		"example.com:/certs/example.crt:/certs/example.key",
+		"*.example.org:org.crt:org.key",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []SNICertificate{
+		{Host: "example.com", CertFile: "/certs/example.crt", KeyFile: "/certs/example.key"},
+		{Host: "*.example.org", CertFile: "org.crt", KeyFile: "org.key"},
+	}, certs)
+
+	for _, entry := range []string{"", "example.com", "example.com:cert", "example.com::key", ":cert:key"} {
+		_, err = ParseSNICertificates([]string{entry})
+		require.Error(t, err, entry)
+	}
+}
+
+func TestSNICertificateSelection(t *testing.T) {
+	dir := t.TempDir()
+
+	defaultCert, defaultKey := writeTestCert(t, dir, "default", "default.test")
+	oneCert, oneKey := writeTestCert(t, dir, "one", "one.test")
+	twoCert, twoKey := writeTestCert(t, dir, "two", "*.two.test")
+
+	tlsConfig, err := configureTLS(zap.NewNop(), nil, Config{
+		TLSConfig: &TLSConfig{
+			CertFile: defaultCert,
+			KeyFile:  defaultKey,
+			SNICertificates: []SNICertificate{
+				{Host: "one.test", CertFile: oneCert, KeyFile: oneKey},
+				{Host: "*.two.test", CertFile: twoCert, KeyFile: twoKey},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, tlsConfig.GetCertificate)
+
+	for serverName, expected := range map[string]string{
+		"one.test":     "one.test",
+		"ONE.test.":    "one.test",
+		"www.two.test": "*.two.test",
+		"a.b.two.test": "",
+		"two.test":     "",
+		"other.test":   "",
+		"":             "",
+	} {
+		cert, err := tlsConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: serverName})
+		require.NoError(t, err)
+		if expected == "" {
+			// crypto/tls falls back to the default certificate.
+			require.Nil(t, cert, serverName)
+			continue
+		}
+		require.NotNil(t, cert, serverName)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		require.Equal(t, []string{expected}, leaf.DNSNames, serverName)
+	}
+
+	require.Len(t, tlsConfig.Certificates, 1)
+	leaf, err := x509.ParseCertificate(tlsConfig.Certificates[0].Certificate[0])
+	require.NoError(t, err)
+	require.Equal(t, []string{"default.test"}, leaf.DNSNames)
+}
+
+func TestSNICertificatesRequireDefault(t *testing.T) {
+	dir := t.TempDir()
+
+	oneCert, oneKey := writeTestCert(t, dir, "one", "one.test")
+
+	_, err := configureTLS(zap.NewNop(), nil, Config{
+		TLSConfig: &TLSConfig{
+			SNICertificates: []SNICertificate{
+				{Host: "one.test", CertFile: oneCert, KeyFile: oneKey},
+			},
+		},
+	})
+	require.Error(t, err)
+
+	_, err = configureTLS(zap.NewNop(), nil, Config{
+		TLSConfig: &TLSConfig{
+			CertFile: oneCert,
+			KeyFile:  oneKey,
+			SNICertificates: []SNICertificate{
+				{Host: "one.test", CertFile: oneCert, KeyFile: oneKey},
+				{Host: "ONE.test", CertFile: oneCert, KeyFile: oneKey},
+			},
+		},
+	})
+	require.Error(t, err)
+}
+
+func writeTestCert(t *testing.T, dir, name, dnsName string) (certPath, keyPath string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{dnsName},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	certPath = filepath.Join(dir, name+".crt")
+	keyPath = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0644))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	return certPath, keyPath
+}
@@ -26,6 +26,12 @@ type IPInfo struct {
 	} `maxminddb:"location"`
 }
 
+// ASNInfo represents the autonomous system data from maxmind ASN db.
+type ASNInfo struct {
+	Number       uint   `maxminddb:"autonomous_system_number"`
+	Organization string `maxminddb:"autonomous_system_organization"`
+}
+
 // Reader is a maxmind database reader interface.
 type Reader interface {
 	Lookup(ip net.IP, result interface{}) error
@@ -37,30 +43,48 @@ type cachedInfo struct {
 	IPInfo
 }
 
+type cachedASNInfo struct {
+	Error error
+	ASNInfo
+}
+
 // IPDB holds the database file path and its reader.
 //
 // architecture: Database
 type IPDB struct {
-	reader Reader
+	reader    Reader
+	asnReader Reader
 
-	mu        sync.RWMutex
-	cachedIPs map[string]cachedInfo
+	mu         sync.RWMutex
+	cachedIPs  map[string]cachedInfo
+	cachedASNs map[string]cachedASNInfo
 }
 
 // NewIPDB creates a new IPMapper instance.
 func NewIPDB(reader Reader) *IPDB {
+	return NewIPDBWithASN(reader, nil)
+}
+
+// NewIPDBWithASN creates a new IPMapper instance that additionally looks up
+// autonomous systems using asnReader. asnReader may be nil.
+func NewIPDBWithASN(reader, asnReader Reader) *IPDB {
 	return &IPDB{
-		reader:    reader,
-		cachedIPs: make(map[string]cachedInfo),
+		reader:     reader,
+		asnReader:  asnReader,
+		cachedIPs:  make(map[string]cachedInfo),
+		cachedASNs: make(map[string]cachedASNInfo),
 	}
 }
 
-// Close closes the IPMapper reader.
+// Close closes the IPMapper readers.
 func (mapper *IPDB) Close() (err error) {
 	if mapper.reader != nil {
-		return mapper.reader.Close()
+		err = mapper.reader.Close()
+	}
+	if mapper.asnReader != nil {
+		err = errs.Combine(err, mapper.asnReader.Close())
 	}
-	return nil
+	return err
 }
 
 // GetIPInfos returns the geolocation information from an IP address.
@@ -99,15 +123,55 @@ func (mapper *IPDB) GetIPInfos(ctx context.Context, hostOrIP string) (_ *IPInfo,
 	return &record, nil
 }
 
-// parseHost validate and remove port from IP address.
-func (mapper *IPDB) parseHost(hostOrIP string) (_ net.IP, err error) {
-	if strings.Count(hostOrIP, ":") > 1 {
-		return nil, errs.New("IPv6 addresses are ignored for now: %s", hostOrIP)
+// GetASN returns the autonomous system of an IP address. It returns nil
+// without an error if no ASN database was configured.
+func (mapper *IPDB) GetASN(ctx context.Context, hostOrIP string) (_ *ASNInfo, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if mapper.asnReader == nil {
+		return nil, nil
 	}
 
+	mapper.mu.RLock()
+	cacheItem, ok := mapper.cachedASNs[hostOrIP]
+	mapper.mu.RUnlock()
+
+	if ok {
+		if cacheItem.Error != nil {
+			return nil, cacheItem.Error
+		}
+		return &cacheItem.ASNInfo, nil
+	}
+
+	parsed, err := mapper.parseHost(hostOrIP)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	var record ASNInfo
+	err = mapper.asnReader.Lookup(parsed, &record)
+
+	mapper.mu.Lock()
+	mapper.cachedASNs[hostOrIP] = cachedASNInfo{
+		Error:   err,
+		ASNInfo: record,
+	}
+	mapper.mu.Unlock()
+
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return &record, nil
+}
+
+// parseHost validates the IPv4 or IPv6 address in hostOrIP, which may
+// have a port, and returns it without the port.
+func (mapper *IPDB) parseHost(hostOrIP string) (_ net.IP, err error) {
 	ip, _, err := net.SplitHostPort(hostOrIP)
 	if err != nil {
-		ip = hostOrIP // assume it had no port
+		// assume it had no port, though an IPv6 address may still be in
+		// brackets.
+		ip = strings.TrimSuffix(strings.TrimPrefix(hostOrIP, "["), "]")
 	}
 
 	parsed := net.ParseIP(ip)
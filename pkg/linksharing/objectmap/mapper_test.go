@@ -29,6 +29,13 @@ func TestIPDB_GetIPInfos(t *testing.T) {
 		{"valid (IP:PORT) found geolocation", mockReader, "172.146.10.1:4545", mockIPInfo(-19.456, 20.123), false},
 		{"valid IP geolocation not found", mockReader, "1.1.1.1", &IPInfo{}, true},
 		{"valid (IP:PORT) geolocation not found", mockReader, "1.1.1.1:1000", &IPInfo{}, true},
+		{"valid IPv6 found geolocation", mockReader, "2001:db8:a0b:12f0::1", mockIPInfo(52.379, 4.9), false},
+		{"valid IPv6 (IP:PORT) found geolocation", mockReader, "[2001:db8:a0b:12f0::1]:28967", mockIPInfo(52.379, 4.9), false},
+		{"valid bracketed IPv6 found geolocation", mockReader, "[2001:db8:a0b:12f0::1]", mockIPInfo(52.379, 4.9), false},
+		{"valid expanded IPv6 found geolocation", mockReader, "2001:0db8:0a0b:12f0:0000:0000:0000:0001", mockIPInfo(52.379, 4.9), false},
+		{"invalid IPv6", mockReader, "2001:db8:a0b:12f0::1::1", nil, true},
+		{"invalid IPv6 (IP:PORT)", mockReader, "[2001:db8:a0b:12f0::1::1]:28967", nil, true},
+		{"IPv6 network", mockReader, "2001:db8:a0b:12f0::1/64", nil, true},
 	}
 	for _, tt := range tests {
 		mapper := NewIPDB(tt.reader)
@@ -67,10 +74,13 @@ func TestIPDB_GetIPInfos_Concurrent(t *testing.T) {
 		"172.146.10.10:4545",
 	}
 
-	ipv6addresses := [5]string{
+	ipv6addresses := [3]string{
 		"2001:db8:a0b:12f0::1",
 		"2001:db8:0:1:1:1:1:1",
 		"[2001:db8:a0b:12f0::1]:21",
+	}
+
+	invalidAddresses := [2]string{
 		"2001:db8:a0b:12f0::1/64",
 		"2001:db8:a0b:12f0::1%eth0",
 	}
@@ -98,6 +108,26 @@ func TestIPDB_GetIPInfos_Concurrent(t *testing.T) {
 	}
 
 	for _, ip := range ipv6addresses {
+		ip := ip
+		t.Run(ip, func(t *testing.T) {
+			group.Go(func() error {
+				ipInfo, err := mapper.GetIPInfos(ctx, ip)
+
+				assert.NoError(t, err)
+				assert.NotNil(t, ipInfo)
+				return nil
+			})
+			group.Go(func() error {
+				ipInfo, err := mapper.GetIPInfos(ctx, ip)
+
+				assert.NoError(t, err)
+				assert.NotNil(t, ipInfo)
+				return nil
+			})
+		})
+	}
+
+	for _, ip := range invalidAddresses {
 		ip := ip
 		t.Run(ip, func(t *testing.T) {
 			group.Go(func() error {
@@ -120,5 +150,45 @@ func TestIPDB_GetIPInfos_Concurrent(t *testing.T) {
 	err := group.Wait()
 	require.NoError(t, err)
 
-	require.Equal(t, 10, len(mapper.cachedIPs))
+	require.Equal(t, 13, len(mapper.cachedIPs))
+}
+
+func TestIPDB_GetASN(t *testing.T) {
+	ctx := context.Background()
+
+	mapper := NewIPDB(&MockReader{})
+	info, err := mapper.GetASN(ctx, "172.146.10.1")
+	require.NoError(t, err)
+	require.Nil(t, info)
+
+	mapper = NewIPDBWithASN(&MockReader{}, &MockReader{})
+
+	tests := []struct {
+		name        string
+		ipAddress   string
+		expected    *ASNInfo
+		expectedErr bool
+	}{
+		{"invalid IP", "999.999.999.999", nil, true},
+		{"valid IP found ASN", "172.146.10.1", mockASNInfo(64496, "Example Networks"), false},
+		{"valid (IP:PORT) found ASN", "172.146.10.1:4545", mockASNInfo(64496, "Example Networks"), false},
+		{"valid IPv6 (IP:PORT) found ASN", "[2001:db8:a0b:12f0::1]:28967", mockASNInfo(64497, "Example IPv6 Networks"), false},
+		{"valid IP ASN not found", "1.1.1.1", nil, true},
+	}
+	for _, tt := range tests {
+		testCase := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mapper.GetASN(ctx, testCase.ipAddress)
+
+			if testCase.expectedErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.EqualValues(t, testCase.expected, got)
+		})
+	}
+
+	require.NoError(t, mapper.Close())
 }
@@ -19,7 +19,21 @@ type MockReader struct{}
 func (mr *MockReader) Lookup(ip net.IP, result interface{}) error {
 	// Valid geolocation case
 	if ip.Equal(net.IPv4(172, 146, 10, 1)) {
-		result.(*IPInfo).Location = mockIPInfo(-19.456, 20.123).Location
+		switch result := result.(type) {
+		case *IPInfo:
+			result.Location = mockIPInfo(-19.456, 20.123).Location
+		case *ASNInfo:
+			*result = *mockASNInfo(64496, "Example Networks")
+		}
+		return nil
+	}
+	if ip.Equal(net.ParseIP("2001:db8:a0b:12f0::1")) {
+		switch result := result.(type) {
+		case *IPInfo:
+			result.Location = mockIPInfo(52.379, 4.9).Location
+		case *ASNInfo:
+			*result = *mockASNInfo(64497, "Example IPv6 Networks")
+		}
 		return nil
 	}
 	// Location not found
@@ -45,3 +59,10 @@ func mockIPInfo(latitude, longitude float64) *IPInfo {
 		},
 	}
 }
+
+func mockASNInfo(number uint, organization string) *ASNInfo {
+	return &ASNInfo{
+		Number:       number,
+		Organization: organization,
+	}
+}
@@ -5,12 +5,18 @@ package objectranger
 
 import (
 	"context"
+	"errors"
 	"io"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
 
 	"github.com/spacemonkeygo/monkit/v3"
 
 	"storj.io/common/ranger"
 	"storj.io/common/ranger/httpranger"
+	"storj.io/common/rpc/rpcstatus"
 	"storj.io/uplink"
 )
 
@@ -18,23 +24,52 @@ var (
 	mon = monkit.Package()
 )
 
+// RetryConfig configures retrying downloads that fail with transient errors.
+type RetryConfig struct {
+	MaxAttempts int           `user:"true" help:"maximum number of attempts to download an object range; 1 disables retries" default:"1"`
+	BaseDelay   time.Duration `user:"true" help:"delay before the first retry of a download, doubled with every following retry" default:"100ms"`
+	MaxDelay    time.Duration `user:"true" help:"maximum delay between download retries" default:"2s"`
+}
+
+// delay returns the delay before the given retry (starting at 1), with
+// exponential backoff and jitter.
+func (config RetryConfig) delay(retry int) time.Duration {
+	d := config.BaseDelay
+	for i := 1; i < retry && d < config.MaxDelay; i++ {
+		d *= 2
+	}
+	if config.MaxDelay > 0 && d > config.MaxDelay {
+		d = config.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	// full jitter in the upper half keeps retries of concurrent downloads
+	// from synchronizing while still backing off.
+	return d/2 + rand.N(d/2+1)
+}
+
 // ObjectRanger holds all the data needed to make object downloadable.
 type ObjectRanger struct {
-	p      *uplink.Project
-	o      *uplink.Object
-	d      *uplink.Download
-	r      httpranger.HTTPRange
-	bucket string
+	o     *uplink.Object
+	d     *uplink.Download
+	r     httpranger.HTTPRange
+	retry RetryConfig
+
+	// downloadObject opens a download of a range of the object.
+	downloadObject func(ctx context.Context, offset, length int64) (io.ReadCloser, error)
 }
 
 // New creates a new object ranger.
-func New(p *uplink.Project, o *uplink.Object, d *uplink.Download, r httpranger.HTTPRange, bucket string) ranger.Ranger {
+func New(p *uplink.Project, o *uplink.Object, d *uplink.Download, r httpranger.HTTPRange, bucket string, retry RetryConfig) ranger.Ranger {
 	return &ObjectRanger{
-		p:      p,
-		o:      o,
-		d:      d,
-		r:      r,
-		bucket: bucket,
+		o:     o,
+		d:     d,
+		r:     r,
+		retry: retry,
+		downloadObject: func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+			return p.DownloadObject(ctx, bucket, o.Key, &uplink.DownloadOptions{Offset: offset, Length: length})
+		},
 	}
 }
 
@@ -46,8 +81,206 @@ func (ranger *ObjectRanger) Size() int64 {
 // Range returns object read/close interface.
 func (ranger *ObjectRanger) Range(ctx context.Context, offset, length int64) (_ io.ReadCloser, err error) {
 	defer mon.Task()(&ctx)(&err)
+
+	start := time.Now()
+
+	// attempts counts the downloads of the range opened so far, including
+	// the ones that failed, so that all of them together, whether opening or
+	// resuming the range, never exceed the configured maximum.
+	var attempts int
+
+	var rc io.ReadCloser
 	if ranger.d != nil && ranger.r.Start == offset && ranger.r.Length == length {
-		return ranger.d, nil
+		rc = ranger.d
+		attempts = 1
+	} else {
+		rc, err = ranger.download(ctx, offset, length, &attempts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if ranger.retry.MaxAttempts > 1 {
+		rc = &retryingReader{
+			ctx:      ctx,
+			ranger:   ranger,
+			rc:       rc,
+			offset:   offset,
+			length:   length,
+			attempts: attempts,
+		}
+	}
+
+	rangeKind := "partial"
+	if offset == 0 && (length < 0 || length >= ranger.Size()) {
+		rangeKind = "full"
+	}
+
+	return &timingReader{
+		ReadCloser: rc,
+		start:      start,
+		rangeTag:   monkit.NewSeriesTag("range", rangeKind),
+	}, nil
+}
+
+// timingReader records the time to the first byte and the total duration of
+// reading a range.
+type timingReader struct {
+	io.ReadCloser
+
+	start     time.Time
+	rangeTag  monkit.SeriesTag
+	firstByte bool
+	closed    bool
+}
+
+func (r *timingReader) Read(p []byte) (n int, err error) {
+	n, err = r.ReadCloser.Read(p)
+	if n > 0 && !r.firstByte {
+		r.firstByte = true
+		mon.DurationVal("object_ranger_time_to_first_byte", r.rangeTag).Observe(time.Since(r.start))
+	}
+	return n, err
+}
+
+func (r *timingReader) Close() error {
+	if !r.closed {
+		r.closed = true
+		mon.DurationVal("object_ranger_range_duration", r.rangeTag).Observe(time.Since(r.start))
+	}
+	return r.ReadCloser.Close()
+}
+
+// download opens a download of the range, retrying transient errors.
+// attempts is the number of attempts made to download the range so far,
+// which is incremented for every attempt made.
+func (ranger *ObjectRanger) download(ctx context.Context, offset, length int64, attempts *int) (_ io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	for {
+		*attempts++
+		rc, err := ranger.downloadObject(ctx, offset, length)
+		if err == nil || *attempts >= ranger.retry.MaxAttempts || !isRetryable(err) {
+			return rc, err
+		}
+
+		mon.Event("object_ranger_download_retry")
+
+		if err := sleep(ctx, ranger.retry.delay(*attempts)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryingReader reads a range of an object, resuming the download where it
+// stopped if reading fails with a transient error.
+type retryingReader struct {
+	ctx    context.Context
+	ranger *ObjectRanger
+	// rc is nil once a download was closed to be resumed and resuming it
+	// failed, so that it's not closed twice.
+	rc io.ReadCloser
+	// err is the error resuming the download failed with.
+	err error
+
+	// offset and length are those of the remaining range, i.e. they move
+	// forward with every byte read. A negative length means the range
+	// extends to the end of the object.
+	offset int64
+	length int64
+
+	// attempts is the number of downloads of the range opened so far.
+	attempts int
+}
+
+func (r *retryingReader) Read(p []byte) (n int, err error) {
+	if r.rc == nil {
+		return 0, r.err
+	}
+
+	for {
+		n, err = r.rc.Read(p)
+		r.offset += int64(n)
+		if r.length > 0 {
+			r.length -= int64(n)
+		}
+
+		if err == nil || errors.Is(err, io.EOF) || !isRetryable(err) {
+			return n, err
+		}
+
+		if r.length == 0 {
+			// the whole range was read, there's nothing left to resume.
+			return n, io.EOF
+		}
+
+		if r.attempts >= r.ranger.retry.MaxAttempts {
+			return n, err
+		}
+
+		mon.Event("object_ranger_read_retry")
+
+		_ = r.rc.Close()
+		r.rc = nil
+
+		if sleepErr := sleep(r.ctx, r.ranger.retry.delay(r.attempts)); sleepErr != nil {
+			r.err = errors.Join(err, sleepErr)
+			return n, r.err
+		}
+
+		rc, downloadErr := r.ranger.download(r.ctx, r.offset, r.length, &r.attempts)
+		if downloadErr != nil {
+			r.err = downloadErr
+			return n, r.err
+		}
+		r.rc = rc
+
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (r *retryingReader) Close() error {
+	if r.rc == nil {
+		return nil
+	}
+	return r.rc.Close()
+}
+
+// isRetryable returns whether err is known to be transient, so that retrying
+// the download might succeed. Errors that aren't known to be transient, e.g.
+// missing objects or exceeded limits, aren't retried.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return rpcstatus.Code(err) == rpcstatus.Unavailable
+}
+
+// sleep waits for d or until ctx is canceled.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	return ranger.p.DownloadObject(ctx, ranger.bucket, ranger.o.Key, &uplink.DownloadOptions{Offset: offset, Length: length})
 }
@@ -0,0 +1,196 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package objectranger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+	"storj.io/uplink"
+)
+
+// fakeObject serves downloads of data that fail with err after failAfter
+// bytes or, if failAfter is negative, when they're opened. If reopenErr is
+// set, downloads but the first fail with it when they're opened.
+type fakeObject struct {
+	data      []byte
+	failAfter int
+	err       error
+	reopenErr error
+
+	offsets []int64
+	closes  int
+}
+
+func (o *fakeObject) ranger(maxAttempts int) *ObjectRanger {
+	return &ObjectRanger{
+		o:     &uplink.Object{System: uplink.SystemMetadata{ContentLength: int64(len(o.data))}},
+		retry: RetryConfig{MaxAttempts: maxAttempts},
+		downloadObject: func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+			o.offsets = append(o.offsets, offset)
+			if o.failAfter < 0 {
+				return nil, o.err
+			}
+			if o.reopenErr != nil && len(o.offsets) > 1 {
+				return nil, o.reopenErr
+			}
+			end := int64(len(o.data))
+			if length >= 0 && offset+length < end {
+				end = offset + length
+			}
+			return &failingReader{data: o.data[offset:end], failAfter: o.failAfter, err: o.err, closes: &o.closes}, nil
+		},
+	}
+}
+
+type failingReader struct {
+	data      []byte
+	failAfter int
+	err       error
+	closes    *int
+}
+
+// Read fails once failAfter bytes were read, even if they were all of the
+// data, like a connection reset after the last byte.
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.failAfter == 0 {
+		return 0, r.err
+	}
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p[:min(len(p), r.failAfter)], r.data)
+	r.data = r.data[n:]
+	r.failAfter -= n
+	return n, nil
+}
+
+func (r *failingReader) Close() error {
+	*r.closes++
+	return nil
+}
+
+func TestRangeRetriesOpening(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	for _, tc := range []struct {
+		name        string
+		err         error
+		maxAttempts int
+		attempts    int
+	}{
+		{name: "transient", err: syscall.ECONNRESET, maxAttempts: 3, attempts: 3},
+		{name: "retries disabled", err: syscall.ECONNRESET, maxAttempts: 1, attempts: 1},
+		{name: "not found", err: uplink.ErrObjectNotFound, maxAttempts: 3, attempts: 1},
+		{name: "unknown", err: errors.New("unknown"), maxAttempts: 3, attempts: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			object := &fakeObject{data: []byte("data"), failAfter: -1, err: tc.err}
+
+			_, err := object.ranger(tc.maxAttempts).Range(ctx, 0, 4)
+			require.ErrorIs(t, err, tc.err)
+			require.Len(t, object.offsets, tc.attempts)
+		})
+	}
+}
+
+func TestRangeResumesReading(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	object := &fakeObject{data: []byte("0123456789"), failAfter: 4, err: syscall.ECONNRESET}
+
+	rc, err := object.ranger(3).Range(ctx, 1, 8)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, rc.Close()) }()
+
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.Equal(t, "12345678", string(data))
+	require.Equal(t, []int64{1, 5}, object.offsets)
+}
+
+func TestRangeDoesntResumeReadRange(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	// the download fails right after the last byte of the range.
+	object := &fakeObject{data: []byte("0123456789"), failAfter: 4, err: syscall.ECONNRESET}
+
+	rc, err := object.ranger(3).Range(ctx, 2, 4)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, rc.Close()) }()
+
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.Equal(t, "2345", string(data))
+	require.Equal(t, []int64{2}, object.offsets)
+}
+
+func TestRangeClosesOnceIfResumingFails(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	errReopen := errors.New("reopen failed")
+	object := &fakeObject{data: []byte("0123456789"), failAfter: 4, err: syscall.ECONNRESET, reopenErr: errReopen}
+
+	rc, err := object.ranger(3).Range(ctx, 0, 10)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(rc)
+	require.ErrorIs(t, err, errReopen)
+	require.Equal(t, "0123", string(data))
+
+	_, err = rc.Read(make([]byte, 1))
+	require.ErrorIs(t, err, errReopen)
+
+	require.NoError(t, rc.Close())
+	require.Equal(t, 1, object.closes)
+}
+
+func TestRangeAttemptsDontExceedMax(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	for maxAttempts := 2; maxAttempts <= 4; maxAttempts++ {
+		t.Run(fmt.Sprint(maxAttempts), func(t *testing.T) {
+			// every download fails after a byte, so that the range can't be
+			// read completely with maxAttempts downloads.
+			object := &fakeObject{data: []byte("0123456789"), failAfter: 1, err: syscall.ECONNRESET}
+
+			rc, err := object.ranger(maxAttempts).Range(ctx, 0, 10)
+			require.NoError(t, err)
+			defer func() { require.NoError(t, rc.Close()) }()
+
+			data, err := io.ReadAll(rc)
+			require.ErrorIs(t, err, syscall.ECONNRESET)
+			require.Equal(t, "0123456789"[:maxAttempts], string(data))
+			require.Len(t, object.offsets, maxAttempts)
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	for _, tc := range []struct {
+		err       error
+		retryable bool
+	}{
+		{err: io.ErrUnexpectedEOF, retryable: true},
+		{err: fmt.Errorf("read: %w", syscall.ECONNRESET), retryable: true},
+		{err: syscall.ECONNREFUSED, retryable: true},
+		{err: syscall.EPIPE, retryable: true},
+		{err: context.Canceled, retryable: false},
+		{err: context.DeadlineExceeded, retryable: false},
+		{err: uplink.ErrObjectNotFound, retryable: false},
+		{err: uplink.ErrPermissionDenied, retryable: false},
+		{err: uplink.ErrBandwidthLimitExceeded, retryable: false},
+		{err: uplink.ErrTooManyRequests, retryable: false},
+		{err: errors.New("unknown"), retryable: false},
+	} {
+		require.Equal(t, tc.retryable, isRetryable(tc.err), tc.err)
+	}
+}
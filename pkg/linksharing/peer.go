@@ -47,8 +47,16 @@ type Config struct {
 	// Maxmind geolocation database path.
 	GeoLocationDB string
 
+	// Maxmind ASN database path. Optional and only used together with
+	// GeoLocationDB.
+	GeoLocationASNDB string
+
 	// ConcurrentRequestLimit is the number of concurrent requests allowed per project ID, or if unavailable, macaroon head.
 	ConcurrentRequestLimit uint
+
+	// SecurityHeaders configures the security headers set on responses for
+	// the URL bases. They aren't set for hosted websites.
+	SecurityHeaders gwmiddleware.SecurityHeadersConfig
 }
 
 // Peer is the representation of a Linksharing service itself.
@@ -72,7 +80,7 @@ func New(log *zap.Logger, config Config) (_ *Peer, err error) {
 		return nil, err
 	}
 	authClient := authclient.New(config.Handler.AuthServiceConfig)
-	txtRecords := sharing.NewTXTRecords(config.Handler.TXTRecordTTL, dnsClient, authClient)
+	txtRecords := sharing.NewTXTRecords(config.Handler.TXTRecordTTL, config.Handler.TXTRecordNegativeTTL, dnsClient, authClient)
 
 	peer := &Peer{
 		Log:           log,
@@ -85,7 +93,19 @@ func New(log *zap.Logger, config Config) (_ *Peer, err error) {
 		if err != nil {
 			return nil, errs.New("unable to open geo location db: %w", err)
 		}
-		peer.Mapper = objectmap.NewIPDB(reader)
+
+		var asnReader objectmap.Reader
+		if config.GeoLocationASNDB != "" {
+			asnDB, err := maxminddb.Open(config.GeoLocationASNDB)
+			if err != nil {
+				return nil, errs.Combine(errs.New("unable to open geo location asn db: %w", err), reader.Close())
+			}
+			asnReader = asnDB
+		}
+
+		peer.Mapper = objectmap.NewIPDBWithASN(reader, asnReader)
+	} else if config.GeoLocationASNDB != "" {
+		return nil, errs.New("geo location asn db requires geo location db")
 	}
 
 	var tqs *tierquery.Service
@@ -134,6 +154,21 @@ func New(log *zap.Logger, config Config) (_ *Peer, err error) {
 		return nil, errs.New("unable to create handler: %w", err)
 	}
 
+	// hosted websites are the users' own, which may be framed or load
+	// resources from anywhere, so only the service's own pages get the
+	// security headers.
+	securityHeaders := gwmiddleware.NewSecurityHeaders(config.SecurityHeaders)
+	r.Use(func(next http.Handler) http.Handler {
+		secured := securityHeaders(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if sharingHandler.IsServiceHost(req.Host) {
+				secured.ServeHTTP(w, req)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	})
+
 	if config.ConcurrentRequestLimit <= 0 {
 		return nil, ErrInvalidConcurrentRequests
 	}
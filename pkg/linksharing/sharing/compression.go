@@ -0,0 +1,150 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package sharing
+
+import (
+	"compress/gzip"
+	"mime"
+	"net/http"
+	"strings"
+
+	"storj.io/uplink"
+)
+
+// minCompressedSize is the size below which objects aren't compressed, as
+// it wouldn't save enough to make up for the overhead.
+const minCompressedSize = 1024
+
+// compressibleContentTypes lists the media types, besides text/*, that are
+// compressed.
+var compressibleContentTypes = map[string]bool{
+	"application/atom+xml":      true,
+	"application/javascript":    true,
+	"application/json":          true,
+	"application/ld+json":       true,
+	"application/manifest+json": true,
+	"application/rss+xml":       true,
+	"application/wasm":          true,
+	"application/xhtml+xml":     true,
+	"application/xml":           true,
+	"image/svg+xml":             true,
+}
+
+// isCompressible returns whether content of contentType is worth compressing.
+// Already compressed formats, e.g. images, video or archives, aren't.
+func isCompressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") || compressibleContentTypes[mediaType]
+}
+
+// compressResponse returns a writer that compresses the response of o with
+// gzip if compression is enabled and applies to it, and a function that must
+// be called after the response was served to finish it. The response headers
+// for o must already be set.
+//
+// Range requests aren't compressed, as the ranges refer to the uncompressed
+// object, and neither are objects with a content coding of their own.
+func (handler *Handler) compressResponse(w http.ResponseWriter, r *http.Request, o *uplink.Object) (http.ResponseWriter, func() error) {
+	noop := func() error { return nil }
+
+	if !handler.compression || r.Method != http.MethodGet || o.System.ContentLength < minCompressedSize ||
+		w.Header().Get("Content-Encoding") != "" || !isCompressible(w.Header().Get("Content-Type")) {
+		return w, noop
+	}
+
+	// the response varies with Accept-Encoding whether or not it ends up being
+	// compressed, so caches must not reuse it for other clients.
+	varyAcceptEncoding(w.Header())
+
+	if r.Header.Get("Range") != "" {
+		return w, noop
+	}
+	if !acceptsGzip(r.Header) {
+		return w, noop
+	}
+
+	gw := &gzipResponseWriter{ResponseWriter: w}
+	return gw, gw.finish
+}
+
+// varyAcceptEncoding adds Accept-Encoding to the Vary header unless it's
+// already there.
+func varyAcceptEncoding(header http.Header) {
+	if !hasValue(header, "Vary", "Accept-Encoding") {
+		header.Add("Vary", "Accept-Encoding")
+	}
+}
+
+// gzipResponseWriter compresses successful responses with gzip and passes
+// others, e.g. 304 Not Modified, through.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+
+	wroteHeader bool
+	gz          *gzip.Writer
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	if status == http.StatusOK {
+		header := w.Header()
+		header.Set("Content-Encoding", gzipContentCoding)
+		header.Del("Content-Length")
+		// the compressed representation differs byte-wise from the object,
+		// so its entity tag can only be weak.
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+		mon.Event("response_compressed")
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.gz.Write(p)
+}
+
+func (w *gzipResponseWriter) Flush() {
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// finish writes the rest of the compressed response.
+func (w *gzipResponseWriter) finish() error {
+	if w.gz == nil {
+		return nil
+	}
+	return w.gz.Close()
+}
+
+// acceptsGzip returns whether the client asked for gzip in the
+// Accept-Encoding header, by name or with a wildcard. Unlike
+// isContentCodingAcceptable, codings that aren't listed aren't assumed to be
+// acceptable, as clients don't necessarily understand them.
+func acceptsGzip(header http.Header) bool {
+	codingWeights := parseAcceptEncodingHeader(header)
+	if weight, ok := codingWeights[gzipContentCoding]; ok {
+		return weight > 0
+	}
+	return codingWeights["*"] > 0
+}
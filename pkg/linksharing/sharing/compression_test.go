@@ -0,0 +1,106 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package sharing
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"storj.io/uplink"
+)
+
+func TestIsCompressible(t *testing.T) {
+	assert.True(t, isCompressible("text/html; charset=utf-8"))
+	assert.True(t, isCompressible("text/css"))
+	assert.True(t, isCompressible("application/javascript"))
+	assert.True(t, isCompressible("image/svg+xml"))
+	assert.False(t, isCompressible("image/png"))
+	assert.False(t, isCompressible("application/zip"))
+	assert.False(t, isCompressible("application/octet-stream"))
+	assert.False(t, isCompressible(""))
+}
+
+func TestCompressResponse(t *testing.T) {
+	body := strings.Repeat("<p>hello</p>", 200)
+	o := &uplink.Object{System: uplink.SystemMetadata{ContentLength: int64(len(body))}}
+
+	serve := func(handler *Handler, contentType string, status int, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/site/index.html", nil)
+		r.Header = header
+
+		rec := httptest.NewRecorder()
+		rec.Header().Set("Content-Type", contentType)
+		rec.Header().Set("ETag", `"etag"`)
+
+		w, finish := handler.compressResponse(rec, r, o)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			_, err := io.WriteString(w, body)
+			require.NoError(t, err)
+		}
+		require.NoError(t, finish())
+		return rec
+	}
+
+	handler := &Handler{compression: true}
+	acceptsGzip := http.Header{"Accept-Encoding": {"gzip, deflate"}}
+
+	t.Run("compressed", func(t *testing.T) {
+		rec := serve(handler, "text/html", http.StatusOK, acceptsGzip)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		require.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+		require.Equal(t, `W/"etag"`, rec.Header().Get("ETag"))
+		require.Empty(t, rec.Header().Get("Content-Length"))
+		require.Less(t, rec.Body.Len(), len(body))
+
+		gz, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		decompressed, err := io.ReadAll(gz)
+		require.NoError(t, err)
+		require.Equal(t, body, string(decompressed))
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		rec := serve(handler, "text/html", http.StatusNotModified, acceptsGzip)
+		require.Equal(t, http.StatusNotModified, rec.Code)
+		require.Empty(t, rec.Header().Get("Content-Encoding"))
+		require.Zero(t, rec.Body.Len())
+	})
+
+	for _, tc := range []struct {
+		desc        string
+		handler     *Handler
+		contentType string
+		header      http.Header
+		vary        bool
+	}{
+		{desc: "disabled", handler: &Handler{}, contentType: "text/html", header: acceptsGzip},
+		{desc: "not compressible", handler: handler, contentType: "image/png", header: acceptsGzip},
+		{desc: "not accepted", handler: handler, contentType: "text/html", header: http.Header{"Accept-Encoding": {"br"}}, vary: true},
+		{desc: "no accept-encoding", handler: handler, contentType: "text/html", header: http.Header{}, vary: true},
+		{desc: "range", handler: handler, contentType: "text/html", header: http.Header{"Accept-Encoding": {"gzip"}, "Range": {"bytes=0-99"}}, vary: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			rec := serve(tc.handler, tc.contentType, http.StatusOK, tc.header)
+			require.Empty(t, rec.Header().Get("Content-Encoding"))
+			require.Equal(t, `"etag"`, rec.Header().Get("ETag"))
+			require.Equal(t, body, rec.Body.String())
+			if tc.vary {
+				require.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+			} else {
+				require.Empty(t, rec.Header().Get("Vary"))
+			}
+		})
+	}
+}
@@ -23,13 +23,17 @@ import (
 type credentialsCV struct{}
 
 type credentials struct {
-	serializedAccess string
-	access           *uplink.Access
-	publicProjectID  string
-	hostingRoot      string
-	hostingTLS       bool
-	hostingHost      string
-	err              error
+	serializedAccess     string
+	access               *uplink.Access
+	publicProjectID      string
+	hostingRoot          string
+	hostingTLS           bool
+	hostingPrecompressed []string
+	hostingDefaultObject string
+	hostingDefaultStatus int
+	hostingCanonicalHost string
+	hostingHost          string
+	err                  error
 }
 
 func credentialsFromContext(ctx context.Context) *credentials {
@@ -138,12 +142,16 @@ func (h *Handler) hostingCredentials(ctx context.Context, r *http.Request) (cred
 	}
 
 	return credentials{
-		serializedAccess: result.SerializedAccess,
-		access:           result.Access,
-		publicProjectID:  result.PublicProjectID,
-		hostingRoot:      result.Root,
-		hostingTLS:       result.TLS,
-		hostingHost:      host,
+		serializedAccess:     result.SerializedAccess,
+		access:               result.Access,
+		publicProjectID:      result.PublicProjectID,
+		hostingRoot:          result.Root,
+		hostingTLS:           result.TLS,
+		hostingPrecompressed: result.Precompressed,
+		hostingDefaultObject: result.DefaultObject,
+		hostingDefaultStatus: result.DefaultObjectStatus,
+		hostingCanonicalHost: result.CanonicalHost,
+		hostingHost:          host,
 	}, nil
 }
 
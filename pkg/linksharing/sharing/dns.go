@@ -16,6 +16,9 @@ import (
 
 var (
 	errDNS = errs.Class("dns error")
+
+	// errNXDomain is returned by lookups of names that don't exist.
+	errNXDomain = errs.New("no such domain")
 )
 
 // DNSClient is a wrapper utility around github.com/miekg/dns to make it
@@ -66,6 +69,9 @@ func (cli *DNSClient) LookupTXTRecordSet(ctx context.Context, host string) (_ *T
 	if err != nil {
 		return nil, errDNS.Wrap(err)
 	}
+	if r.Rcode == dns.RcodeNameError {
+		return nil, errDNS.Wrap(errNXDomain)
+	}
 	return ResponseToTXTRecordSet(r), nil
 }
 
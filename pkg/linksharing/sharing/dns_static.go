@@ -74,7 +74,7 @@ func (cli *StaticDNSClient) LookupTXTRecordSet(ctx context.Context, host string)
 
 	set, ok := cli.txt[host]
 	if !ok {
-		return nil, errDNS.New("not found")
+		return nil, errDNS.Wrap(errNXDomain)
 	}
 
 	return set, nil
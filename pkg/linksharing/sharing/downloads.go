@@ -0,0 +1,86 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package sharing
+
+import (
+	"sync"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+)
+
+// downloadsRetryAfter is the Retry-After, in seconds, of responses to
+// downloads rejected because too many are in flight.
+const downloadsRetryAfter = "5"
+
+// ErrTooManyDownloads is an error returned when a download would exceed the
+// concurrent downloads limits.
+var ErrTooManyDownloads = errs.Class("too many concurrent downloads")
+
+// downloadLimiter limits the number of downloads of object contents and
+// prefixes served at a time, in total and per access grant. Downloads beyond
+// the limits are rejected rather than queued, as waiting downloads would hold
+// on to their connections all the same.
+type downloadLimiter struct {
+	total     int
+	perAccess int
+
+	mu       sync.Mutex
+	inFlight int
+	accesses map[string]int
+}
+
+// newDownloadLimiter returns a downloadLimiter allowing total downloads at a
+// time, perAccess of them with the same access grant. Zero means no limit.
+func newDownloadLimiter(total, perAccess int) (*downloadLimiter, error) {
+	if total < 0 {
+		return nil, errs.New("concurrent downloads limit must not be negative, got %d", total)
+	}
+	if perAccess < 0 {
+		return nil, errs.New("concurrent downloads per access limit must not be negative, got %d", perAccess)
+	}
+	return &downloadLimiter{
+		total:     total,
+		perAccess: perAccess,
+		accesses:  make(map[string]int),
+	}, nil
+}
+
+// acquire returns an error if a download with serializedAccess would exceed
+// the limits. Otherwise the returned function must be called once the
+// download is served.
+func (limiter *downloadLimiter) acquire(serializedAccess string) (release func(), err error) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	if limiter.total > 0 && limiter.inFlight >= limiter.total {
+		mon.Event("downloads_rejected", monkit.NewSeriesTag("limit", "total"))
+		return nil, ErrTooManyDownloads.New("%d in flight", limiter.inFlight)
+	}
+	if limiter.perAccess > 0 {
+		if limiter.accesses[serializedAccess] >= limiter.perAccess {
+			mon.Event("downloads_rejected", monkit.NewSeriesTag("limit", "access"))
+			return nil, ErrTooManyDownloads.New("%d in flight with access", limiter.accesses[serializedAccess])
+		}
+		limiter.accesses[serializedAccess]++
+	}
+	limiter.inFlight++
+	mon.IntVal("downloads_in_flight").Observe(int64(limiter.inFlight))
+
+	return func() { limiter.release(serializedAccess) }, nil
+}
+
+func (limiter *downloadLimiter) release(serializedAccess string) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	if limiter.perAccess > 0 {
+		limiter.accesses[serializedAccess]--
+		if limiter.accesses[serializedAccess] == 0 {
+			delete(limiter.accesses, serializedAccess)
+		}
+	}
+	limiter.inFlight--
+	mon.IntVal("downloads_in_flight").Observe(int64(limiter.inFlight))
+}
@@ -0,0 +1,108 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package sharing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"storj.io/common/testcontext"
+	"storj.io/edge/pkg/linksharing/objectmap"
+)
+
+func TestNewDownloadLimiter(t *testing.T) {
+	_, err := newDownloadLimiter(0, 0)
+	require.NoError(t, err)
+	_, err = newDownloadLimiter(-1, 0)
+	require.Error(t, err)
+	_, err = newDownloadLimiter(0, -1)
+	require.Error(t, err)
+}
+
+func TestDownloadLimiterUnlimited(t *testing.T) {
+	limiter, err := newDownloadLimiter(0, 0)
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		_, err := limiter.acquire("access")
+		require.NoError(t, err)
+	}
+	require.Equal(t, 10, limiter.inFlight)
+	require.Empty(t, limiter.accesses)
+}
+
+func TestDownloadLimiterPerAccess(t *testing.T) {
+	limiter, err := newDownloadLimiter(0, 1)
+	require.NoError(t, err)
+
+	release, err := limiter.acquire("access1")
+	require.NoError(t, err)
+
+	// other accesses aren't limited by access1.
+	releaseOther, err := limiter.acquire("access2")
+	require.NoError(t, err)
+	releaseOther()
+
+	_, err = limiter.acquire("access1")
+	require.True(t, ErrTooManyDownloads.Has(err))
+
+	release()
+
+	release, err = limiter.acquire("access1")
+	require.NoError(t, err)
+	release()
+
+	require.Zero(t, limiter.inFlight)
+	require.Empty(t, limiter.accesses)
+}
+
+func TestDownloadLimiterTotal(t *testing.T) {
+	limiter, err := newDownloadLimiter(2, 2)
+	require.NoError(t, err)
+
+	release1, err := limiter.acquire("access1")
+	require.NoError(t, err)
+	release2, err := limiter.acquire("access2")
+	require.NoError(t, err)
+
+	_, err = limiter.acquire("access3")
+	require.True(t, ErrTooManyDownloads.Has(err))
+
+	release2()
+
+	release3, err := limiter.acquire("access3")
+	require.NoError(t, err)
+	release3()
+	release1()
+
+	require.Zero(t, limiter.inFlight)
+	require.Empty(t, limiter.accesses)
+}
+
+func TestPresentTooManyDownloads(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	handler, err := NewHandler(&zap.Logger{}, &objectmap.IPDB{}, nil, nil, Config{
+		ListPageLimit:                1,
+		URLBases:                     []string{"http://test.test"},
+		ConcurrentDownloadsPerAccess: 1,
+	})
+	require.NoError(t, err)
+
+	release, err := handler.downloads.acquire("access")
+	require.NoError(t, err)
+	defer release()
+
+	// the download is rejected before the project is used to download the
+	// object, which would fail with a nil project.
+	w := httptest.NewRecorder()
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://test.test/raw/access/bucket/key", nil)
+	require.NoError(t, err)
+	err = handler.presentWithProject(ctx, w, r, &parsedRequest{serializedAccess: "access", bucket: "bucket", realKey: "key"}, nil)
+	require.True(t, ErrTooManyDownloads.Has(err))
+}
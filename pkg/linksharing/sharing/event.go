@@ -13,7 +13,6 @@ import (
 	"gopkg.in/webhelp.v1/whroute"
 
 	"storj.io/common/http/requestid"
-	"storj.io/common/useragent"
 	"storj.io/edge/pkg/auth/authdb"
 	"storj.io/edge/pkg/httplog"
 	"storj.io/edge/pkg/trustedip"
@@ -30,14 +29,7 @@ func EventHandler(h http.Handler) http.Handler {
 			rw := w.(whmon.ResponseWriter)
 			start := time.Now()
 
-			agents, err := useragent.ParseEntries([]byte(r.UserAgent()))
-			product := "unknown"
-			if err == nil && len(agents) > 0 && agents[0].Product != "" {
-				product = agents[0].Product
-				if len(product) > 32 {
-					product = product[:32]
-				}
-			}
+			product := httplog.UserAgentProduct(r.UserAgent())
 
 			var macHead, encKeyHash, satelliteAddress, hostingRoot, publicProjectID string
 			var hostingTLS bool
@@ -4,8 +4,10 @@
 package sharing
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"html/template"
 	"io/fs"
 	"net"
 	"net/http"
@@ -22,9 +24,12 @@ import (
 	"storj.io/common/ranger/httpranger"
 	"storj.io/common/rpc/rpcpool"
 	"storj.io/common/version"
+	"storj.io/edge/internal/lrucache"
 	"storj.io/edge/pkg/authclient"
+	"storj.io/edge/pkg/bucketconfig"
 	"storj.io/edge/pkg/errdata"
 	"storj.io/edge/pkg/linksharing/objectmap"
+	"storj.io/edge/pkg/linksharing/objectranger"
 	"storj.io/edge/pkg/trustedip"
 	"storj.io/uplink"
 	"storj.io/uplink/private/transport"
@@ -41,6 +46,14 @@ var (
 
 	// ErrInvalidListPageLimit is an error returned when list page limit is not greater than zero.
 	ErrInvalidListPageLimit = errs.New("list page limit must be greater than zero")
+
+	// ErrRedirectLoop is an error returned when a redirect would send the
+	// client back to the URL it has just requested.
+	ErrRedirectLoop = errs.New("redirect loop detected")
+
+	// ErrInvalidRedirectHTTPSStatus is an error returned when the status of
+	// redirects to HTTPS isn't a redirect status.
+	ErrInvalidRedirectHTTPSStatus = errs.New("redirect https status must be 301, 302, 307 or 308")
 )
 
 // pageData is the type that is passed to the template rendering engine.
@@ -93,26 +106,52 @@ type Config struct {
 	DynamicAssets bool
 
 	// URLBases is the collection of potential base URLs of the link sharing
-	// handler. The first one in the list is used to construct URLs returned
-	// to clients. All should be a fully formed URL.
+	// handler. URLs returned to clients are constructed from the one whose
+	// host matches the host of the request, or else from the first one. All
+	// should be a fully formed URL.
 	URLBases []string
 
 	// TXTRecordTTL is the duration for which an entry in the txtRecordCache is valid.
 	TXTRecordTTL time.Duration
+	// TXTRecordNegativeTTL is the duration for which it's cached that a
+	// hostname has no TXT records because its domain doesn't exist.
+	TXTRecordNegativeTTL time.Duration
+
+	// MapCacheExpiration is the duration for which the locations of the
+	// nodes storing an object are cached for rendering its map.
+	MapCacheExpiration time.Duration
+	// MapCacheCapacity is the maximum number of cached sets of locations.
+	// Zero disables the cache.
+	MapCacheCapacity int
 
 	// AuthServiceConfig contains configuration required to use the auth service to resolve
 	// access key ids into access grants.
 	AuthServiceConfig authclient.Config
 
+	// BucketConfigs configures where the website configurations of buckets
+	// set with PutBucketWebsite are read from.
+	BucketConfigs bucketconfig.Config
+
 	// DNS Server address, for TXT record lookup
 	DNSServer string
 
 	// RedirectHTTPS enables redirection to https://.
 	RedirectHTTPS bool
 
+	// RedirectHTTPSStatus is the status of redirects to https://: 301, 302,
+	// 307 or 308. Zero means 308. Clients may change the method of requests
+	// redirected with 301 and 302 to GET, so 308 and 307 respectively are
+	// used instead for requests with other methods than GET and HEAD.
+	RedirectHTTPSStatus int
+
 	// LandingRedirectTarget is the url to redirect empty requests to.
 	LandingRedirectTarget string
 
+	// LandingTemplate is the path to an HTML template rendered for empty
+	// requests instead of redirecting them to LandingRedirectTarget. It's
+	// executed with the requested Host.
+	LandingTemplate string
+
 	// uplink Config settings
 	Uplink *uplink.Config
 
@@ -129,6 +168,11 @@ type Config struct {
 	// request, IP from headers).
 	ClientTrustedIPsList []string
 
+	// ClientTrustedHops is the number of proxies, including the one connecting
+	// to the service, whose `X-Forwarded-For` entries are skipped from the
+	// right to find the client IP. Zero uses the first entry.
+	ClientTrustedHops int
+
 	// UseClientIPHeaders indicates that the HTTP headers `Forwarded`,
 	// `X-Forwarded-Ip`, and `X-Real-Ip` (in this order) are used to get the
 	// client IP before falling back of getting from the client request.
@@ -145,8 +189,34 @@ type Config struct {
 	// text/plain for standard (non-hosting) requests.
 	StandardViewsHTML bool
 
+	// InlineContentTypes are content types rendered inline for standard
+	// (non-hosting) requests in addition to the built-in safe ones, e.g.
+	// image/webp or video/*.
+	InlineContentTypes []string
+	// AttachmentContentTypes are content types always downloaded as
+	// attachments for standard (non-hosting) requests, even if
+	// StandardRendersContent is enabled, e.g. text/html or
+	// application/javascript. It takes precedence over InlineContentTypes.
+	AttachmentContentTypes []string
+	// ContentTypeOverrides map file extensions to the content types served
+	// for objects stored without one, as entries like .wasm=application/wasm.
+	// They take precedence over the built-in extension mapping.
+	ContentTypeOverrides []string
+	// SniffContentType makes objects whose content type is known neither
+	// from their metadata nor from their extension be served with the type
+	// detected from their first 512 bytes.
+	SniffContentType bool
+
 	// Maximum number of paths to list on a single page.
 	ListPageLimit int
+	// ListingDisabled makes requests of prefixes fail with 404 Not Found
+	// instead of listing their objects, so that shared prefixes don't reveal
+	// what they contain to those who don't know the object keys.
+	ListingDisabled bool
+	// DisableMap turns off the map of the nodes storing an object, and
+	// with it geolocation lookups, even if a geolocation database is
+	// configured. Requests of the map fail with 404 Not Found.
+	DisableMap bool
 
 	// DownloadPrefixEnabled allows enabling/disabling the ability to download a prefix as a zip or tar file.
 	DownloadPrefixEnabled bool
@@ -154,11 +224,57 @@ type Config struct {
 	// A file indicating that the downloaded prefix is incomplete is included in the zip file if exceeded.
 	DownloadZipLimit int
 
+	// ConcurrentDownloads is the maximum number of downloads of object
+	// contents and prefixes served at a time. Further downloads fail with
+	// 503 Service Unavailable and a Retry-After header. Zero means no limit.
+	ConcurrentDownloads int
+	// ConcurrentDownloadsPerAccess is the maximum number of downloads served
+	// at a time with the same access grant or access key. Zero means no
+	// limit.
+	ConcurrentDownloadsPerAccess int
+
 	// BlockedPaths are requests that will return unauthorized errors. Each entry in this slice
 	// is of the host and the URI on that host concatenated. N.B.: if the special
 	// path "debug" is added, then allowed paths will be logged to debug level
 	// output. Paths that start with "r:" are treated as regular expressions.
 	BlockedPaths []string
+
+	// StrictQueryParams makes standard (non-hosting) requests with query
+	// parameters other than the known ones and AllowedQueryParams fail with
+	// 400 Bad Request instead of ignoring them.
+	StrictQueryParams bool
+	// AllowedQueryParams are additional query parameters accepted in strict
+	// mode, e.g. analytics parameters added by third parties.
+	AllowedQueryParams []string
+
+	// DownloadRetry configures retrying object downloads that fail with
+	// transient errors.
+	DownloadRetry objectranger.RetryConfig
+
+	// IndexDocument is the name of the object served for requests of
+	// prefixes, e.g. /docs/, if it exists in the prefix. Empty uses
+	// index.html.
+	IndexDocument string
+
+	// ErrorDocument is the key, relative to the root of a hosted site, of the
+	// page served with 404 for hosting requests of missing objects. Empty
+	// uses 404.html.
+	ErrorDocument string
+
+	// NotFoundTemplate is the path to an HTML template rendered for hosting
+	// requests of missing objects when the site has no error document of its
+	// own.
+	// It's executed with the requested Path and Bucket.
+	NotFoundTemplate string
+
+	// Compression enables compressing text responses with gzip for clients
+	// that accept it.
+	Compression bool
+
+	// SignedLinksKey is the secret key standard (non-hosting) links signed
+	// with SignURL are validated with. If it's set, standard requests without
+	// a valid signature fail with 403 Forbidden. Empty disables signed links.
+	SignedLinksKey string
 }
 
 // ConnectionPoolConfig is a config struct for configuring RPC connection pool options.
@@ -177,20 +293,39 @@ type Handler struct {
 	urlBases               []*url.URL
 	templates              *Templates
 	mapper                 *objectmap.IPDB
+	mapCache               *lrucache.ExpiringLRUOf[[]location]
+	bucketConfigs          *bucketconfig.Store
 	txtRecords             *TXTRecords
 	authClient             *authclient.AuthClient
 	redirectHTTPS          bool
+	redirectHTTPSStatus    int
 	landingRedirect        string
+	landingTemplate        *template.Template
 	uplink                 *uplink.Config
 	trustedClientIPsList   trustedip.List
 	standardRendersContent bool
 	standardViewsHTML      bool
+	inlineContentTypes     contentTypeList
+	attachmentContentTypes contentTypeList
+	contentTypeOverrides   map[string]string
+	sniffContentType       bool
 	archiveRanger          func(ctx context.Context, project *uplink.Project, bucket, key, path string, canReturnGzip bool) (_ ranger.Ranger, isGzip bool, _ error)
 	listPageLimit          int
+	listingDisabled        bool
+	mapDisabled            bool
 	downloadPrefixEnabled  bool
 	downloadZipLimit       int
+	downloads              *downloadLimiter
 	blockedPaths           map[string]bool
 	blockedRegexes         []*regexp.Regexp
+	strictQueryParams      bool
+	allowedQueryParams     map[string]struct{}
+	downloadRetry          objectranger.RetryConfig
+	indexDocument          string
+	errorDocument          string
+	notFoundTemplate       *template.Template
+	compression            bool
+	signedLinksKey         []byte
 }
 
 // NewHandler creates a new link sharing HTTP handler.
@@ -211,6 +346,15 @@ func NewHandler(log *zap.Logger, mapper *objectmap.IPDB, txtRecords *TXTRecords,
 		return nil, errors.New("requires at least one url base")
 	}
 
+	redirectHTTPSStatus := config.RedirectHTTPSStatus
+	switch redirectHTTPSStatus {
+	case 0:
+		redirectHTTPSStatus = http.StatusPermanentRedirect
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return nil, ErrInvalidRedirectHTTPSStatus
+	}
+
 	var templates *Templates
 	if config.Assets != nil {
 		fs, err := fs.Sub(config.Assets, "templates")
@@ -271,6 +415,7 @@ func NewHandler(log *zap.Logger, mapper *objectmap.IPDB, txtRecords *TXTRecords,
 	} else {
 		trustedClientIPs = trustedip.NewListUntrustAll()
 	}
+	trustedClientIPs = trustedClientIPs.WithTrustedHops(config.ClientTrustedHops)
 
 	if authClient == nil {
 		authClient = authclient.New(config.AuthServiceConfig)
@@ -281,7 +426,7 @@ func NewHandler(log *zap.Logger, mapper *objectmap.IPDB, txtRecords *TXTRecords,
 		if err != nil {
 			return nil, err
 		}
-		txtRecords = NewTXTRecords(config.TXTRecordTTL, dns, authClient)
+		txtRecords = NewTXTRecords(config.TXTRecordTTL, config.TXTRecordNegativeTTL, dns, authClient)
 	}
 
 	blockedPaths := make(map[string]bool, len(config.BlockedPaths))
@@ -300,25 +445,100 @@ func NewHandler(log *zap.Logger, mapper *objectmap.IPDB, txtRecords *TXTRecords,
 		}
 	}
 
+	contentTypeOverrides, err := parseContentTypeOverrides(config.ContentTypeOverrides)
+	if err != nil {
+		return nil, err
+	}
+
+	downloads, err := newDownloadLimiter(config.ConcurrentDownloads, config.ConcurrentDownloadsPerAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	allowedQueryParams := make(map[string]struct{}, len(config.AllowedQueryParams))
+	for _, name := range config.AllowedQueryParams {
+		if name != "" {
+			allowedQueryParams[name] = struct{}{}
+		}
+	}
+
+	indexDocument := config.IndexDocument
+	if indexDocument == "" {
+		indexDocument = "index.html"
+	}
+	if strings.Contains(indexDocument, "/") {
+		return nil, errs.New("index document %q must not contain slashes", indexDocument)
+	}
+	errorDocument := strings.TrimPrefix(config.ErrorDocument, "/")
+	if errorDocument == "" {
+		errorDocument = "404.html"
+	}
+
+	var landingTemplate *template.Template
+	if config.LandingTemplate != "" {
+		landingTemplate, err = template.ParseFiles(config.LandingTemplate)
+		if err != nil {
+			return nil, errs.New("parsing landing template: %w", err)
+		}
+	}
+
+	var notFoundTemplate *template.Template
+	if config.NotFoundTemplate != "" {
+		notFoundTemplate, err = template.ParseFiles(config.NotFoundTemplate)
+		if err != nil {
+			return nil, errs.New("parsing not found template: %w", err)
+		}
+	}
+
+	mapCache := lrucache.NewOf[[]location](lrucache.Options{
+		Expiration: config.MapCacheExpiration,
+		Capacity:   config.MapCacheCapacity,
+		Name:       "map_locations",
+	})
+
+	bucketConfigs, err := bucketconfig.Open(config.BucketConfigs, *uplinkConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Handler{
 		log:                    log,
 		urlBases:               bases,
 		templates:              templates,
 		mapper:                 mapper,
+		mapCache:               mapCache,
+		bucketConfigs:          bucketConfigs,
 		txtRecords:             txtRecords,
 		authClient:             authClient,
 		landingRedirect:        config.LandingRedirectTarget,
+		landingTemplate:        landingTemplate,
 		redirectHTTPS:          config.RedirectHTTPS,
+		redirectHTTPSStatus:    redirectHTTPSStatus,
 		uplink:                 uplinkConfig,
 		trustedClientIPsList:   trustedClientIPs,
 		standardRendersContent: config.StandardRendersContent,
 		standardViewsHTML:      config.StandardViewsHTML,
+		inlineContentTypes:     newContentTypeList(config.InlineContentTypes),
+		attachmentContentTypes: newContentTypeList(config.AttachmentContentTypes),
+		contentTypeOverrides:   contentTypeOverrides,
+		sniffContentType:       config.SniffContentType,
 		archiveRanger:          defaultArchiveRanger,
 		listPageLimit:          config.ListPageLimit,
+		listingDisabled:        config.ListingDisabled,
+		mapDisabled:            config.DisableMap,
 		downloadPrefixEnabled:  config.DownloadPrefixEnabled,
 		downloadZipLimit:       config.DownloadZipLimit,
+		downloads:              downloads,
 		blockedPaths:           blockedPaths,
 		blockedRegexes:         blockedRegexes,
+		strictQueryParams:      config.StrictQueryParams,
+		allowedQueryParams:     allowedQueryParams,
+		downloadRetry:          config.DownloadRetry,
+		indexDocument:          indexDocument,
+		errorDocument:          errorDocument,
+		notFoundTemplate:       notFoundTemplate,
+		compression:            config.Compression,
+		signedLinksKey:         []byte(config.SignedLinksKey),
 	}, nil
 }
 
@@ -365,6 +585,13 @@ func (handler *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case errors.Is(handlerErr, uplink.ErrTooManyRequests):
 		http.Error(w, "429 Too Many Requests", http.StatusTooManyRequests)
 		return
+	case ErrTooManyDownloads.Has(handlerErr):
+		w.Header().Set("Retry-After", downloadsRetryAfter)
+		http.Error(w, "503 Service Unavailable", http.StatusServiceUnavailable)
+		return
+	case errors.Is(handlerErr, ErrRedirectLoop):
+		status = http.StatusLoopDetected
+		message = "Redirect loop detected. Please check the server configuration."
 	case errors.Is(handlerErr, context.Canceled) && errors.Is(ctx.Err(), context.Canceled):
 		status = errdata.HTTPStatusClientClosedRequest
 		message = "Client closed request."
@@ -419,16 +646,16 @@ func (handler *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	delete(w.Header(), "Content-Disposition")
 	w.WriteHeader(status)
 	if !skipRendering {
-		handler.renderTemplate(w, "error.html", pageData{Data: message, Title: "Error"})
+		handler.renderTemplate(w, r, "error.html", pageData{Data: message, Title: "Error"})
 	}
 }
 
-func (handler *Handler) renderTemplate(w http.ResponseWriter, template string, data pageData) {
+func (handler *Handler) renderTemplate(w http.ResponseWriter, r *http.Request, template string, data pageData) {
 	if handler.templates == nil {
 		handler.log.Error("template filesystem not initialized")
 		return
 	}
-	data.Base = strings.TrimSuffix(handler.urlBases[0].String(), "/")
+	data.Base = strings.TrimSuffix(handler.urlBase(r).String(), "/")
 	data.VersionHash = version.Build.CommitHash
 	err := handler.templates.ExecuteTemplate(w, template, data)
 	if err != nil {
@@ -464,17 +691,127 @@ func (handler *Handler) serveHTTP(ctx context.Context, w http.ResponseWriter, r
 
 	switch {
 	case handler.redirectHTTPS && r.TLS == nil:
-		target := url.URL{Scheme: "https", Host: r.Host, Path: r.URL.Path, RawPath: r.URL.RawPath, RawQuery: r.URL.RawQuery}
-		http.Redirect(w, r, target.String(), http.StatusPermanentRedirect)
-		return nil
+		target := requestURL(r)
+		target.Scheme = "https"
+		return handler.redirect(w, r, target.String(), handler.httpsRedirectStatus(r))
+	case handler.landingTemplate != nil && (r.URL.Path == "" || r.URL.Path == "/"):
+		return handler.renderLanding(w, r)
 	case handler.landingRedirect != "" && (r.URL.Path == "" || r.URL.Path == "/"):
-		http.Redirect(w, r, handler.landingRedirect, http.StatusSeeOther)
-		return nil
+		return handler.redirect(w, r, handler.landingRedirect, http.StatusSeeOther)
 	default:
 		return handler.handleStandard(ctx, w, r)
 	}
 }
 
+// renderLanding renders the configured landing template for an empty request.
+func (handler *Handler) renderLanding(w http.ResponseWriter, r *http.Request) error {
+	var buf bytes.Buffer
+	err := handler.landingTemplate.Execute(&buf, struct {
+		Host string
+	}{
+		Host: r.Host,
+	})
+	if err != nil {
+		return errdata.WithAction(err, "render landing template")
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// redirect replies to the request with a redirect to target. It refuses to do
+// so and returns ErrRedirectLoop instead if target resolves to the URL that
+// was requested, as following such a redirect would never end.
+func (handler *Handler) redirect(w http.ResponseWriter, r *http.Request, target string, code int) error {
+	if isRedirectLoop(r, target) {
+		return errdata.WithAction(ErrRedirectLoop, "redirect")
+	}
+	http.Redirect(w, r, target, code)
+	return nil
+}
+
+// httpsRedirectStatus returns the status of the redirect of r to https://.
+// Clients may repeat requests redirected with 301 and 302 as GET requests, so
+// for other methods than GET and HEAD those are replaced by their
+// method-preserving counterparts, 308 and 307.
+func (handler *Handler) httpsRedirectStatus(r *http.Request) int {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return handler.redirectHTTPSStatus
+	}
+	switch handler.redirectHTTPSStatus {
+	case http.StatusMovedPermanently:
+		return http.StatusPermanentRedirect
+	case http.StatusFound:
+		return http.StatusTemporaryRedirect
+	default:
+		return handler.redirectHTTPSStatus
+	}
+}
+
+// requestURL returns the absolute URL of the request as seen by the client.
+func requestURL(r *http.Request) *url.URL {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return &url.URL{Scheme: scheme, Host: r.Host, Path: r.URL.Path, RawPath: r.URL.RawPath, RawQuery: r.URL.RawQuery}
+}
+
+// isRedirectLoop returns whether target, resolved against the URL of the
+// request, is the same URL as the one requested.
+func isRedirectLoop(r *http.Request, target string) bool {
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	current := requestURL(r)
+	resolved := current.ResolveReference(u)
+
+	sameHost, err := compareHosts(resolved.Host, current.Host)
+	if err != nil {
+		return false
+	}
+
+	return resolved.Scheme == current.Scheme &&
+		sameHost &&
+		resolved.EscapedPath() == current.EscapedPath() &&
+		resolved.RawQuery == current.RawQuery
+}
+
+// urlBase returns the URL base that URLs in the response to r are
+// constructed from. It's the first one whose host matches the host of the
+// request, preferring one whose scheme matches too, so that links work behind
+// each of the configured domains. It's the first URL base if none matches.
+func (handler *Handler) urlBase(r *http.Request) *url.URL {
+	scheme := requestURL(r).Scheme
+
+	var hostMatch *url.URL
+	for _, base := range handler.urlBases {
+		if ours, err := compareHosts(r.Host, base.Host); err != nil || !ours {
+			continue
+		}
+		if base.Scheme == scheme {
+			return base
+		}
+		if hostMatch == nil {
+			hostMatch = base
+		}
+	}
+	if hostMatch != nil {
+		return hostMatch
+	}
+	return handler.urlBases[0]
+}
+
+// IsServiceHost returns whether host is the host of one of the URL bases, as
+// opposed to the host of a hosted website.
+func (handler *Handler) IsServiceHost(host string) bool {
+	ours, err := isDomainOurs(host, handler.urlBases)
+	return err == nil && ours
+}
+
 func isDomainOurs(host string, bases []*url.URL) (bool, error) {
 	for _, base := range bases {
 		ours, err := compareHosts(host, base.Host)
@@ -4,9 +4,18 @@
 package sharing
 
 import (
+	"crypto/tls"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"storj.io/common/testcontext"
 )
 
 func TestCompareHosts(t *testing.T) {
@@ -35,3 +44,224 @@ func TestCompareHosts(t *testing.T) {
 		assert.False(t, result)
 	}
 }
+
+func TestRedirectLoop(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		redirectHTTPS  bool
+		landing        string
+		url            string
+		tls            bool
+		expectedStatus int
+		expectedTarget string
+		expectedErr    error
+	}{
+		{
+			desc:           "default landing redirect",
+			landing:        "https://www.storj.io/",
+			url:            "http://test.test/",
+			expectedStatus: http.StatusSeeOther,
+			expectedTarget: "https://www.storj.io/",
+		},
+		{
+			desc:        "landing redirect to itself",
+			landing:     "http://test.test/",
+			url:         "http://test.test/",
+			expectedErr: ErrRedirectLoop,
+		},
+		{
+			desc:        "relative landing redirect to itself",
+			landing:     "/",
+			url:         "http://test.test/",
+			expectedErr: ErrRedirectLoop,
+		},
+		{
+			desc:           "landing redirect to itself over a different scheme",
+			landing:        "https://test.test/",
+			url:            "http://test.test/",
+			expectedStatus: http.StatusSeeOther,
+			expectedTarget: "https://test.test/",
+		},
+		{
+			desc:           "https redirect",
+			redirectHTTPS:  true,
+			landing:        "https://test.test/",
+			url:            "http://test.test/?q=1",
+			expectedStatus: http.StatusPermanentRedirect,
+			expectedTarget: "https://test.test/?q=1",
+		},
+		{
+			desc:          "https redirect followed by landing redirect to itself",
+			redirectHTTPS: true,
+			landing:       "https://test.test/",
+			url:           "https://test.test/",
+			tls:           true,
+			expectedErr:   ErrRedirectLoop,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			handler, err := NewHandler(zap.NewNop(), nil, nil, nil, Config{
+				ListPageLimit:         1,
+				URLBases:              []string{"http://test.test"},
+				RedirectHTTPS:         tc.redirectHTTPS,
+				LandingRedirectTarget: tc.landing,
+			})
+			require.NoError(t, err)
+
+			ctx := testcontext.New(t)
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, tc.url, nil).WithContext(ctx)
+			if !tc.tls {
+				r.TLS = nil
+			} else if r.TLS == nil {
+				r.TLS = &tls.ConnectionState{}
+			}
+
+			err = handler.serveHTTP(ctx, w, r)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedTarget, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestRedirectHTTPSStatus(t *testing.T) {
+	_, err := NewHandler(zap.NewNop(), nil, nil, nil, Config{
+		ListPageLimit:       1,
+		URLBases:            []string{"http://test.test"},
+		RedirectHTTPSStatus: http.StatusOK,
+	})
+	require.ErrorIs(t, err, ErrInvalidRedirectHTTPSStatus)
+
+	testCases := []struct {
+		status   int
+		method   string
+		expected int
+	}{
+		{status: 0, method: http.MethodGet, expected: http.StatusPermanentRedirect},
+		{status: 0, method: http.MethodPost, expected: http.StatusPermanentRedirect},
+		{status: http.StatusMovedPermanently, method: http.MethodGet, expected: http.StatusMovedPermanently},
+		{status: http.StatusMovedPermanently, method: http.MethodHead, expected: http.StatusMovedPermanently},
+		{status: http.StatusMovedPermanently, method: http.MethodPost, expected: http.StatusPermanentRedirect},
+		{status: http.StatusFound, method: http.MethodGet, expected: http.StatusFound},
+		{status: http.StatusFound, method: http.MethodHead, expected: http.StatusFound},
+		{status: http.StatusFound, method: http.MethodPost, expected: http.StatusTemporaryRedirect},
+		{status: http.StatusTemporaryRedirect, method: http.MethodGet, expected: http.StatusTemporaryRedirect},
+		{status: http.StatusTemporaryRedirect, method: http.MethodPost, expected: http.StatusTemporaryRedirect},
+		{status: http.StatusPermanentRedirect, method: http.MethodGet, expected: http.StatusPermanentRedirect},
+		{status: http.StatusPermanentRedirect, method: http.MethodPost, expected: http.StatusPermanentRedirect},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(fmt.Sprintf("%d %s", tc.status, tc.method), func(t *testing.T) {
+			handler, err := NewHandler(zap.NewNop(), nil, nil, nil, Config{
+				ListPageLimit:       1,
+				URLBases:            []string{"http://test.test"},
+				RedirectHTTPS:       true,
+				RedirectHTTPSStatus: tc.status,
+			})
+			require.NoError(t, err)
+
+			ctx := testcontext.New(t)
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "http://test.test/s/access/bucket?q=1", nil).WithContext(ctx)
+
+			require.NoError(t, handler.serveHTTP(ctx, w, r))
+			assert.Equal(t, tc.expected, w.Code)
+			assert.Equal(t, "https://test.test/s/access/bucket?q=1", w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestIsRedirectLoop(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "http://test.test/bucket?wrap=1", nil)
+
+	for target, expected := range map[string]bool{
+		"http://test.test/bucket?wrap=1":      true,
+		"http://test.test:80/bucket?wrap=1":   true,
+		"/bucket?wrap=1":                      true,
+		"bucket?wrap=1":                       true,
+		"/bucket/?wrap=1":                     false,
+		"/bucket":                             false,
+		"https://test.test/bucket?wrap=1":     false,
+		"http://other.test/bucket?wrap=1":     false,
+		"http://test.test/bucket/../bucket/x": false,
+	} {
+		assert.Equal(t, expected, isRedirectLoop(r, target), target)
+	}
+}
+
+func TestURLBase(t *testing.T) {
+	handler, err := NewHandler(zap.NewNop(), nil, nil, nil, Config{
+		ListPageLimit: 1,
+		URLBases:      []string{"https://link.test", "http://other.test:8080", "https://other.test", "http://plain.test/prefix"},
+	})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		url      string
+		tls      bool
+		expected string
+	}{
+		{url: "http://link.test/bucket", tls: true, expected: "https://link.test"},
+		{url: "http://other.test/bucket", tls: true, expected: "https://other.test"},
+		{url: "http://other.test/bucket", expected: "http://other.test:8080"},
+		{url: "http://other.test:8080/bucket", expected: "http://other.test:8080"},
+		{url: "http://plain.test/bucket", tls: true, expected: "http://plain.test/prefix"},
+		{url: "http://unknown.test/bucket", expected: "https://link.test"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+		if tc.tls {
+			r.TLS = &tls.ConnectionState{}
+		}
+		assert.Equal(t, tc.expected, handler.urlBase(r).String(), tc.url)
+	}
+}
+
+func TestRenderLanding(t *testing.T) {
+	tmpl, err := template.New("landing").Parse(`<h1>Welcome to {{.Host}}</h1>`)
+	require.NoError(t, err)
+
+	handler := &Handler{landingTemplate: tmpl}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://link.example.test/", nil)
+	require.NoError(t, handler.renderLanding(rec, req))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	require.Equal(t, "<h1>Welcome to link.example.test</h1>", rec.Body.String())
+}
+
+func TestNewHandlerDocuments(t *testing.T) {
+	handler, err := NewHandler(zap.NewNop(), nil, nil, nil, Config{
+		ListPageLimit: 1,
+		URLBases:      []string{"http://test.test"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "index.html", handler.indexDocument)
+	require.Equal(t, "404.html", handler.errorDocument)
+
+	handler, err = NewHandler(zap.NewNop(), nil, nil, nil, Config{
+		ListPageLimit: 1,
+		URLBases:      []string{"http://test.test"},
+		IndexDocument: "default.htm",
+		ErrorDocument: "/errors/missing.html",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "default.htm", handler.indexDocument)
+	require.Equal(t, "errors/missing.html", handler.errorDocument)
+
+	_, err = NewHandler(zap.NewNop(), nil, nil, nil, Config{
+		ListPageLimit: 1,
+		URLBases:      []string{"http://test.test"},
+		IndexDocument: "docs/index.html",
+	})
+	require.Error(t, err)
+}
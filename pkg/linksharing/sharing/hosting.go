@@ -4,16 +4,23 @@
 package sharing
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"net"
 	"net/http"
 	"net/url"
+	"path"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
+	"storj.io/common/ranger"
+	"storj.io/common/ranger/httpranger"
 	"storj.io/common/sync2"
 	"storj.io/edge/pkg/errdata"
+	"storj.io/edge/pkg/linksharing/objectranger"
 	"storj.io/uplink"
 )
 
@@ -26,13 +33,27 @@ func (handler *Handler) handleHostingService(ctx context.Context, w http.Respons
 		return creds.err
 	}
 
+	// Redirect custom domains with a `storj-canonical-host` TXT record to that
+	// host, straight to HTTPS if the one below would apply too
+	if target := canonicalHostRedirect(r, creds.hostingHost, creds.hostingCanonicalHost, handler.redirectHTTPS && creds.hostingTLS); target != nil {
+		return handler.redirect(w, r, target.String(), http.StatusMovedPermanently)
+	}
+
 	// Redirect to HTTPS only custom domains with `storj-tls:true` TXT record
 	if handler.redirectHTTPS && r.TLS == nil && creds.hostingTLS {
-		target := url.URL{Scheme: "https", Host: r.Host, Path: r.URL.Path, RawPath: r.URL.RawPath, RawQuery: r.URL.RawQuery}
-		http.Redirect(w, r, target.String(), http.StatusPermanentRedirect)
-		return nil
+		target := requestURL(r)
+		target.Scheme = "https"
+		return handler.redirect(w, r, target.String(), handler.httpsRedirectStatus(r))
 	}
 
+	// hosted sites serve object contents, including the default object and
+	// the error document, so the slot is held for the whole request.
+	release, err := handler.downloads.acquire(creds.serializedAccess)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	bucket, key := determineBucketAndObjectKey(creds.hostingRoot, r.URL.Path)
 
 	project, err := handler.uplink.OpenProject(ctx, creds.access)
@@ -45,11 +66,36 @@ func (handler *Handler) handleHostingService(ctx context.Context, w http.Respons
 		}
 	}()
 
+	website, err := handler.websiteConfig(ctx, creds, bucket)
+	if err != nil {
+		return err
+	}
+
+	indexDocument, errorDocument := handler.indexDocument, handler.errorDocument
+	if website != nil {
+		if website.RedirectAllRequestsTo != nil {
+			return handler.redirect(w, r, website.RedirectAllRequestsTo.Target(requestURL(r)), http.StatusMovedPermanently)
+		}
+		indexDocument = website.IndexDocument.Suffix
+		if website.ErrorDocument != nil {
+			errorDocument = website.ErrorDocument.Key
+		}
+	}
+
 	visibleKey := strings.TrimPrefix(r.URL.Path, "/")
+
+	if website != nil {
+		if rule := website.Route(visibleKey, 0); rule != nil {
+			target, status := rule.Target(requestURL(r), visibleKey)
+			return handler.redirect(w, r, target, status)
+		}
+	}
+
 	if visibleKey == "" {
 		// special case: if someone is looking for http://sub.domain.tld/,
-		// explicitly assume they shared a prefix and are looking for index.html
-		key += "index.html"
+		// explicitly assume they shared a prefix and are looking for the index
+		// document.
+		key += indexDocument
 	}
 
 	err = handler.presentWithProject(ctx, w, r, &parsedRequest{
@@ -64,6 +110,8 @@ func (handler *Handler) handleHostingService(ctx context.Context, w http.Respons
 		downloadDefault:  false,
 		hosting:          true,
 		hostingTLS:       creds.hostingTLS,
+		precompressed:    creds.hostingPrecompressed,
+		indexDocument:    indexDocument,
 	}, project)
 
 	// if the error is anything other than ObjectNotFound, return to normal
@@ -72,11 +120,30 @@ func (handler *Handler) handleHostingService(ctx context.Context, w http.Respons
 		return err
 	}
 
-	// in ObjectNotFound, let the user provide a custom 404 page
+	if website != nil {
+		if rule := website.Route(visibleKey, http.StatusNotFound); rule != nil {
+			target, status := rule.Target(requestURL(r), visibleKey)
+			return handler.redirect(w, r, target, status)
+		}
+	}
 
-	bucket, key = determineBucketAndObjectKey(creds.hostingRoot, "/404.html")
+	// in ObjectNotFound, serve the site's default object if it has one
+	if creds.hostingDefaultObject != "" {
+		bucket, key = determineBucketAndObjectKey(creds.hostingRoot, "/"+creds.hostingDefaultObject)
+		err = handler.serveDefaultObject(ctx, w, r, project, bucket, key, creds.hostingDefaultStatus)
+		if !errors.Is(err, uplink.ErrObjectNotFound) {
+			return err
+		}
+	}
+
+	// otherwise let the user provide a custom 404 page
+
+	bucket, key = determineBucketAndObjectKey(creds.hostingRoot, "/"+errorDocument)
 	download, err := project.DownloadObject(ctx, bucket, key, nil)
 	if err != nil {
+		if errors.Is(err, uplink.ErrObjectNotFound) && handler.notFoundTemplate != nil {
+			return handler.renderNotFound(w, r.URL.Path, bucket)
+		}
 		// if this returns uplink.ErrObjectNotFound, then, that's still
 		// the right error, and we should return it and return our normal
 		// 404 page, so this is fine to just pass through.
@@ -96,6 +163,36 @@ func (handler *Handler) handleHostingService(ctx context.Context, w http.Respons
 	return nil
 }
 
+// canonicalHostRedirect returns the URL that a request that arrived on host
+// should be redirected to so that it arrives on canonicalHost, or nil if it
+// already did or there's no canonical host. The path, query and port are kept,
+// unless canonicalHost has a port of its own, and the scheme is upgraded to
+// HTTPS if upgradeHTTPS is set.
+func canonicalHostRedirect(r *http.Request, host, canonicalHost string, upgradeHTTPS bool) *url.URL {
+	if canonicalHost == "" {
+		return nil
+	}
+	canonicalHostname := canonicalHost
+	if h, _, err := net.SplitHostPort(canonicalHost); err == nil {
+		canonicalHostname = h
+	}
+	if strings.EqualFold(host, canonicalHostname) {
+		return nil
+	}
+
+	target := requestURL(r)
+	target.Host = canonicalHost
+	if canonicalHostname == canonicalHost {
+		if _, port, err := net.SplitHostPort(r.Host); err == nil {
+			target.Host = net.JoinHostPort(canonicalHost, port)
+		}
+	}
+	if upgradeHTTPS {
+		target.Scheme = "https"
+	}
+	return target
+}
+
 // determineBucketAndObjectKey is a helper function to parse storj_root and the url into the bucket and object key.
 // For example, we have http://mydomain.com/prefix2/index.html with storj_root:bucket1/prefix1/
 // The root path will be [bucket1, prefix1/]. Our bucket is named bucket1.
@@ -116,3 +213,87 @@ func determineBucketAndObjectKey(root, urlPath string) (bucket, key string) {
 	}
 	return bucket, prefix + strings.TrimPrefix(urlPath, "/")
 }
+
+// serveDefaultObject serves the object under key with the given status
+// instead of a missing object. The object's own metadata determines the
+// content type and the other headers. Range and conditional requests are
+// served like they are for the object itself if the status is 200 OK.
+func (handler *Handler) serveDefaultObject(ctx context.Context, w http.ResponseWriter, r *http.Request, project *uplink.Project, bucket, key string, status int) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	o, err := project.StatObject(ctx, bucket, key)
+	if err != nil {
+		return errdata.WithAction(err, "stat default object")
+	}
+
+	handler.setHeaders(w, r, o.Custom, true, path.Base(o.Key), nil)
+	w.Header().Set("ETag", objectETag(o))
+
+	err = serveContentWithStatus(ctx, w, r, o.Key, o.System.Created, objectranger.New(project, o, nil, httpranger.HTTPRange{}, bucket, handler.downloadRetry), status)
+	if err != nil {
+		return errdata.WithAction(err, "serve default object")
+	}
+	return nil
+}
+
+// serveContentWithStatus serves rr like httpranger.ServeContent does, but
+// with status instead of 200 OK. Unless status is 200 OK, Range and
+// conditional headers are ignored, so that e.g. a missing object isn't
+// answered with 206 Partial Content or 304 Not Modified.
+func serveContentWithStatus(ctx context.Context, w http.ResponseWriter, r *http.Request, name string, modtime time.Time, rr ranger.Ranger, status int) error {
+	if status != http.StatusOK {
+		r = r.Clone(ctx)
+		for _, header := range []string{"Range", "If-Range", "If-None-Match", "If-Modified-Since"} {
+			r.Header.Del(header)
+		}
+	}
+	return httpranger.ServeContent(ctx, &statusResponseWriter{ResponseWriter: w, status: status}, r, name, modtime, rr)
+}
+
+// statusResponseWriter replaces 200 OK with status.
+type statusResponseWriter struct {
+	http.ResponseWriter
+
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	if status == http.StatusOK {
+		status = w.status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// renderNotFound renders the configured not found template for a hosting
+// request of a missing object.
+func (handler *Handler) renderNotFound(w http.ResponseWriter, path, bucket string) error {
+	var buf bytes.Buffer
+	err := handler.notFoundTemplate.Execute(&buf, struct {
+		Path   string
+		Bucket string
+	}{
+		Path:   path,
+		Bucket: bucket,
+	})
+	if err != nil {
+		return errdata.WithAction(err, "render not found template")
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusNotFound)
+	_, err = buf.WriteTo(w)
+	return err
+}
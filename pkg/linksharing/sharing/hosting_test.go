@@ -5,9 +5,17 @@ package sharing
 
 import (
 	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/ranger"
+	"storj.io/common/testcontext"
 )
 
 func TestDetermineBucketAndObjectKey(t *testing.T) {
@@ -78,3 +86,99 @@ func TestDetermineBucketAndObjectKey(t *testing.T) {
 		assert.Equal(t, actualKey, test.key, fmt.Sprintf("%d: %s", idx, test.name))
 	}
 }
+
+func TestRenderNotFound(t *testing.T) {
+	tmpl, err := template.New("404").Parse(`<h1>{{.Path}} not found in {{.Bucket}}</h1>`)
+	require.NoError(t, err)
+
+	handler := &Handler{notFoundTemplate: tmpl}
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, handler.renderNotFound(rec, "/docs/<missing>", "site"))
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	require.Equal(t, "<h1>/docs/&lt;missing&gt; not found in site</h1>", rec.Body.String())
+}
+
+func TestCanonicalHostRedirect(t *testing.T) {
+	testCases := []struct {
+		url           string
+		tls           bool
+		canonicalHost string
+		upgradeHTTPS  bool
+		expected      string
+	}{
+		{url: "http://www.example.test/a/b?c=d", expected: ""},
+		{url: "http://www.example.test/a/b?c=d", canonicalHost: "example.test", expected: "http://example.test/a/b?c=d"},
+		{url: "http://example.test/a/", canonicalHost: "example.test", expected: ""},
+		{url: "http://EXAMPLE.test/a/", canonicalHost: "example.test", expected: ""},
+		{url: "http://example.test/", canonicalHost: "www.example.test", expected: "http://www.example.test/"},
+		{url: "https://www.example.test/a", tls: true, canonicalHost: "example.test", expected: "https://example.test/a"},
+		{url: "http://www.example.test/a", canonicalHost: "example.test", upgradeHTTPS: true, expected: "https://example.test/a"},
+		{url: "http://example.test/a", canonicalHost: "example.test", upgradeHTTPS: true, expected: ""},
+		{url: "http://www.example.test:8080/a", canonicalHost: "example.test", expected: "http://example.test:8080/a"},
+		{url: "http://www.example.test:8080/a", canonicalHost: "example.test:9090", expected: "http://example.test:9090/a"},
+		{url: "http://example.test:8080/a", canonicalHost: "example.test:9090", expected: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s to %s", tc.url, tc.canonicalHost), func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			if !tc.tls {
+				r.TLS = nil
+			}
+			host := r.URL.Hostname()
+
+			target := canonicalHostRedirect(r, host, tc.canonicalHost, tc.upgradeHTTPS)
+			if tc.expected == "" {
+				require.Nil(t, target)
+				return
+			}
+			require.NotNil(t, target)
+			require.Equal(t, tc.expected, target.String())
+			require.False(t, isRedirectLoop(r, target.String()))
+		})
+	}
+}
+
+func TestServeContentWithStatus(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	content := []byte("placeholder")
+	modtime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	const etag = `"placeholder-etag"`
+
+	for _, tc := range []struct {
+		name           string
+		method         string
+		status         int
+		header         http.Header
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "ok", method: http.MethodGet, status: http.StatusOK, expectedStatus: http.StatusOK, expectedBody: "placeholder"},
+		{name: "not found", method: http.MethodGet, status: http.StatusNotFound, expectedStatus: http.StatusNotFound, expectedBody: "placeholder"},
+		{name: "head", method: http.MethodHead, status: http.StatusNotFound, expectedStatus: http.StatusNotFound},
+		{name: "range", method: http.MethodGet, status: http.StatusOK, header: http.Header{"Range": {"bytes=0-4"}}, expectedStatus: http.StatusPartialContent, expectedBody: "place"},
+		{name: "if-none-match", method: http.MethodGet, status: http.StatusOK, header: http.Header{"If-None-Match": {etag}}, expectedStatus: http.StatusNotModified},
+		{name: "if-modified-since", method: http.MethodGet, status: http.StatusOK, header: http.Header{"If-Modified-Since": {modtime.Format(http.TimeFormat)}}, expectedStatus: http.StatusNotModified},
+		{name: "not found range", method: http.MethodGet, status: http.StatusNotFound, header: http.Header{"Range": {"bytes=0-4"}}, expectedStatus: http.StatusNotFound, expectedBody: "placeholder"},
+		{name: "not found if-none-match", method: http.MethodGet, status: http.StatusNotFound, header: http.Header{"If-None-Match": {etag}}, expectedStatus: http.StatusNotFound, expectedBody: "placeholder"},
+		{name: "not found if-none-match mismatch", method: http.MethodGet, status: http.StatusNotFound, header: http.Header{"If-None-Match": {`"other"`}}, expectedStatus: http.StatusNotFound, expectedBody: "placeholder"},
+		{name: "not found if-modified-since", method: http.MethodGet, status: http.StatusNotFound, header: http.Header{"If-Modified-Since": {modtime.Format(http.TimeFormat)}}, expectedStatus: http.StatusNotFound, expectedBody: "placeholder"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, "https://example.com/missing.png", nil)
+			for k, v := range tc.header {
+				r.Header[k] = v
+			}
+			rec := httptest.NewRecorder()
+			rec.Header().Set("ETag", etag)
+
+			err := serveContentWithStatus(ctx, rec, r, "placeholder.png", modtime, ranger.ByteRanger(content), tc.status)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedStatus, rec.Code)
+			require.Equal(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
@@ -43,8 +43,15 @@ type listObject struct {
 	Prefix bool
 }
 
-func (handler *Handler) servePrefix(ctx context.Context, w http.ResponseWriter, project *uplink.Project, pr *parsedRequest, archivePath, cursor string) (err error) {
+func (handler *Handler) servePrefix(ctx context.Context, w http.ResponseWriter, r *http.Request, project *uplink.Project, pr *parsedRequest, archivePath, cursor string) (err error) {
 	defer mon.Task()(&ctx)(&err)
+
+	// listing the files of an archive object is fine, as the object itself
+	// is accessible anyway.
+	if handler.listingDisabled && archivePath == "" {
+		return errdata.WithAction(uplink.ErrObjectNotFound, "serve prefix - listing disabled")
+	}
+
 	var input struct {
 		Title            string
 		Breadcrumbs      []breadcrumb
@@ -102,7 +109,7 @@ func (handler *Handler) servePrefix(ctx context.Context, w http.ResponseWriter,
 		input.ShowBackButton = true
 	}
 
-	handler.renderTemplate(w, "prefix-listing.html", pageData{
+	handler.renderTemplate(w, r, "prefix-listing.html", pageData{
 		Data:             input,
 		Title:            pr.title,
 		ShowViewContents: len(archivePath) > 0,
@@ -8,7 +8,9 @@ import (
 	"context"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 
 	"go.uber.org/zap"
 
@@ -23,6 +25,11 @@ import (
 type location struct {
 	Latitude  float64
 	Longitude float64
+
+	// ASNumber and ASOrganization are only set if an ASN database is
+	// configured.
+	ASNumber       uint
+	ASOrganization string
 }
 
 func (handler *Handler) getLocations(ctx context.Context, access *uplink.Access, bucket, key string) (locs []location, pieceCount int64, placementConstraint uint32, err error) {
@@ -32,7 +39,7 @@ func (handler *Handler) getLocations(ctx context.Context, access *uplink.Access,
 	// null when we plop it into the output javascript.
 	locations := make([]location, 0)
 
-	if handler.mapper == nil { // fast path
+	if handler.mapper == nil || handler.mapDisabled { // fast path
 		return locations, 0, 0, nil
 	}
 
@@ -41,20 +48,67 @@ func (handler *Handler) getLocations(ctx context.Context, access *uplink.Access,
 		return nil, 0, 0, errdata.WithAction(err, "get locations")
 	}
 
+	ips := make([]string, 0, len(ipSummary.IPPorts))
 	for _, ip := range ipSummary.IPPorts {
-		info, err := handler.mapper.GetIPInfos(ctx, string(ip))
+		ips = append(ips, string(ip))
+	}
+
+	return handler.cachedLocations(ctx, ips), ipSummary.PieceCount, ipSummary.PlacementConstraint, nil
+}
+
+// cachedLocations returns the geolocation of the nodes with the given
+// addresses, reusing the result for the same set of addresses until the
+// map cache expires.
+func (handler *Handler) cachedLocations(ctx context.Context, ips []string) []location {
+	// objects stored on the same nodes share their locations regardless of
+	// the order the nodes are listed in.
+	sort.Strings(ips)
+
+	// lookupLocations never fails, so neither does the cache.
+	locations, _ := handler.mapCache.Get(ctx, strings.Join(ips, ","), func() ([]location, error) {
+		return handler.lookupLocations(ctx, ips), nil
+	})
+	return locations
+}
+
+// lookupLocations returns the geolocation of the nodes with the given
+// addresses, skipping those that can't be located.
+func (handler *Handler) lookupLocations(ctx context.Context, ips []string) []location {
+	locations := make([]location, 0, len(ips))
+	for _, ip := range ips {
+		info, err := handler.mapper.GetIPInfos(ctx, ip)
 		if err != nil {
 			handler.log.Error("failed to get IP info", zap.Error(err))
 			continue
 		}
 
-		locations = append(locations, location{
+		loc := location{
 			Latitude:  info.Location.Latitude,
 			Longitude: info.Location.Longitude,
-		})
+		}
+
+		asn, err := handler.mapper.GetASN(ctx, ip)
+		if err != nil {
+			handler.log.Debug("failed to get ASN info", zap.Error(err))
+		} else if asn != nil {
+			loc.ASNumber, loc.ASOrganization = asn.Number, asn.Organization
+		}
+
+		locations = append(locations, loc)
 	}
+	return locations
+}
 
-	return locations, ipSummary.PieceCount, ipSummary.PlacementConstraint, nil
+// countNetworks returns the number of distinct autonomous systems among
+// locations. It returns zero if no ASN database is configured.
+func countNetworks(locations []location) int {
+	networks := make(map[uint]struct{})
+	for _, loc := range locations {
+		if loc.ASNumber != 0 {
+			networks[loc.ASNumber] = struct{}{}
+		}
+	}
+	return len(networks)
 }
 
 func (handler *Handler) serveMap(ctx context.Context, w http.ResponseWriter, locations []location, pieces int64, o *uplink.Object, q url.Values) (err error) {
@@ -0,0 +1,58 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package sharing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"storj.io/common/testcontext"
+	"storj.io/edge/pkg/linksharing/objectmap"
+)
+
+func TestCachedLocations(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	newHandler := func(capacity int) *Handler {
+		handler, err := NewHandler(zap.NewNop(), objectmap.NewIPDB(&objectmap.MockReader{}), nil, nil, Config{
+			URLBases:           []string{"http://test.test"},
+			ListPageLimit:      1,
+			MapCacheExpiration: time.Hour,
+			MapCacheCapacity:   capacity,
+		})
+		require.NoError(t, err)
+		return handler
+	}
+
+	expected := []location{{Latitude: -19.456, Longitude: 20.123}}
+
+	t.Run("cached", func(t *testing.T) {
+		handler := newHandler(10)
+
+		first := handler.cachedLocations(ctx, []string{"172.146.10.1:28967", "1.1.1.1:28967"})
+		require.Equal(t, expected, first)
+
+		// the same nodes in a different order share the cached locations.
+		second := handler.cachedLocations(ctx, []string{"1.1.1.1:28967", "172.146.10.1:28967"})
+		require.Equal(t, expected, second)
+		require.Same(t, &first[0], &second[0])
+
+		other := handler.cachedLocations(ctx, []string{"172.146.10.1:28967"})
+		require.Equal(t, expected, other)
+		require.NotSame(t, &first[0], &other[0])
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		handler := newHandler(0)
+
+		first := handler.cachedLocations(ctx, []string{"172.146.10.1:28967"})
+		second := handler.cachedLocations(ctx, []string{"172.146.10.1:28967"})
+		require.Equal(t, expected, first)
+		require.Equal(t, expected, second)
+		require.NotSame(t, &first[0], &second[0])
+	})
+}
@@ -0,0 +1,137 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package sharing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"storj.io/common/ranger/httpranger"
+	"storj.io/edge/pkg/errdata"
+	"storj.io/edge/pkg/linksharing/objectranger"
+	"storj.io/uplink"
+)
+
+const brotliContentCoding = "br"
+
+// precompressedCoding is a content coding that hosted sites can serve from
+// precompressed sidecar objects.
+type precompressedCoding struct {
+	coding    string
+	extension string
+}
+
+// precompressedCodings lists the supported sidecar content codings in order
+// of preference.
+var precompressedCodings = []precompressedCoding{
+	{coding: brotliContentCoding, extension: ".br"},
+	{coding: gzipContentCoding, extension: ".gz"},
+}
+
+// parsePrecompressed parses the value of the storj-precompressed TXT record,
+// a comma-separated list of content codings, into the codings linksharing
+// supports. Unknown codings are ignored.
+func parsePrecompressed(value string) (codings []string) {
+	for _, coding := range strings.Split(strings.ToLower(spaceReplacer.Replace(value)), ",") {
+		for _, supported := range precompressedCodings {
+			if coding == supported.coding {
+				codings = append(codings, coding)
+				break
+			}
+		}
+	}
+	return codings
+}
+
+// acceptedPrecompressedCodings returns the precompressed codings out of
+// enabled that the client accepts, in order of preference. Unlike
+// isContentCodingAcceptable, a coding is only accepted if Accept-Encoding
+// lists it, or the "*" wildcard, with a non-zero weight.
+//
+// Clients that don't send Accept-Encoding at all never get a sidecar, even
+// though RFC 9110 would allow any coding for them.
+func acceptedPrecompressedCodings(header http.Header, enabled []string) (accepted []precompressedCoding) {
+	if _, ok := header["Accept-Encoding"]; !ok {
+		return nil
+	}
+	weights := parseAcceptEncodingHeader(header)
+	for _, candidate := range precompressedCodings {
+		if !slices.Contains(enabled, candidate.coding) {
+			continue
+		}
+		weight, ok := weights[candidate.coding]
+		if !ok {
+			weight, ok = weights["*"]
+		}
+		if ok && weight > 0 {
+			accepted = append(accepted, candidate)
+		}
+	}
+	return accepted
+}
+
+// mayServePrecompressed returns whether a precompressed sidecar might be
+// served instead of the requested object, so that the object itself
+// shouldn't be downloaded before looking the sidecar up.
+func mayServePrecompressed(r *http.Request, pr *parsedRequest) bool {
+	return pr.hosting && len(acceptedPrecompressedCodings(r.Header, pr.precompressed)) > 0
+}
+
+// precompressedSidecar looks for a precompressed sidecar of the object under
+// key (e.g. foo.js.br for foo.js) in one of the enabled content codings that
+// the client accepts, preferring Brotli over gzip. It returns a nil object if
+// there's no such sidecar, in which case the original object should be served
+// as is.
+func (handler *Handler) precompressedSidecar(ctx context.Context, r *http.Request, project *uplink.Project, bucket, key string, enabled []string) (_ *uplink.Object, coding string, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	for _, candidate := range acceptedPrecompressedCodings(r.Header, enabled) {
+		o, err := project.StatObject(ctx, bucket, key+candidate.extension)
+		if err != nil {
+			if errors.Is(err, uplink.ErrObjectNotFound) {
+				continue
+			}
+			return nil, "", errdata.WithAction(err, "stat precompressed object")
+		}
+
+		mon.Event("precompressed_sidecar_served_" + candidate.coding)
+
+		return o, candidate.coding, nil
+	}
+
+	return nil, "", nil
+}
+
+// servePrecompressed serves a precompressed sidecar of o instead of o itself
+// if the hosted site enabled precompressed sidecars, o has no content coding
+// of its own, and a sidecar acceptable to the client exists. The response
+// headers for o must already be set. It returns whether it served a response.
+func (handler *Handler) servePrecompressed(ctx context.Context, w http.ResponseWriter, r *http.Request, pr *parsedRequest, project *uplink.Project, o *uplink.Object) (served bool, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if !pr.hosting || len(pr.precompressed) == 0 || w.Header().Get("Content-Encoding") != "" {
+		return false, nil
+	}
+
+	// the response varies with Accept-Encoding whether or not a sidecar ends
+	// up being served, so caches must not reuse it for other clients.
+	varyAcceptEncoding(w.Header())
+
+	sidecar, coding, err := handler.precompressedSidecar(ctx, r, project, pr.bucket, o.Key, pr.precompressed)
+	if err != nil || sidecar == nil {
+		return false, err
+	}
+
+	w.Header().Set("Content-Encoding", coding)
+	w.Header().Set("ETag", objectETag(sidecar))
+
+	err = httpranger.ServeContent(ctx, w, r, o.Key, sidecar.System.Created, objectranger.New(project, sidecar, nil, httpranger.HTTPRange{}, pr.bucket, handler.downloadRetry))
+	if err != nil {
+		return true, errdata.WithAction(err, "serve precompressed content")
+	}
+	return true, nil
+}
@@ -0,0 +1,115 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package sharing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+	"storj.io/uplink"
+)
+
+func TestParsePrecompressed(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected []string
+	}{
+		{"", nil},
+		{"br", []string{"br"}},
+		{"br,gzip", []string{"br", "gzip"}},
+		{" GZIP , Br ", []string{"gzip", "br"}},
+		{"br,deflate,zstd", []string{"br"}},
+		{",,", nil},
+	} {
+		require.Equal(t, tc.expected, parsePrecompressed(tc.value), tc.value)
+	}
+}
+
+func TestAcceptedPrecompressedCodings(t *testing.T) {
+	both := []string{"br", "gzip"}
+
+	for _, tc := range []struct {
+		name     string
+		header   http.Header
+		enabled  []string
+		expected []string
+	}{
+		{name: "no accept-encoding", header: http.Header{}, enabled: both},
+		{name: "empty accept-encoding", header: http.Header{"Accept-Encoding": {""}}, enabled: both},
+		{name: "both", header: http.Header{"Accept-Encoding": {"gzip, deflate, br"}}, enabled: both, expected: both},
+		{name: "brotli preferred", header: http.Header{"Accept-Encoding": {"gzip;q=1.0, br;q=0.5"}}, enabled: both, expected: both},
+		{name: "gzip only", header: http.Header{"Accept-Encoding": {"gzip"}}, enabled: both, expected: []string{"gzip"}},
+		{name: "unlisted", header: http.Header{"Accept-Encoding": {"deflate"}}, enabled: both},
+		{name: "identity only", header: http.Header{"Accept-Encoding": {"identity"}}, enabled: both},
+		{name: "brotli refused", header: http.Header{"Accept-Encoding": {"br;q=0, gzip"}}, enabled: both, expected: []string{"gzip"}},
+		{name: "wildcard", header: http.Header{"Accept-Encoding": {"*"}}, enabled: both, expected: both},
+		{name: "wildcard refusing gzip", header: http.Header{"Accept-Encoding": {"*, gzip;q=0"}}, enabled: both, expected: []string{"br"}},
+		{name: "only gzip enabled", header: http.Header{"Accept-Encoding": {"br, gzip"}}, enabled: []string{"gzip"}, expected: []string{"gzip"}},
+		{name: "none enabled", header: http.Header{"Accept-Encoding": {"br, gzip"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var codings []string
+			for _, coding := range acceptedPrecompressedCodings(tc.header, tc.enabled) {
+				codings = append(codings, coding.coding)
+			}
+			require.Equal(t, tc.expected, codings)
+		})
+	}
+}
+
+func TestServePrecompressedVary(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	handler := &Handler{}
+	o := &uplink.Object{Key: "app.js"}
+
+	for _, tc := range []struct {
+		name            string
+		pr              parsedRequest
+		header          http.Header
+		contentEncoding string
+		vary            bool
+		mayServe        bool
+	}{
+		{name: "not hosting", pr: parsedRequest{precompressed: []string{"br"}}, header: http.Header{"Accept-Encoding": {"br"}}},
+		{name: "not enabled", pr: parsedRequest{hosting: true}, header: http.Header{"Accept-Encoding": {"br"}}},
+		{name: "own content coding", pr: parsedRequest{hosting: true, precompressed: []string{"br"}}, header: http.Header{"Accept-Encoding": {"br"}}, contentEncoding: "gzip", mayServe: true},
+		{name: "no accept-encoding", pr: parsedRequest{hosting: true, precompressed: []string{"br"}}, header: http.Header{}, vary: true},
+		{name: "coding refused", pr: parsedRequest{hosting: true, precompressed: []string{"br"}}, header: http.Header{"Accept-Encoding": {"gzip, br;q=0"}}, vary: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/app.js", nil)
+			r.Header = tc.header
+
+			rec := httptest.NewRecorder()
+			if tc.contentEncoding != "" {
+				rec.Header().Set("Content-Encoding", tc.contentEncoding)
+			}
+
+			require.Equal(t, tc.mayServe, mayServePrecompressed(r, &tc.pr))
+
+			// none of the cases look for a sidecar, so no project is needed.
+			served, err := handler.servePrecompressed(ctx, rec, r, &tc.pr, nil, o)
+			require.NoError(t, err)
+			require.False(t, served)
+
+			if tc.vary {
+				require.Equal(t, []string{"Accept-Encoding"}, rec.Header().Values("Vary"))
+			} else {
+				require.Empty(t, rec.Header().Values("Vary"))
+			}
+		})
+	}
+}
+
+func TestVaryAcceptEncoding(t *testing.T) {
+	header := http.Header{"Vary": {"Origin"}}
+	varyAcceptEncoding(header)
+	varyAcceptEncoding(header)
+	require.Equal(t, []string{"Origin", "Accept-Encoding"}, header.Values("Vary"))
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"math"
 	"mime"
@@ -16,6 +17,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/zeebo/errs"
 	"go.uber.org/zap"
@@ -35,6 +37,10 @@ const (
 	gzipContentCoding = "gzip"
 )
 
+// sniffLen is the number of bytes considered when detecting content types,
+// as per http.DetectContentType.
+const sniffLen = 512
+
 var spaceReplacer = strings.NewReplacer(" ", "", "\t", "")
 
 type parsedRequest struct {
@@ -49,6 +55,19 @@ type parsedRequest struct {
 	downloadDefault  bool
 	hosting          bool
 	hostingTLS       bool
+	precompressed    []string
+	// indexDocument overrides the handler's index document, e.g. for hosted
+	// websites with a website configuration.
+	indexDocument string
+}
+
+// indexDocumentOf returns the name of the object served for requests of
+// prefixes in pr.
+func (handler *Handler) indexDocumentOf(pr *parsedRequest) string {
+	if pr.indexDocument != "" {
+		return pr.indexDocument
+	}
+	return handler.indexDocument
 }
 
 func (handler *Handler) present(ctx context.Context, w http.ResponseWriter, r *http.Request, pr *parsedRequest) (err error) {
@@ -82,9 +101,20 @@ func (handler *Handler) presentWithProject(ctx context.Context, w http.ResponseW
 		archivePath = q["path"][0]
 	}
 
+	// the slot is taken before anything is downloaded, including the
+	// predicted range below. Hosting requests took theirs already.
+	if !pr.hosting && (download || !wrap) && !mapOnly {
+		release, err := handler.downloads.acquire(pr.serializedAccess)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
 	switch {
 	case strings.HasSuffix(pr.realKey, "/"):
-		// kick off background index.html request to cut down on sequential round trips.
+		// kick off background index document request to cut down on sequential
+		// round trips.
 		type statResult struct {
 			obj *uplink.Object
 			err error
@@ -93,7 +123,7 @@ func (handler *Handler) presentWithProject(ctx context.Context, w http.ResponseW
 		// stat object result away entirely.
 		indexResultCh := make(chan statResult, 1)
 		go func() {
-			obj, err := project.StatObject(ctx, pr.bucket, pr.realKey+"index.html")
+			obj, err := project.StatObject(ctx, pr.bucket, pr.realKey+handler.indexDocumentOf(pr))
 			indexResultCh <- statResult{obj: obj, err: err}
 		}()
 
@@ -106,28 +136,34 @@ func (handler *Handler) presentWithProject(ctx context.Context, w http.ResponseW
 			return errdata.WithAction(err, "stat object")
 		}
 
-		// index.html object?
+		// index document?
 		indexResult := <-indexResultCh
 		o, err = indexResult.obj, indexResult.err
 		if err == nil {
 			return handler.showObject(ctx, w, r, pr, project, o, nil, httpranger.HTTPRange{})
 		}
 		if !errors.Is(err, uplink.ErrObjectNotFound) {
-			return errdata.WithAction(err, "stat object - index.html")
+			return errdata.WithAction(err, "stat object - index document")
 		}
 
 		// it might be a prefix
 		if handler.downloadPrefixEnabled && (download || !wrap) && !pr.hosting {
 			return handler.downloadPrefix(ctx, w, project, pr, downloadKind)
 		}
-		return handler.servePrefix(ctx, w, project, pr, "", cursor)
+		return handler.servePrefix(ctx, w, r, project, pr, "", cursor)
 
 	case pr.realKey != "":
 		var objectErr error
 		options, rangeErr := predictRange(r.Header.Get("Range"))
 		// a rangeErr here does not always result in RangeNotSatisfiable so ignore it and
 		// allow StatObject and ServeContent to handle all the edge cases.
-		if (download || !wrap) && !mapOnly && len(archivePath) == 0 && rangeErr == nil {
+		//
+		// conditional requests are likely to end with 304 Not Modified, so we
+		// only stat the object for them and leave downloading to the object
+		// ranger in case the content has to be served after all. HEAD requests
+		// never need the content, and neither do requests that might be
+		// served a precompressed sidecar instead.
+		if (download || !wrap) && !mapOnly && len(archivePath) == 0 && rangeErr == nil && !isConditionalRequest(r) && r.Method != http.MethodHead && !mayServePrecompressed(r, pr) {
 			d, err := project.DownloadObject(ctx, pr.bucket, pr.realKey, options)
 			if err == nil {
 				defer func() {
@@ -164,38 +200,36 @@ func (handler *Handler) presentWithProject(ctx context.Context, w http.ResponseW
 		}
 
 		if isPrefix {
-			u := r.URL
+			u := *r.URL
 			u.Path += "/"
 
-			http.Redirect(w, r, u.String(), http.StatusSeeOther)
-			return nil
+			return handler.redirect(w, r, u.String(), http.StatusSeeOther)
 		}
 
 		return objectErr
 	// there are no objects with the empty key
 	case pr.realKey == "":
 		if pr.hosting {
-			o, err := project.StatObject(ctx, pr.bucket, "index.html")
+			o, err := project.StatObject(ctx, pr.bucket, handler.indexDocumentOf(pr))
 			if err == nil {
 				return handler.showObject(ctx, w, r, pr, project, o, nil, httpranger.HTTPRange{})
 			}
 			if !errors.Is(err, uplink.ErrObjectNotFound) {
-				return errdata.WithAction(err, "stat object - index.html")
+				return errdata.WithAction(err, "stat object - index document")
 			}
 		}
 
 		// special case for if the user requested a bucket but there's no trailing slash
 		if !strings.HasSuffix(r.URL.Path, "/") {
-			u := r.URL
+			u := *r.URL
 			u.Path += "/"
 
-			http.Redirect(w, r, u.String(), http.StatusSeeOther)
-			return nil
+			return handler.redirect(w, r, u.String(), http.StatusSeeOther)
 		}
 		if handler.downloadPrefixEnabled && (download || !wrap) && !pr.hosting {
 			return handler.downloadPrefix(ctx, w, project, pr, downloadKind)
 		}
-		return handler.servePrefix(ctx, w, project, pr, "", cursor)
+		return handler.servePrefix(ctx, w, r, project, pr, "", cursor)
 	default:
 		return errdata.WithAction(err, "unexpected case")
 	}
@@ -232,7 +266,7 @@ func (handler *Handler) showObject(ctx context.Context, w http.ResponseWriter, r
 
 	if (download || !wrap) && !mapOnly {
 		if len(archivePath) > 0 { // handle zip archives
-			handler.setHeaders(w, r, o.Custom, pr.hosting, archivePath)
+			handler.setHeaders(w, r, o.Custom, pr.hosting, archivePath, nil)
 			if len(r.Header.Get("Range")) > 0 { // prohibit range requests for archives for now
 				return errdata.WithStatus(errs.New("Range header isn't compatible with path query"), http.StatusRequestedRangeNotSatisfiable)
 			}
@@ -253,8 +287,23 @@ func (handler *Handler) showObject(ctx context.Context, w http.ResponseWriter, r
 				return errdata.WithAction(err, "serve content")
 			}
 		} else {
-			handler.setHeaders(w, r, o.Custom, pr.hosting, filepath.Base(o.Key))
-			err = httpranger.ServeContent(ctx, w, r, o.Key, o.System.Created, objectranger.New(project, o, d, httpRange, pr.bucket))
+			// sniffing downloads the start of the object, which is only worth
+			// it if the object's content is served at all.
+			var sniff func() string
+			if servesBody(r, objectETag(o), o.System.Created) {
+				sniff = handler.sniffObject(ctx, project, pr.bucket, o)
+			}
+			handler.setHeaders(w, r, o.Custom, pr.hosting, filepath.Base(o.Key), sniff)
+			w.Header().Set("ETag", objectETag(o))
+			served, err := handler.servePrecompressed(ctx, w, r, pr, project, o)
+			if err != nil || served {
+				return err
+			}
+			cw, finish := handler.compressResponse(w, r, o)
+			err = httpranger.ServeContent(ctx, cw, r, o.Key, o.System.Created, objectranger.New(project, o, d, httpRange, pr.bucket, handler.downloadRetry))
+			if err == nil {
+				err = finish()
+			}
 			if err != nil {
 				return errdata.WithAction(err, "serve content")
 			}
@@ -263,7 +312,11 @@ func (handler *Handler) showObject(ctx context.Context, w http.ResponseWriter, r
 	}
 
 	if archivePath == "/" {
-		return handler.servePrefix(ctx, w, project, pr, archivePath, "")
+		return handler.servePrefix(ctx, w, r, project, pr, archivePath, "")
+	}
+
+	if mapOnly && handler.mapDisabled {
+		return errdata.WithAction(uplink.ErrObjectNotFound, "show object - map disabled")
 	}
 
 	locations, pieces, placementConstraint, err := handler.getLocations(ctx, pr.access, pr.bucket, o.Key)
@@ -276,14 +329,16 @@ func (handler *Handler) showObject(ctx context.Context, w http.ResponseWriter, r
 	}
 
 	var input struct {
-		Key          string
-		Size         string
-		NodesCount   int
-		HasPlacement bool
-		IsInline     bool
+		Key           string
+		Size          string
+		NodesCount    int
+		NetworksCount int
+		HasPlacement  bool
+		IsInline      bool
 	}
 
 	input.NodesCount = len(locations)
+	input.NetworksCount = countNetworks(locations)
 
 	// TODO(artur): fix image preview paths when the corresponding image is in
 	// the zip archive.
@@ -323,7 +378,7 @@ func (handler *Handler) showObject(ctx context.Context, w http.ResponseWriter, r
 	data.Title = input.Key
 	data.AllowDownload = handler.isDownloadAllowed(pr.access)
 
-	handler.renderTemplate(w, "single-object.html", data)
+	handler.renderTemplate(w, r, "single-object.html", data)
 
 	return nil
 }
@@ -349,9 +404,18 @@ func (handler *Handler) isDownloadAllowed(access *uplink.Access) bool {
 	return true
 }
 
-func (handler *Handler) setHeaders(w http.ResponseWriter, r *http.Request, metadata map[string]string, hosting bool, filename string) {
+// setHeaders sets the response headers of an object from its metadata. If
+// its content type is otherwise unknown and sniffing is enabled, sniff is
+// called, if not nil, to detect it from the object's content.
+func (handler *Handler) setHeaders(w http.ResponseWriter, r *http.Request, metadata map[string]string, hosting bool, filename string, sniff func() string) {
 	detectType := !hasValue(r.Header, "X-Content-Type-Options", "nosniff")
-	contentType := contentType(filename, metadata, detectType)
+	contentType := contentType(filename, metadata, detectType, handler.contentTypeOverrides)
+	if contentType == "" && detectType && handler.sniffContentType && sniff != nil {
+		contentType = sniff()
+	}
+	// the disposition is decided on the type the object was stored with, so
+	// that HTML served as text/plain can still be matched as text/html.
+	inline := handler.inlineDisposition(contentType)
 	if contentType != "" {
 		if !handler.standardViewsHTML && !hosting && strings.Contains(strings.ToLower(contentType), "html") {
 			contentType = "text/plain"
@@ -361,7 +425,7 @@ func (handler *Handler) setHeaders(w http.ResponseWriter, r *http.Request, metad
 		w.Header().Set("Content-Type", "application/octet-stream")
 	}
 
-	if !handler.standardRendersContent && !allowedInlineType(contentType) && !hosting {
+	if !inline && !hosting {
 		w.Header().Set("Content-Disposition", "attachment; filename="+filename)
 	}
 
@@ -380,8 +444,8 @@ func (handler *Handler) isPrefix(ctx context.Context, project *uplink.Project, p
 	defer mon.Task()(&ctx)(&err)
 
 	// we might not having listing permission. if this is the case, guess that
-	// we're looking for an index.html and look for that.
-	_, err = project.StatObject(ctx, pr.bucket, pr.realKey+"/index.html")
+	// we're looking for an index document and look for that.
+	_, err = project.StatObject(ctx, pr.bucket, pr.realKey+"/"+handler.indexDocumentOf(pr))
 	if err == nil {
 		return true, nil
 	}
@@ -436,6 +500,114 @@ func imagePreviewPath(access, bucket, key string, size int64) (twitterImage, ogI
 	return twitterImage, ogImage
 }
 
+// inlineDisposition returns whether content of contentType may be rendered
+// inline for standard (non-hosting) requests.
+func (handler *Handler) inlineDisposition(contentType string) bool {
+	switch {
+	case handler.attachmentContentTypes.matches(contentType):
+		return false
+	case handler.standardRendersContent:
+		return true
+	default:
+		return allowedInlineType(contentType) || handler.inlineContentTypes.matches(contentType)
+	}
+}
+
+// sniffObject returns a function detecting the content type of o from at
+// most its first sniffLen bytes. It returns an empty string if the content
+// can't be downloaded.
+func (handler *Handler) sniffObject(ctx context.Context, project *uplink.Project, bucket string, o *uplink.Object) func() string {
+	return func() string {
+		length := min(o.System.ContentLength, sniffLen)
+		if length <= 0 {
+			return ""
+		}
+
+		download, err := project.DownloadObject(ctx, bucket, o.Key, &uplink.DownloadOptions{Length: length})
+		if err != nil {
+			handler.log.Debug("unable to sniff content type", zap.Error(err))
+			return ""
+		}
+		defer func() {
+			if err := download.Close(); err != nil {
+				handler.log.Debug("unable to close sniffing download", zap.Error(err))
+			}
+		}()
+
+		buf := make([]byte, length)
+		n, err := io.ReadFull(download, buf)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			handler.log.Debug("unable to sniff content type", zap.Error(err))
+			return ""
+		}
+
+		mon.Event("content_type_sniffed")
+		return http.DetectContentType(buf[:n])
+	}
+}
+
+// parseContentTypeOverrides parses entries of file extensions and content
+// types joined by '=', e.g. .wasm=application/wasm, into a map keyed by
+// lowercase extensions with a leading dot.
+func parseContentTypeOverrides(entries []string) (map[string]string, error) {
+	overrides := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		ext, contentType, ok := strings.Cut(entry, "=")
+		ext, contentType = strings.TrimSpace(ext), strings.TrimSpace(contentType)
+		if !ok || ext == "" || ext == "." || contentType == "" {
+			return nil, errs.New("invalid content type override %q, expected .ext=type", entry)
+		}
+		if _, _, err := mime.ParseMediaType(contentType); err != nil {
+			return nil, errs.New("invalid content type override %q: %w", entry, err)
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		overrides[strings.ToLower(ext)] = contentType
+	}
+	return overrides, nil
+}
+
+// contentTypeList is a list of lowercase media types, which may end with a
+// wildcard subtype, e.g. image/*.
+type contentTypeList []string
+
+func newContentTypeList(types []string) contentTypeList {
+	var list contentTypeList
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t != "" {
+			list = append(list, t)
+		}
+	}
+	return list
+}
+
+// matches returns whether the media type of contentType, ignoring any
+// parameters, is in the list.
+func (list contentTypeList) matches(contentType string) bool {
+	if len(list) == 0 || contentType == "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range list {
+		if t == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(t, "*"); ok && strings.HasSuffix(prefix, "/") && strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // allowedInlineType allows certain MIME types that are considered safe to be used
 // for "inline" disposition with Linksharing serving requests on public domains.
 func allowedInlineType(contentType string) bool {
@@ -469,7 +641,7 @@ func metadataHeaderValue(metadata map[string]string, header string) string {
 	return ""
 }
 
-func contentType(key string, metadata map[string]string, detectType bool) (contentType string) {
+func contentType(key string, metadata map[string]string, detectType bool, overrides map[string]string) (contentType string) {
 	contentType = metadataHeaderValue(metadata, "Content-Type")
 
 	if detectType {
@@ -481,7 +653,11 @@ func contentType(key string, metadata map[string]string, detectType bool) (conte
 		}
 
 		if contentType == "" {
-			return mime.TypeByExtension(filepath.Ext(key))
+			ext := filepath.Ext(key)
+			if override, ok := overrides[strings.ToLower(ext)]; ok {
+				return override
+			}
+			return mime.TypeByExtension(ext)
 		}
 	}
 
@@ -552,7 +728,10 @@ func predictRange(s string) (*uplink.DownloadOptions, error) {
 			}
 		}
 
-		// satellite doesn't currently support multiple ranges
+		// satellite doesn't currently support multiple ranges, so only the
+		// first one is predicted. httpranger.ServeContent serves requests of
+		// multiple ranges as multipart/byteranges and downloads the others
+		// through ObjectRanger.Range.
 		return &uplink.DownloadOptions{Offset: offset, Length: length}, nil
 	}
 	return nil, errors.New("range prediction failed")
@@ -583,6 +762,70 @@ func optionsToRange(length int64, options *uplink.DownloadOptions) httpranger.HT
 	return r
 }
 
+// objectETag returns an entity tag for the object derived from its creation
+// time and size, since uplink doesn't expose the ETag of objects.
+func objectETag(o *uplink.Object) string {
+	return fmt.Sprintf(`"%x-%x"`, o.System.Created.UnixNano(), o.System.ContentLength)
+}
+
+// isConditionalRequest returns whether the request has preconditions that
+// can make it end with 304 Not Modified.
+func isConditionalRequest(r *http.Request) bool {
+	return r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != ""
+}
+
+// servesBody returns whether a request of a representation with etag and
+// modtime ends up with its content being served, i.e. it isn't a HEAD
+// request and its preconditions neither fail nor make it end with 304 Not
+// Modified. The preconditions are evaluated in the order of RFC 9110 Section
+// 13.2.2, like httpranger.ServeContent does.
+func servesBody(r *http.Request, etag string, modtime time.Time) bool {
+	if r.Method == http.MethodHead {
+		return false
+	}
+
+	modtime = modtime.Truncate(time.Second)
+
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if !etagListMatches(ifMatch, etag, false) {
+			return false
+		}
+	} else if t, err := http.ParseTime(r.Header.Get("If-Unmodified-Since")); err == nil && modtime.After(t) {
+		return false
+	}
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return !etagListMatches(ifNoneMatch, etag, true)
+	}
+	if t, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && r.Method == http.MethodGet && !modtime.After(t) {
+		return false
+	}
+	return true
+}
+
+// etagListMatches returns whether list, the value of an If-Match or
+// If-None-Match header, matches etag, comparing weakly if weak is set.
+func etagListMatches(list, etag string, weak bool) bool {
+	if weak {
+		etag = strings.TrimPrefix(etag, "W/")
+	} else if strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
 // isContentCodingAcceptable returns whether the specified content coding is acceptable
 // in accordance with RFC 9110 Section 12.5.3.
 // It panics if the coding is the wildcard token ("*").
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -116,12 +117,80 @@ func TestDownloadMetadataHeaders(t *testing.T) {
 	}
 }
 
+func TestConditionalGet(t *testing.T) {
+	cfg := Config{
+		ListPageLimit: 1,
+		URLBases:      []string{"http://test.test"},
+	}
+
+	handler, err := NewHandler(&zap.Logger{}, &objectmap.IPDB{}, nil, nil, cfg)
+	require.NoError(t, err)
+
+	ctx := testcontext.New(t)
+
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	object := &uplink.Object{
+		Key: "test.txt",
+		System: uplink.SystemMetadata{
+			Created:       created,
+			ContentLength: 100,
+		},
+	}
+
+	testCases := []struct {
+		desc   string
+		header http.Header
+		status int
+	}{
+		{
+			desc:   "matching etag",
+			header: http.Header{"If-None-Match": []string{objectETag(object)}},
+			status: http.StatusNotModified,
+		},
+		{
+			desc:   "not modified since",
+			header: http.Header{"If-Modified-Since": []string{created.Add(time.Hour).Format(http.TimeFormat)}},
+			status: http.StatusNotModified,
+		},
+		{
+			desc:   "etag takes precedence",
+			header: http.Header{"If-None-Match": []string{`"other"`}, "If-Modified-Since": []string{created.Add(time.Hour).Format(http.TimeFormat)}},
+			status: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://test.test?download", nil)
+			require.NoError(t, err)
+			r.Header = tc.header
+			require.True(t, isConditionalRequest(r))
+
+			obj := *object
+			if tc.status == http.StatusOK {
+				// serving an empty object doesn't need a download.
+				obj.System.ContentLength = 0
+			}
+
+			// the project can't download anything, so the object must not be
+			// downloaded when the response is 304 Not Modified.
+			err = handler.showObject(ctx, w, r, &parsedRequest{}, &uplink.Project{}, &obj, nil, httpranger.HTTPRange{})
+			require.NoError(t, err)
+			require.Equal(t, tc.status, w.Code)
+			require.Equal(t, objectETag(&obj), w.Header().Get("ETag"))
+		})
+	}
+}
+
 func TestContentDisposition(t *testing.T) {
 	testCases := []struct {
 		desc                   string
 		contentType            string
 		hosting                bool
 		standardRendersContent bool
+		inlineContentTypes     []string
+		attachmentContentTypes []string
 		key                    string
 		disposition            []string
 	}{
@@ -210,6 +279,62 @@ func TestContentDisposition(t *testing.T) {
 			key:                    "test.png",
 			contentType:            "image/png",
 		},
+		{
+			desc:               "webp file, type set, inline type, no disposition",
+			key:                "test.webp",
+			contentType:        "image/webp",
+			inlineContentTypes: []string{"image/webp"},
+		},
+		{
+			desc:               "mp4 file, type set, inline wildcard type, no disposition",
+			key:                "test.mp4",
+			contentType:        "video/mp4",
+			inlineContentTypes: []string{" Video/* "},
+		},
+		{
+			desc:               "dat file, type set, not an inline type, disposition set to attachment",
+			key:                "something.dat",
+			contentType:        "unknown/thing",
+			inlineContentTypes: []string{"image/webp", "video/*"},
+			disposition:        []string{"attachment; filename=something.dat"},
+		},
+		{
+			desc:                   "html file, standard renders enabled, type detected, attachment type, disposition set to attachment",
+			key:                    "test.html",
+			standardRendersContent: true,
+			attachmentContentTypes: []string{"text/html"},
+			disposition:            []string{"attachment; filename=test.html"},
+		},
+		{
+			desc:                   "js file, standard renders enabled, type set with parameters, attachment type, disposition set to attachment",
+			key:                    "test.js",
+			contentType:            "application/javascript; charset=utf-8",
+			standardRendersContent: true,
+			attachmentContentTypes: []string{"application/javascript"},
+			disposition:            []string{"attachment; filename=test.js"},
+		},
+		{
+			desc:                   "png file, type set, attachment type takes precedence, disposition set to attachment",
+			key:                    "test.png",
+			contentType:            "image/png",
+			inlineContentTypes:     []string{"image/*"},
+			attachmentContentTypes: []string{"image/png"},
+			disposition:            []string{"attachment; filename=test.png"},
+		},
+		{
+			desc:                   "png file, standard renders enabled, type set, other attachment type, no disposition",
+			key:                    "test.png",
+			contentType:            "image/png",
+			standardRendersContent: true,
+			attachmentContentTypes: []string{"text/html", ""},
+		},
+		{
+			desc:                   "hosting html file, type set, attachment type, no disposition",
+			key:                    "test.html",
+			contentType:            "text/html",
+			hosting:                true,
+			attachmentContentTypes: []string{"text/html"},
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
@@ -218,6 +343,8 @@ func TestContentDisposition(t *testing.T) {
 				ListPageLimit:          1,
 				URLBases:               []string{"http://test.test"},
 				StandardRendersContent: tc.standardRendersContent,
+				InlineContentTypes:     tc.inlineContentTypes,
+				AttachmentContentTypes: tc.attachmentContentTypes,
 			}
 
 			handler, err := NewHandler(&zap.Logger{}, nil, nil, nil, cfg)
@@ -294,6 +421,7 @@ func TestContentType(t *testing.T) {
 		key        string
 		metadata   map[string]string
 		detectType bool
+		overrides  map[string]string
 		expected   string
 	}{
 		{
@@ -366,15 +494,59 @@ func TestContentType(t *testing.T) {
 			},
 			expected: "text/html",
 		},
+		{
+			desc:       "object with no metadata, type overridden",
+			key:        "test.WASM",
+			detectType: true,
+			overrides:  map[string]string{".wasm": "application/wasm"},
+			expected:   "application/wasm",
+		},
+		{
+			desc:       "object with no metadata, override takes precedence",
+			key:        "test.gif",
+			detectType: true,
+			overrides:  map[string]string{".gif": "custom/mime"},
+			expected:   "custom/mime",
+		},
+		{
+			desc: "object with Content-Type metadata, not overridden",
+			key:  "test.gif",
+			metadata: map[string]string{
+				"Content-Type": "image/gif",
+			},
+			detectType: true,
+			overrides:  map[string]string{".gif": "custom/mime"},
+			expected:   "image/gif",
+		},
+		{
+			desc:      "object with no metadata, no detection, not overridden",
+			key:       "test.gif",
+			overrides: map[string]string{".gif": "custom/mime"},
+			expected:  "",
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
 		t.Run(tc.desc, func(t *testing.T) {
-			require.Equal(t, tc.expected, contentType(tc.key, tc.metadata, tc.detectType))
+			require.Equal(t, tc.expected, contentType(tc.key, tc.metadata, tc.detectType, tc.overrides))
 		})
 	}
 }
 
+func TestParseContentTypeOverrides(t *testing.T) {
+	overrides, err := parseContentTypeOverrides([]string{"", ".WASM=application/wasm", " md = text/markdown; charset=utf-8 "})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		".wasm": "application/wasm",
+		".md":   "text/markdown; charset=utf-8",
+	}, overrides)
+
+	for _, entry := range []string{".wasm", ".wasm=", "=application/wasm", ".=application/wasm", ".wasm=not a type"} {
+		_, err := parseContentTypeOverrides([]string{entry})
+		require.Error(t, err, entry)
+	}
+}
+
 func TestHasValue(t *testing.T) {
 	assert.False(t, hasValue(http.Header{}, "Content-Encoding", "gzip"))
 	assert.False(t, hasValue(http.Header{"Content-Encoding": []string{"deflate", "gzip"}}, "Content-Encoding", "a"))
@@ -512,6 +684,20 @@ func TestIsDownloadAllowed(t *testing.T) {
 	require.False(t, allowed)
 }
 
+func TestServePrefixListingDisabled(t *testing.T) {
+	handler, err := NewHandler(&zap.Logger{}, &objectmap.IPDB{}, nil, nil, Config{
+		ListPageLimit:   1,
+		ListingDisabled: true,
+		URLBases:        []string{"http://test.test"},
+	})
+	require.NoError(t, err)
+
+	ctx := testcontext.New(t)
+
+	err = handler.servePrefix(ctx, httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), nil, &parsedRequest{visibleKey: "prefix/"}, "", "")
+	require.ErrorIs(t, err, uplink.ErrObjectNotFound)
+}
+
 func TestIsContentCodingAcceptable(t *testing.T) {
 	require.Equal(t, true, isContentCodingAcceptable("gzip", http.Header{}))
 	require.Equal(t, true, isContentCodingAcceptable("identity", http.Header{"Accept-Encoding": []string{""}}))
@@ -535,3 +721,57 @@ func TestIsContentCodingAcceptable(t *testing.T) {
 		require.Equal(t, tt.accepted, isContentCodingAcceptable("gzip", header), "Header value: %s", tt.value)
 	}
 }
+
+func TestServesBody(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	modtime := time.Date(2025, 3, 4, 5, 6, 7, 890, time.UTC)
+	const etag = `"etag"`
+	before := modtime.Add(-time.Hour).Format(http.TimeFormat)
+	after := modtime.Add(time.Hour).Format(http.TimeFormat)
+
+	for _, tc := range []struct {
+		desc   string
+		method string
+		header http.Header
+		body   bool
+	}{
+		{desc: "unconditional", body: true},
+		{desc: "head", method: http.MethodHead},
+		{desc: "if-match", header: http.Header{"If-Match": {etag}}, body: true},
+		{desc: "if-match any", header: http.Header{"If-Match": {"*"}}, body: true},
+		{desc: "if-match list", header: http.Header{"If-Match": {`"other", "etag"`}}, body: true},
+		{desc: "if-match mismatch", header: http.Header{"If-Match": {`"other"`}}},
+		{desc: "if-match weak", header: http.Header{"If-Match": {`W/"etag"`}}},
+		{desc: "if-unmodified-since", header: http.Header{"If-Unmodified-Since": {after}}, body: true},
+		{desc: "if-unmodified-since modified", header: http.Header{"If-Unmodified-Since": {before}}},
+		{desc: "if-match takes precedence", header: http.Header{"If-Match": {etag}, "If-Unmodified-Since": {before}}, body: true},
+		{desc: "if-none-match", header: http.Header{"If-None-Match": {etag}}},
+		{desc: "if-none-match weak", header: http.Header{"If-None-Match": {`W/"etag"`}}},
+		{desc: "if-none-match any", header: http.Header{"If-None-Match": {"*"}}},
+		{desc: "if-none-match mismatch", header: http.Header{"If-None-Match": {`"other"`}}, body: true},
+		{desc: "if-modified-since", header: http.Header{"If-Modified-Since": {after}}},
+		{desc: "if-modified-since same second", header: http.Header{"If-Modified-Since": {modtime.Format(http.TimeFormat)}}},
+		{desc: "if-modified-since modified", header: http.Header{"If-Modified-Since": {before}}, body: true},
+		{desc: "if-none-match takes precedence", header: http.Header{"If-None-Match": {`"other"`}, "If-Modified-Since": {after}}, body: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			r := httptest.NewRequest(method, "/object", nil)
+			if tc.header != nil {
+				r.Header = tc.header
+			}
+
+			require.Equal(t, tc.body, servesBody(r, etag, modtime))
+
+			// servesBody must agree with what's actually served.
+			w := httptest.NewRecorder()
+			w.Header().Set("ETag", etag)
+			require.NoError(t, httpranger.ServeContent(ctx, w, r, "object", modtime, ranger.ByteRanger("content")))
+			require.Equal(t, tc.body, w.Body.Len() > 0, w.Code)
+		})
+	}
+}
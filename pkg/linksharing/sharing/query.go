@@ -0,0 +1,64 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package sharing
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/edge/pkg/errdata"
+)
+
+// knownQueryParams are the query parameters understood by standard
+// (non-hosting) requests.
+var knownQueryParams = map[string]struct{}{
+	"cursor":        {},
+	"download":      {},
+	"download-kind": {},
+	"expires":       {},
+	"include-stats": {},
+	"map":           {},
+	"path":          {},
+	"signature":     {},
+	"view":          {},
+	"width":         {},
+	"wrap":          {},
+}
+
+// validateQuery returns an error for the first unknown query parameter in q
+// if strict query parameter validation is enabled. Parameters of signed
+// requests (X-Amz-*) are always accepted.
+//
+// Hosting requests are not validated, as websites can use any query
+// parameters in their own scripts.
+func (handler *Handler) validateQuery(q url.Values) error {
+	if !handler.strictQueryParams {
+		return nil
+	}
+
+	names := make([]string, 0, len(q))
+	for name := range q {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, ok := knownQueryParams[name]; ok {
+			continue
+		}
+		if _, ok := handler.allowedQueryParams[name]; ok {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(name), "x-amz-") {
+			continue
+		}
+		return errdata.WithStatus(errs.New("unknown query parameter %q", name), http.StatusBadRequest)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package sharing
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"storj.io/edge/pkg/errdata"
+)
+
+func TestValidateQuery(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		query  string
+		strict bool
+		ok     bool
+	}{
+		{desc: "lenient unknown", query: "foo=bar&download=1", strict: false, ok: true},
+		{desc: "strict empty", query: "", strict: true, ok: true},
+		{desc: "strict known", query: "download=1&wrap=0&map=1&width=400&include-stats=no&cursor=a&path=b&view&download-kind=tar", strict: true, ok: true},
+		{desc: "strict signed", query: "X-Amz-Algorithm=AWS4-HMAC-SHA256&x-amz-signature=abc", strict: true, ok: true},
+		{desc: "strict allowed", query: "utm_source=newsletter", strict: true, ok: true},
+		{desc: "strict unknown", query: "download=1&cachebust=123", strict: true, ok: false},
+		{desc: "strict case sensitive", query: "Download=1", strict: true, ok: false},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			handler, err := NewHandler(zap.NewNop(), nil, nil, nil, Config{
+				URLBases:           []string{"http://test.test"},
+				ListPageLimit:      1,
+				StrictQueryParams:  tc.strict,
+				AllowedQueryParams: []string{"utm_source"},
+			})
+			require.NoError(t, err)
+
+			q, err := url.ParseQuery(tc.query)
+			require.NoError(t, err)
+
+			err = handler.validateQuery(q)
+			if tc.ok {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, http.StatusBadRequest, errdata.GetStatus(err, http.StatusOK))
+		})
+	}
+}
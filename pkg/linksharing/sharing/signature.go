@@ -0,0 +1,82 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package sharing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/edge/pkg/errdata"
+)
+
+const (
+	// expiresQueryParam is the query parameter of signed links holding the
+	// time, in seconds since the Unix epoch, after which they stop working.
+	expiresQueryParam = "expires"
+	// signatureQueryParam is the query parameter of signed links holding the
+	// signature of their path and expiration.
+	signatureQueryParam = "signature"
+)
+
+// SignURL returns a copy of link, a standard (non-hosting) linksharing URL,
+// signed with key so that it stops working after expires. The sign-url
+// command of linksharing signs links with it.
+//
+// The signature covers the path of the link, i.e. the access, bucket and key,
+// and the expiration, but not other query parameters, so that e.g. download=1
+// can still be added to or removed from signed links.
+func SignURL(key []byte, link *url.URL, expires time.Time) *url.URL {
+	signed := *link
+
+	q := signed.Query()
+	q.Del(signatureQueryParam)
+	q.Set(expiresQueryParam, strconv.FormatInt(expires.Unix(), 10))
+	q.Set(signatureQueryParam, signLink(key, signed.EscapedPath(), q.Get(expiresQueryParam)))
+	signed.RawQuery = q.Encode()
+
+	return &signed
+}
+
+func signLink(key []byte, path, expires string) string {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(expires + "\n" + path))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validateSignature returns an error with 403 Forbidden status if r doesn't
+// carry a signature or carries one that doesn't match or has expired.
+//
+// It does nothing if no signing key is configured.
+func (handler *Handler) validateSignature(r *http.Request, now time.Time) error {
+	if len(handler.signedLinksKey) == 0 {
+		return nil
+	}
+
+	q := r.URL.Query()
+	expires, signature := q.Get(expiresQueryParam), q.Get(signatureQueryParam)
+	if signature == "" {
+		return errdata.WithStatus(errs.New("missing link signature"), http.StatusForbidden)
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(signLink(handler.signedLinksKey, r.URL.EscapedPath(), expires))) {
+		return errdata.WithStatus(errs.New("invalid link signature"), http.StatusForbidden)
+	}
+
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return errdata.WithStatus(errs.New("invalid link expiration: %w", err), http.StatusForbidden)
+	}
+	if now.After(time.Unix(unix, 0)) {
+		return errdata.WithStatus(errs.New("link expired"), http.StatusForbidden)
+	}
+
+	return nil
+}
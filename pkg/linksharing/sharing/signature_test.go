@@ -0,0 +1,82 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package sharing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"storj.io/edge/pkg/errdata"
+)
+
+func TestValidateSignature(t *testing.T) {
+	key := []byte("secret")
+	now := time.Now()
+
+	link, err := url.Parse("http://test.test/raw/access/bucket/some%20key?download=1")
+	require.NoError(t, err)
+
+	signed := SignURL(key, link, now.Add(time.Hour))
+	require.Equal(t, "1", signed.Query().Get("download"))
+	require.NotEmpty(t, signed.Query().Get("signature"))
+	require.NotEqual(t, link.RawQuery, signed.RawQuery, "link must not be modified")
+
+	tampered := *signed
+	tampered.Path = "/raw/access/bucket/other"
+	tampered.RawPath = ""
+
+	extended := *signed
+	q := extended.Query()
+	q.Set("expires", "9999999999")
+	extended.RawQuery = q.Encode()
+
+	otherParams := *signed
+	q = otherParams.Query()
+	q.Del("download")
+	otherParams.RawQuery = q.Encode()
+
+	testCases := []struct {
+		desc string
+		link *url.URL
+		key  string
+		ok   bool
+	}{
+		{desc: "signed", link: signed, key: "secret", ok: true},
+		{desc: "signed, other query parameters", link: &otherParams, key: "secret", ok: true},
+		{desc: "signed, wrong key", link: signed, key: "other", ok: false},
+		{desc: "expired", link: SignURL(key, link, now.Add(-time.Second)), key: "secret", ok: false},
+		{desc: "tampered path", link: &tampered, key: "secret", ok: false},
+		{desc: "tampered expiration", link: &extended, key: "secret", ok: false},
+		{desc: "unsigned", link: link, key: "secret", ok: false},
+		{desc: "disabled", link: &tampered, key: "", ok: true},
+		{desc: "disabled, unsigned", link: link, key: "", ok: true},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			handler, err := NewHandler(zap.NewNop(), nil, nil, nil, Config{
+				URLBases:       []string{"http://test.test"},
+				ListPageLimit:  1,
+				SignedLinksKey: tc.key,
+			})
+			require.NoError(t, err)
+
+			r := httptest.NewRequest(http.MethodGet, tc.link.String(), nil)
+
+			err = handler.validateSignature(r, now)
+			if tc.ok {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, http.StatusForbidden, errdata.GetStatus(err, 0))
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/zeebo/errs"
 
@@ -21,6 +22,14 @@ func (handler *Handler) handleStandard(ctx context.Context, w http.ResponseWrite
 		return creds.err
 	}
 
+	if err := handler.validateQuery(r.URL.Query()); err != nil {
+		return err
+	}
+
+	if err := handler.validateSignature(r, time.Now()); err != nil {
+		return err
+	}
+
 	var pr parsedRequest
 	path := strings.TrimPrefix(r.URL.Path, "/")
 	switch {
@@ -5,7 +5,10 @@ package sharing
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,9 +20,10 @@ import (
 
 // TXTRecords fetches and caches linksharing DNS txt records.
 type TXTRecords struct {
-	maxTTL time.Duration
-	dns    *DNSClient
-	auth   *authclient.AuthClient
+	maxTTL      time.Duration
+	negativeTTL time.Duration
+	dns         *DNSClient
+	auth        *authclient.AuthClient
 
 	cache       sync.Map
 	updateLocks MutexGroup
@@ -32,6 +36,16 @@ type Result struct {
 	PublicProjectID  string
 	Root             string
 	TLS              bool
+	Precompressed    []string
+
+	// DefaultObject is the key, relative to Root, of the object served
+	// instead of missing objects with DefaultObjectStatus.
+	DefaultObject       string
+	DefaultObjectStatus int
+
+	// CanonicalHost is the host that requests arriving on other hosts are
+	// redirected to.
+	CanonicalHost string
 }
 
 type txtRecord struct {
@@ -46,14 +60,20 @@ type txtRecord struct {
 	// TODO: parts of this cache should be encrypted.
 	queryResult Result
 	expiration  time.Time
+
+	// err is set for domains that don't exist, which are cached for
+	// negativeTTL so that requests for them don't all hit the DNS server.
+	err error
 }
 
-// NewTXTRecords constructs a TXTRecords.
-func NewTXTRecords(maxTTL time.Duration, dns *DNSClient, auth *authclient.AuthClient) *TXTRecords {
+// NewTXTRecords constructs a TXTRecords. Lookups of domains that don't exist
+// are cached for negativeTTL; zero disables it.
+func NewTXTRecords(maxTTL, negativeTTL time.Duration, dns *DNSClient, auth *authclient.AuthClient) *TXTRecords {
 	return &TXTRecords{
-		maxTTL: maxTTL,
-		dns:    dns,
-		auth:   auth,
+		maxTTL:      maxTTL,
+		negativeTTL: negativeTTL,
+		dns:         dns,
+		auth:        auth,
 	}
 }
 
@@ -62,6 +82,8 @@ func NewTXTRecords(maxTTL time.Duration, dns *DNSClient, auth *authclient.AuthCl
 //   - access/grant
 //   - root/path
 //   - tls
+//   - precompressed
+//   - default-object and default-object-status
 //
 // TXT records from cache or DNS when applicable.
 //
@@ -94,6 +116,19 @@ func (records *TXTRecords) fetchAccessForHost(ctx context.Context, hostname stri
 
 	// there's something in the cache!
 	record := val.(*txtRecord)
+	if record.err != nil {
+		// the domain didn't exist. unlike for found records, we don't serve
+		// the expired value optimistically, as the domain has likely just
+		// been set up if it's being requested again.
+		if record.expiration.Before(time.Now()) {
+			record, err = records.updateCache(ctx, hostname, allowAccessGrant, record.expiration, clientIP)
+			if err != nil {
+				return Result{}, err
+			}
+			return record.queryResult, nil
+		}
+		return Result{}, record.err
+	}
 	if record.expiration.Before(time.Now()) {
 		// but it's expired. okay, this happens a lot and is usually going to
 		// return the same value. we're going to be optimistic and assume the
@@ -120,6 +155,9 @@ func (records *TXTRecords) fetchAccessForHost(ctx context.Context, hostname stri
 // nothing if the currently cached expiration is different than
 // currentExpiration. clientIP is the IP of the client that originated the
 // request.
+//
+// Concurrent calls for the same hostname are serialized, so that a burst of
+// requests for an uncached hostname results in a single DNS lookup.
 func (records *TXTRecords) updateCache(ctx context.Context, hostname string, allowAccessGrant bool, currentExpiration time.Time, clientIP string) (record *txtRecord, err error) {
 	defer mon.Task()(&ctx)(&err)
 	defer records.updateLocks.Lock(hostname)()
@@ -128,12 +166,20 @@ func (records *TXTRecords) updateCache(ctx context.Context, hostname string, all
 	if val, ok := records.cache.Load(hostname); ok {
 		record = val.(*txtRecord)
 		if currentExpiration.IsZero() || !record.expiration.Equal(currentExpiration) {
-			return record, nil
+			return record, record.err
 		}
 	}
 
 	record, err = records.queryAccessFromDNS(ctx, hostname, allowAccessGrant, clientIP)
 	if err != nil {
+		if errors.Is(err, errNXDomain) && records.negativeTTL > 0 {
+			mon.Event("txt_record_negative_cached")
+			records.cache.Store(hostname, &txtRecord{
+				expiration: time.Now().Add(records.negativeTTL),
+				err:        err,
+			})
+			return nil, err
+		}
 		records.cache.Delete(hostname)
 		return record, err
 	}
@@ -167,6 +213,13 @@ func (records *TXTRecords) queryAccessFromDNS(ctx context.Context, hostname stri
 		root = set.Lookup("storj-path")
 	}
 	tls, _ := strconv.ParseBool(set.Lookup("storj-tls"))
+	precompressed := parsePrecompressed(set.Lookup("storj-precompressed"))
+	defaultObject := strings.TrimPrefix(set.Lookup("storj-default-object"), "/")
+	defaultObjectStatus, err := parseDefaultObjectStatus(set.Lookup("storj-default-object-status"))
+	if err != nil {
+		return nil, errs.New("failure with hostname %q: %w", hostname, err)
+	}
+	canonicalHost := strings.ToLower(strings.TrimSuffix(set.Lookup("storj-canonical-host"), "."))
 
 	// NOTE(artur): due to cache shared among all clients per hostname for
 	// hosting requests, signed requests cannot be served. One client with a
@@ -190,7 +243,26 @@ func (records *TXTRecords) queryAccessFromDNS(ctx context.Context, hostname stri
 			PublicProjectID:  result.PublicProjectID,
 			Root:             root,
 			TLS:              tls,
+			Precompressed:    precompressed,
+
+			DefaultObject:       defaultObject,
+			DefaultObjectStatus: defaultObjectStatus,
+
+			CanonicalHost: canonicalHost,
 		},
 		expiration: time.Now().Add(ttl),
 	}, nil
 }
+
+// parseDefaultObjectStatus parses the status the default object is served
+// with, which is 200 OK unless it's set to 404 Not Found.
+func parseDefaultObjectStatus(value string) (int, error) {
+	switch value {
+	case "", strconv.Itoa(http.StatusOK):
+		return http.StatusOK, nil
+	case strconv.Itoa(http.StatusNotFound):
+		return http.StatusNotFound, nil
+	default:
+		return 0, errs.New("invalid storj-default-object-status %q: must be %d or %d", value, http.StatusOK, http.StatusNotFound)
+	}
+}
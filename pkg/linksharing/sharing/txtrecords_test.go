@@ -0,0 +1,116 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package sharing
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/base58"
+	"storj.io/common/testcontext"
+)
+
+func TestTXTRecordsNegativeCache(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	// the access looks like an access grant, so that it's parsed without the
+	// auth service, but it's invalid.
+	access := base58.CheckEncode([]byte("invalid"), 0)
+
+	static, err := ParseStaticDNSClientFromZoneFile([]byte("txt-example.com.	IN	TXT	storj-access:" + access))
+	require.NoError(t, err)
+	dns := &DNSClient{static: static}
+
+	t.Run("cached", func(t *testing.T) {
+		records := NewTXTRecords(time.Hour, time.Hour, dns, nil)
+
+		_, err := records.FetchAccessForHost(ctx, "missing.example.com", "")
+		require.ErrorIs(t, err, errNXDomain)
+
+		val, ok := records.cache.Load("missing.example.com")
+		require.True(t, ok)
+		require.ErrorIs(t, val.(*txtRecord).err, errNXDomain)
+
+		// the domain now exists, but it's still cached that it doesn't.
+		static.txt["txt-missing.example.com."] = static.txt["txt-example.com."]
+		defer delete(static.txt, "txt-missing.example.com.")
+
+		_, err = records.FetchAccessForHost(ctx, "missing.example.com", "")
+		require.ErrorIs(t, err, errNXDomain)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		records := NewTXTRecords(time.Hour, time.Hour, dns, nil)
+
+		_, err := records.FetchAccessForHost(ctx, "missing.example.com", "")
+		require.ErrorIs(t, err, errNXDomain)
+
+		val, _ := records.cache.Load("missing.example.com")
+		val.(*txtRecord).expiration = time.Now().Add(-time.Second)
+
+		static.txt["txt-missing.example.com."] = static.txt["txt-example.com."]
+		defer delete(static.txt, "txt-missing.example.com.")
+
+		// the domain is looked up again, which now fails for another reason.
+		_, err = records.FetchAccessForHost(ctx, "missing.example.com", "")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, errNXDomain)
+
+		_, ok := records.cache.Load("missing.example.com")
+		require.False(t, ok)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		records := NewTXTRecords(time.Hour, 0, dns, nil)
+
+		_, err := records.FetchAccessForHost(ctx, "missing.example.com", "")
+		require.ErrorIs(t, err, errNXDomain)
+
+		_, ok := records.cache.Load("missing.example.com")
+		require.False(t, ok)
+	})
+}
+
+func TestParseDefaultObjectStatus(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected int
+		invalid  bool
+	}{
+		{value: "", expected: http.StatusOK},
+		{value: "200", expected: http.StatusOK},
+		{value: "404", expected: http.StatusNotFound},
+		{value: "410", invalid: true},
+		{value: "500", invalid: true},
+		{value: "not-found", invalid: true},
+		{value: " 404", invalid: true},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			status, err := parseDefaultObjectStatus(tc.value)
+			if tc.invalid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, status)
+		})
+	}
+}
+
+func TestTXTRecordsInvalidDefaultObjectStatus(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	static, err := ParseStaticDNSClientFromZoneFile([]byte(
+		"txt-example.com.	IN	TXT	storj-default-object:placeholder.png\n" +
+			"txt-example.com.	IN	TXT	storj-default-object-status:500"))
+	require.NoError(t, err)
+
+	records := NewTXTRecords(time.Hour, 0, &DNSClient{static: static}, nil)
+
+	_, err = records.FetchAccessForHost(ctx, "example.com", "")
+	require.ErrorContains(t, err, "storj-default-object-status")
+}
@@ -0,0 +1,48 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package sharing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"storj.io/edge/pkg/bucketconfig"
+	"storj.io/edge/pkg/bucketwebsite"
+	"storj.io/edge/pkg/errdata"
+)
+
+// websiteConfig returns the website configuration set with PutBucketWebsite
+// for bucket of the project of creds, or nil if it has none.
+func (handler *Handler) websiteConfig(ctx context.Context, creds *credentials, bucket string) (_ *bucketwebsite.Configuration, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if handler.bucketConfigs == nil {
+		return nil, nil
+	}
+
+	projectKey, err := bucketconfig.ProjectKey(creds.publicProjectID)
+	if err != nil {
+		return nil, errdata.WithAction(errdata.WithStatus(err, http.StatusServiceUnavailable), "get website configuration")
+	}
+
+	data, err := handler.bucketConfigs.Get(ctx, projectKey, bucket, bucketwebsite.ConfigName)
+	if err != nil {
+		if errors.Is(err, bucketconfig.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, errdata.WithAction(err, "get website configuration")
+	}
+
+	config, err := bucketwebsite.Parse(bytes.NewReader(data))
+	if err != nil {
+		// the gateway validates configurations before storing them.
+		handler.log.Warn("ignoring invalid website configuration", zap.String("bucket", bucket), zap.Error(err))
+		return nil, nil
+	}
+	return &config, nil
+}
@@ -4,17 +4,31 @@
 package minio
 
 import (
+	"context"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/gorilla/mux"
+
+	"storj.io/edge/pkg/bucketconfig"
+	"storj.io/edge/pkg/bucketwebsite"
+	"storj.io/edge/pkg/server/middleware"
 	"storj.io/minio/cmd"
+	"storj.io/minio/cmd/logger"
+	"storj.io/minio/pkg/bucket/policy"
 )
 
 // objectAPIHandlersWrapper should be used to extend cmd.ObjectAPIHandlers.
 type objectAPIHandlersWrapper struct {
 	core               cmd.ObjectAPIHandlers
 	corsAllowedOrigins []string
+	corsAllowedHeaders []string
+	corsMaxAge         time.Duration
+	bucketConfigs      *bucketconfig.Store
 }
 
 func (h objectAPIHandlersWrapper) HeadObjectHandler(w http.ResponseWriter, r *http.Request) {
@@ -209,9 +223,47 @@ func (h objectAPIHandlersWrapper) PutBucketACLHandler(w http.ResponseWriter, r *
 	h.core.PutBucketACLHandler(w, r)
 }
 
+// GetBucketCorsHandler returns the CORS configuration stored for the bucket,
+// or, if there's none, the server-wide configuration.
 func (h objectAPIHandlersWrapper) GetBucketCorsHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	defer mon.Task()(&ctx)(nil)
+
+	ctx = cmd.NewContext(r, w, "GetBucketCors")
+
+	defer logger.AuditLog(ctx, w, r, nil)
+
+	bucket := mux.Vars(r)["bucket"]
+
+	// MinIO has no dedicated CORS actions; the bucket policy ones are the
+	// closest match.
+	if _, _, s3Error := cmd.CheckRequestAuthTypeCredential(ctx, r, policy.GetBucketPolicyAction, bucket, ""); s3Error != cmd.ErrNone {
+		cmd.WriteErrorResponse(ctx, w, cmd.GetAPIError(s3Error), r.URL, false)
+		return
+	}
+
+	projectKey, err := h.bucketConfigProjectKey(ctx, bucket)
+	if err != nil {
+		cmd.WriteErrorResponse(ctx, w, bucketConfigAPIError(ctx, err), r.URL, false)
+		return
+	}
+
+	config, err := h.bucketConfigs.Get(ctx, projectKey, bucket, corsConfigName)
+	if errors.Is(err, bucketconfig.ErrNotFound) {
+		cmd.WriteSuccessResponseXML(w, []byte(h.defaultCORSConfiguration()))
+		return
+	}
+	if err != nil {
+		cmd.WriteErrorResponse(ctx, w, cmd.ToAPIError(ctx, err), r.URL, false)
+		return
+	}
+
+	cmd.WriteSuccessResponseXML(w, config)
+}
+
+// defaultCORSConfiguration returns the server-wide CORS configuration as
+// applied by CorsHandler.
+func (h objectAPIHandlersWrapper) defaultCORSConfiguration() string {
 	var sb strings.Builder
 	sb.WriteString("<CORSConfiguration><CORSRule>")
 	for _, o := range h.corsAllowedOrigins {
@@ -223,27 +275,226 @@ func (h objectAPIHandlersWrapper) GetBucketCorsHandler(w http.ResponseWriter, r
 	for _, o := range allowedMethods {
 		fmt.Fprintf(&sb, "<AllowedMethod>%s</AllowedMethod>", o)
 	}
-	// CorsHandler's AllowedHeader list is not implemented here, because it includes "*"
-	sb.WriteString("<AllowedHeader>*</AllowedHeader><ExposeHeader>*</ExposeHeader></CORSRule></CORSConfiguration>")
-	cmd.WriteSuccessResponseXML(w, []byte(sb.String()))
+	if len(h.corsAllowedHeaders) == 0 {
+		// CorsHandler's default AllowedHeader list is not implemented here, because it includes "*"
+		sb.WriteString("<AllowedHeader>*</AllowedHeader>")
+	}
+	for _, o := range h.corsAllowedHeaders {
+		fmt.Fprintf(&sb, "<AllowedHeader>%s</AllowedHeader>", o)
+	}
+	sb.WriteString("<ExposeHeader>*</ExposeHeader>")
+	if seconds := int(h.corsMaxAge.Seconds()); seconds > 0 {
+		fmt.Fprintf(&sb, "<MaxAgeSeconds>%d</MaxAgeSeconds>", seconds)
+	}
+	sb.WriteString("</CORSRule></CORSConfiguration>")
+	return sb.String()
 }
 
+// PutBucketCorsHandler validates and stores the CORS configuration of the
+// bucket, which CorsHandler applies to requests to it.
 func (h objectAPIHandlersWrapper) PutBucketCorsHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	defer mon.Task()(&ctx)(nil)
-	cmd.WriteErrorResponse(r.Context(), w, cmd.GetAPIError(cmd.ErrNotImplemented), r.URL, false)
+
+	ctx = cmd.NewContext(r, w, "PutBucketCors")
+
+	defer logger.AuditLog(ctx, w, r, nil)
+
+	bucket := mux.Vars(r)["bucket"]
+
+	if _, _, s3Error := cmd.CheckRequestAuthTypeCredential(ctx, r, policy.PutBucketPolicyAction, bucket, ""); s3Error != cmd.ErrNone {
+		cmd.WriteErrorResponse(ctx, w, cmd.GetAPIError(s3Error), r.URL, false)
+		return
+	}
+
+	config, err := parseCORSConfiguration(r.Body)
+	if err != nil {
+		var configErr corsConfigError
+		if errors.As(err, &configErr) {
+			cmd.WriteErrorResponse(ctx, w, configErr.APIError, r.URL, false)
+			return
+		}
+		cmd.WriteErrorResponse(ctx, w, cmd.ToAPIError(ctx, err), r.URL, false)
+		return
+	}
+
+	data, err := xml.Marshal(config)
+	if err != nil {
+		cmd.WriteErrorResponse(ctx, w, cmd.ToAPIError(ctx, err), r.URL, false)
+		return
+	}
+
+	projectKey, err := h.bucketConfigProjectKey(ctx, bucket)
+	if err != nil {
+		cmd.WriteErrorResponse(ctx, w, bucketConfigAPIError(ctx, err), r.URL, false)
+		return
+	}
+
+	if err = h.bucketConfigs.Put(ctx, projectKey, bucket, corsConfigName, data); err != nil {
+		cmd.WriteErrorResponse(ctx, w, bucketConfigAPIError(ctx, err), r.URL, false)
+		return
+	}
+
+	cmd.WriteSuccessResponseXML(w, nil)
 }
 
+// DeleteBucketCorsHandler deletes the CORS configuration stored for the
+// bucket, reverting it to the server-wide configuration.
 func (h objectAPIHandlersWrapper) DeleteBucketCorsHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	defer mon.Task()(&ctx)(nil)
-	cmd.WriteErrorResponse(r.Context(), w, cmd.GetAPIError(cmd.ErrNotImplemented), r.URL, false)
+
+	ctx = cmd.NewContext(r, w, "DeleteBucketCors")
+
+	defer logger.AuditLog(ctx, w, r, nil)
+
+	bucket := mux.Vars(r)["bucket"]
+
+	if _, _, s3Error := cmd.CheckRequestAuthTypeCredential(ctx, r, policy.DeleteBucketPolicyAction, bucket, ""); s3Error != cmd.ErrNone {
+		cmd.WriteErrorResponse(ctx, w, cmd.GetAPIError(s3Error), r.URL, false)
+		return
+	}
+
+	projectKey, err := h.bucketConfigProjectKey(ctx, bucket)
+	if err != nil {
+		cmd.WriteErrorResponse(ctx, w, bucketConfigAPIError(ctx, err), r.URL, false)
+		return
+	}
+
+	if err = h.bucketConfigs.Delete(ctx, projectKey, bucket, corsConfigName); err != nil {
+		cmd.WriteErrorResponse(ctx, w, bucketConfigAPIError(ctx, err), r.URL, false)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bucketConfigProjectKey returns the key of the project of the request's
+// credentials in the bucket configuration store, after checking that bucket
+// exists in it.
+func (h objectAPIHandlersWrapper) bucketConfigProjectKey(ctx context.Context, bucket string) (string, error) {
+	if _, err := h.core.ObjectAPI().GetBucketInfo(ctx, bucket); err != nil {
+		return "", err
+	}
+
+	// without a store, there are no configurations of any project to key.
+	if h.bucketConfigs == nil {
+		return "", nil
+	}
+
+	credentials := middleware.GetAccess(ctx)
+	if credentials == nil || credentials.AccessGrant == "" {
+		return "", cmd.BucketNotFound{Bucket: bucket}
+	}
+	return bucketConfigProjectKey(credentials.AuthServiceResponse)
+}
+
+// bucketConfigAPIError returns the API error of err returned by the bucket
+// configuration store.
+func bucketConfigAPIError(ctx context.Context, err error) cmd.APIError {
+	switch {
+	case errors.Is(err, bucketconfig.ErrNotConfigured):
+		return cmd.GetAPIError(cmd.ErrNotImplemented)
+	case errors.Is(err, bucketconfig.ErrUnknownProject):
+		return cmd.APIError{
+			Code:           "ServiceUnavailable",
+			Description:    "The project of the credentials can't be determined yet. Please try again later.",
+			HTTPStatusCode: http.StatusServiceUnavailable,
+		}
+	}
+	return cmd.ToAPIError(ctx, err)
 }
 
+// GetBucketWebsiteHandler returns the website configuration stored for the
+// bucket.
 func (h objectAPIHandlersWrapper) GetBucketWebsiteHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	defer mon.Task()(&ctx)(nil)
-	h.core.GetBucketWebsiteHandler(w, r)
+
+	ctx = cmd.NewContext(r, w, "GetBucketWebsite")
+
+	defer logger.AuditLog(ctx, w, r, nil)
+
+	bucket := mux.Vars(r)["bucket"]
+
+	// MinIO has no dedicated website actions; the bucket policy ones are the
+	// closest match.
+	if _, _, s3Error := cmd.CheckRequestAuthTypeCredential(ctx, r, policy.GetBucketPolicyAction, bucket, ""); s3Error != cmd.ErrNone {
+		cmd.WriteErrorResponse(ctx, w, cmd.GetAPIError(s3Error), r.URL, false)
+		return
+	}
+
+	projectKey, err := h.bucketConfigProjectKey(ctx, bucket)
+	if err != nil {
+		cmd.WriteErrorResponse(ctx, w, bucketConfigAPIError(ctx, err), r.URL, false)
+		return
+	}
+
+	config, err := h.bucketConfigs.Get(ctx, projectKey, bucket, bucketwebsite.ConfigName)
+	if errors.Is(err, bucketconfig.ErrNotFound) {
+		cmd.WriteErrorResponse(ctx, w, cmd.GetAPIError(cmd.ErrNoSuchWebsiteConfiguration), r.URL, false)
+		return
+	}
+	if err != nil {
+		cmd.WriteErrorResponse(ctx, w, cmd.ToAPIError(ctx, err), r.URL, false)
+		return
+	}
+
+	cmd.WriteSuccessResponseXML(w, config)
+}
+
+// PutBucketWebsiteHandler validates and stores the website configuration of
+// the bucket, which linksharing consults when hosting a website from it.
+func (h objectAPIHandlersWrapper) PutBucketWebsiteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer mon.Task()(&ctx)(nil)
+
+	ctx = cmd.NewContext(r, w, "PutBucketWebsite")
+
+	defer logger.AuditLog(ctx, w, r, nil)
+
+	bucket := mux.Vars(r)["bucket"]
+
+	if _, _, s3Error := cmd.CheckRequestAuthTypeCredential(ctx, r, policy.PutBucketPolicyAction, bucket, ""); s3Error != cmd.ErrNone {
+		cmd.WriteErrorResponse(ctx, w, cmd.GetAPIError(s3Error), r.URL, false)
+		return
+	}
+
+	config, err := bucketwebsite.Parse(r.Body)
+	if err != nil {
+		switch {
+		case errors.Is(err, bucketwebsite.ErrMalformedXML):
+			cmd.WriteErrorResponse(ctx, w, cmd.GetAPIError(cmd.ErrMalformedXML), r.URL, false)
+		case bucketwebsite.Error.Has(err):
+			cmd.WriteErrorResponse(ctx, w, cmd.APIError{
+				Code:           "InvalidArgument",
+				Description:    err.Error(),
+				HTTPStatusCode: http.StatusBadRequest,
+			}, r.URL, false)
+		default:
+			cmd.WriteErrorResponse(ctx, w, cmd.ToAPIError(ctx, err), r.URL, false)
+		}
+		return
+	}
+
+	data, err := xml.Marshal(config)
+	if err != nil {
+		cmd.WriteErrorResponse(ctx, w, cmd.ToAPIError(ctx, err), r.URL, false)
+		return
+	}
+
+	projectKey, err := h.bucketConfigProjectKey(ctx, bucket)
+	if err != nil {
+		cmd.WriteErrorResponse(ctx, w, bucketConfigAPIError(ctx, err), r.URL, false)
+		return
+	}
+
+	if err = h.bucketConfigs.Put(ctx, projectKey, bucket, bucketwebsite.ConfigName, data); err != nil {
+		cmd.WriteErrorResponse(ctx, w, bucketConfigAPIError(ctx, err), r.URL, false)
+		return
+	}
+
+	cmd.WriteSuccessResponseXML(w, nil)
 }
 
 func (h objectAPIHandlersWrapper) GetBucketAccelerateHandler(w http.ResponseWriter, r *http.Request) {
@@ -270,10 +521,35 @@ func (h objectAPIHandlersWrapper) GetBucketTaggingHandler(w http.ResponseWriter,
 	h.core.GetBucketTaggingHandler(w, r)
 }
 
+// DeleteBucketWebsiteHandler deletes the website configuration stored for the
+// bucket.
 func (h objectAPIHandlersWrapper) DeleteBucketWebsiteHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	defer mon.Task()(&ctx)(nil)
-	h.core.DeleteBucketWebsiteHandler(w, r)
+
+	ctx = cmd.NewContext(r, w, "DeleteBucketWebsite")
+
+	defer logger.AuditLog(ctx, w, r, nil)
+
+	bucket := mux.Vars(r)["bucket"]
+
+	if _, _, s3Error := cmd.CheckRequestAuthTypeCredential(ctx, r, policy.DeleteBucketPolicyAction, bucket, ""); s3Error != cmd.ErrNone {
+		cmd.WriteErrorResponse(ctx, w, cmd.GetAPIError(s3Error), r.URL, false)
+		return
+	}
+
+	projectKey, err := h.bucketConfigProjectKey(ctx, bucket)
+	if err != nil {
+		cmd.WriteErrorResponse(ctx, w, bucketConfigAPIError(ctx, err), r.URL, false)
+		return
+	}
+
+	if err = h.bucketConfigs.Delete(ctx, projectKey, bucket, bucketwebsite.ConfigName); err != nil {
+		cmd.WriteErrorResponse(ctx, w, bucketConfigAPIError(ctx, err), r.URL, false)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (h objectAPIHandlersWrapper) DeleteBucketTaggingHandler(w http.ResponseWriter, r *http.Request) {
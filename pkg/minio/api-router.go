@@ -6,9 +6,11 @@ package minio
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 
+	"storj.io/edge/pkg/bucketconfig"
 	"storj.io/edge/pkg/server/gw"
 	"storj.io/edge/pkg/server/middleware"
 	"storj.io/minio/cmd"
@@ -16,11 +18,11 @@ import (
 )
 
 // RegisterAPIRouter - registers S3 compatible APIs.
-func RegisterAPIRouter(router *mux.Router, layer *gw.MultiTenancyLayer, domainNames []string, concurrentAllowed uint, corsAllowedOrigins []string) {
+func RegisterAPIRouter(router *mux.Router, layer *gw.MultiTenancyLayer, domainNames []string, concurrentAllowed uint, corsAllowedOrigins, corsAllowedHeaders []string, corsMaxAge time.Duration, bucketConfigs *bucketconfig.Store, defaultRegion string) {
 	api := objectAPIHandlersWrapper{cmd.ObjectAPIHandlers{
 		ObjectAPI: func() cmd.ObjectLayer { return layer },
 		CacheAPI:  func() cmd.CacheObjectLayer { return nil },
-	}, corsAllowedOrigins}
+	}, corsAllowedOrigins, corsAllowedHeaders, corsMaxAge, bucketConfigs}
 
 	// limit the conccurrency of uploads and downloads
 	limit := middleware.NewConcurrentRequestsLimiter(concurrentAllowed,
@@ -118,7 +120,7 @@ func RegisterAPIRouter(router *mux.Router, layer *gw.MultiTenancyLayer, domainNa
 		// Bucket operations
 		// GetBucketLocation
 		bucket.Methods(http.MethodGet).HandlerFunc(
-			cmd.MaxClients(cmd.CollectAPIStats("getbucketlocation", cmd.HTTPTraceAll(newGetBucketLocationHandler(layer))))).Queries("location", "")
+			cmd.MaxClients(cmd.CollectAPIStats("getbucketlocation", cmd.HTTPTraceAll(newGetBucketLocationHandler(layer, defaultRegion))))).Queries("location", "")
 		// GetBucketPolicy
 		bucket.Methods(http.MethodGet).HandlerFunc(
 			cmd.MaxClients(cmd.CollectAPIStats("getbucketpolicy", cmd.HTTPTraceAll(api.GetBucketPolicyHandler)))).Queries("policy", "")
@@ -151,18 +153,21 @@ func RegisterAPIRouter(router *mux.Router, layer *gw.MultiTenancyLayer, domainNa
 		// PutBucketACL -- this is a dummy call.
 		bucket.Methods(http.MethodPut).HandlerFunc(
 			cmd.MaxClients(cmd.CollectAPIStats("putbucketacl", cmd.HTTPTraceAll(api.PutBucketACLHandler)))).Queries("acl", "")
-		// GetBucketCors - this is a dummy call.
+		// GetBucketCors
 		bucket.Methods(http.MethodGet).HandlerFunc(
 			cmd.MaxClients(cmd.CollectAPIStats("getbucketcors", cmd.HTTPTraceAll(api.GetBucketCorsHandler)))).Queries("cors", "")
-		// PutBucketCors - this is a dummy call.
+		// PutBucketCors
 		bucket.Methods(http.MethodPut).HandlerFunc(
 			cmd.MaxClients(cmd.CollectAPIStats("putbucketcors", cmd.HTTPTraceAll(api.PutBucketCorsHandler)))).Queries("cors", "")
-		// DeleteBucketCors - this is a dummy call.
+		// DeleteBucketCors
 		bucket.Methods(http.MethodDelete).HandlerFunc(
 			cmd.MaxClients(cmd.CollectAPIStats("deletebucketcors", cmd.HTTPTraceAll(api.DeleteBucketCorsHandler)))).Queries("cors", "")
-		// GetBucketWebsiteHandler - this is a dummy call.
+		// GetBucketWebsiteHandler
 		bucket.Methods(http.MethodGet).HandlerFunc(
 			cmd.MaxClients(cmd.CollectAPIStats("getbucketwebsite", cmd.HTTPTraceAll(api.GetBucketWebsiteHandler)))).Queries("website", "")
+		// PutBucketWebsiteHandler
+		bucket.Methods(http.MethodPut).HandlerFunc(
+			cmd.MaxClients(cmd.CollectAPIStats("putbucketwebsite", cmd.HTTPTraceAll(api.PutBucketWebsiteHandler)))).Queries("website", "")
 		// GetBucketAccelerateHandler - this is a dummy call.
 		bucket.Methods(http.MethodGet).HandlerFunc(
 			cmd.MaxClients(cmd.CollectAPIStats("getbucketaccelerate", cmd.HTTPTraceAll(api.GetBucketAccelerateHandler)))).Queries("accelerate", "")
@@ -0,0 +1,241 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package minio
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"storj.io/minio/cmd"
+	"storj.io/minio/pkg/wildcard"
+)
+
+const (
+	// corsConfigName is the name a bucket's CORS configuration is stored
+	// under in the bucket configuration store.
+	corsConfigName = "cors.xml"
+
+	// maxCORSConfigSize is the maximum size of a CORS configuration, as
+	// documented for AWS S3.
+	maxCORSConfigSize = 64 << 10
+
+	// maxCORSRules is the maximum number of rules in a CORS configuration, as
+	// documented for AWS S3.
+	maxCORSRules = 100
+
+	// maxCORSRuleOrigins is the maximum number of allowed origins in a CORS
+	// rule. Every origin is matched against the Origin header of requests, so
+	// this bounds the work done for each of them.
+	maxCORSRuleOrigins = 100
+)
+
+// corsConfiguration is the CORSConfiguration XML document of the
+// PutBucketCors and GetBucketCors actions.
+type corsConfiguration struct {
+	XMLName xml.Name   `xml:"CORSConfiguration"`
+	Rules   []corsRule `xml:"CORSRule"`
+}
+
+// corsRule is a single CORSRule of a CORSConfiguration.
+type corsRule struct {
+	ID             string   `xml:"ID,omitempty"`
+	AllowedOrigins []string `xml:"AllowedOrigin"`
+	AllowedMethods []string `xml:"AllowedMethod"`
+	AllowedHeaders []string `xml:"AllowedHeader,omitempty"`
+	ExposeHeaders  []string `xml:"ExposeHeader,omitempty"`
+	MaxAgeSeconds  *int     `xml:"MaxAgeSeconds,omitempty"`
+}
+
+// parseCORSConfiguration parses and validates a CORSConfiguration XML
+// document.
+func parseCORSConfiguration(r io.Reader) (config corsConfiguration, err error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxCORSConfigSize+1))
+	if err != nil {
+		return corsConfiguration{}, err
+	}
+	if len(data) > maxCORSConfigSize {
+		return corsConfiguration{}, invalidRequestError("The CORS configuration must not exceed 64 KB.")
+	}
+
+	if err = xml.Unmarshal(data, &config); err != nil {
+		return corsConfiguration{}, corsConfigError{cmd.GetAPIError(cmd.ErrMalformedXML)}
+	}
+
+	return config, config.validate()
+}
+
+// validate returns an error if config isn't a valid CORS configuration.
+func (config corsConfiguration) validate() error {
+	if len(config.Rules) == 0 {
+		return corsConfigError{cmd.GetAPIError(cmd.ErrMalformedXML)}
+	}
+	if len(config.Rules) > maxCORSRules {
+		return invalidRequestError(fmt.Sprintf("The CORS configuration must not have more than %d rules.", maxCORSRules))
+	}
+
+	for _, rule := range config.Rules {
+		if len(rule.AllowedOrigins) == 0 || len(rule.AllowedMethods) == 0 {
+			return corsConfigError{cmd.GetAPIError(cmd.ErrMalformedXML)}
+		}
+		if len(rule.AllowedOrigins) > maxCORSRuleOrigins {
+			return invalidRequestError(fmt.Sprintf("A CORS rule must not have more than %d AllowedOrigin elements.", maxCORSRuleOrigins))
+		}
+		for _, origin := range rule.AllowedOrigins {
+			if strings.Count(origin, "*") > 1 {
+				return invalidRequestError(fmt.Sprintf("AllowedOrigin %q can not have more than one wildcard.", origin))
+			}
+		}
+		for _, method := range rule.AllowedMethods {
+			switch method {
+			case http.MethodGet, http.MethodPut, http.MethodHead, http.MethodPost, http.MethodDelete:
+			default:
+				return invalidRequestError(fmt.Sprintf("Found unsupported HTTP method in CORS config. Unsupported method is %s", method))
+			}
+		}
+		for _, header := range rule.AllowedHeaders {
+			if strings.Count(header, "*") > 1 {
+				return invalidRequestError(fmt.Sprintf("AllowedHeader %q can not have more than one wildcard.", header))
+			}
+		}
+		if rule.MaxAgeSeconds != nil && *rule.MaxAgeSeconds < 0 {
+			return invalidRequestError("MaxAgeSeconds must not be negative.")
+		}
+	}
+
+	return nil
+}
+
+// corsConfigError is the error of an invalid CORS configuration. It holds
+// the API error to respond with, as cmd.APIError isn't an error itself.
+type corsConfigError struct {
+	cmd.APIError
+}
+
+func (err corsConfigError) Error() string {
+	return err.Description
+}
+
+// invalidRequestError returns an InvalidRequest API error with description.
+func invalidRequestError(description string) corsConfigError {
+	return corsConfigError{cmd.APIError{
+		Code:           "InvalidRequest",
+		Description:    description,
+		HTTPStatusCode: http.StatusBadRequest,
+	}}
+}
+
+// match returns the first rule of config allowing a request from origin with
+// method and headers.
+func (config corsConfiguration) match(origin, method string, headers []string) (corsRule, bool) {
+	for _, rule := range config.Rules {
+		if rule.allowsOrigin(origin) && rule.allowsMethod(method) && rule.allowsHeaders(headers) {
+			return rule, true
+		}
+	}
+	return corsRule{}, false
+}
+
+func (rule corsRule) allowsOrigin(origin string) bool {
+	for _, allowed := range rule.AllowedOrigins {
+		if wildcard.MatchSimple(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rule corsRule) allowsMethod(method string) bool {
+	for _, allowed := range rule.AllowedMethods {
+		if allowed == method {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsHeaders returns whether every header is matched by an AllowedHeader
+// of rule. Header names are case-insensitive.
+func (rule corsRule) allowsHeaders(headers []string) bool {
+	for _, header := range headers {
+		header = strings.ToLower(header)
+		allowed := false
+		for _, pattern := range rule.AllowedHeaders {
+			if wildcard.MatchSimple(strings.ToLower(pattern), header) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// allowOrigin sets the Access-Control-Allow-Origin header of a response to a
+// request from origin allowed by rule.
+func (rule corsRule) allowOrigin(h http.Header, origin string) {
+	for _, allowed := range rule.AllowedOrigins {
+		if allowed == "*" {
+			h.Set("Access-Control-Allow-Origin", "*")
+			return
+		}
+	}
+	h.Set("Access-Control-Allow-Origin", origin)
+	h.Set("Access-Control-Allow-Credentials", "true")
+}
+
+// serveHTTP applies config to r. Preflight requests are answered directly,
+// others are passed to next with the CORS headers of the matching rule.
+func (config corsConfiguration) serveHTTP(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	origin := r.Header.Get("Origin")
+	h := w.Header()
+
+	if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+		h.Add("Vary", "Origin")
+		if rule, ok := config.match(origin, r.Method, nil); ok {
+			rule.allowOrigin(h, origin)
+			if len(rule.ExposeHeaders) > 0 {
+				h.Set("Access-Control-Expose-Headers", strings.Join(rule.ExposeHeaders, ", "))
+			}
+		}
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	var headers []string
+	for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			headers = append(headers, header)
+		}
+	}
+
+	h.Add("Vary", "Origin")
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+
+	rule, ok := config.match(origin, r.Header.Get("Access-Control-Request-Method"), headers)
+	if !ok {
+		cmd.WriteErrorResponse(r.Context(), w, cmd.APIError{
+			Code:           "AccessForbidden",
+			Description:    "CORSResponse: This CORS request is not allowed. This is usually because the evaluation of Origin, request method / Access-Control-Request-Method or Access-Control-Request-Headers are not whitelisted by the resource's CORS spec.",
+			HTTPStatusCode: http.StatusForbidden,
+		}, r.URL, false)
+		return
+	}
+
+	rule.allowOrigin(h, origin)
+	h.Set("Access-Control-Allow-Methods", strings.Join(rule.AllowedMethods, ", "))
+	if len(headers) > 0 {
+		h.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+	}
+	if rule.MaxAgeSeconds != nil {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(*rule.MaxAgeSeconds))
+	}
+	w.WriteHeader(http.StatusOK)
+}
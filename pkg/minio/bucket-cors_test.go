@@ -0,0 +1,219 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package minio
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCORSConfiguration(t *testing.T) {
+	maxAge := 3600
+
+	config, err := parseCORSConfiguration(strings.NewReader(`<CORSConfiguration>
+		<CORSRule>
+			<ID>app</ID>
+			<AllowedOrigin>https://*.example.com</AllowedOrigin>
+			<AllowedMethod>GET</AllowedMethod>
+			<AllowedMethod>PUT</AllowedMethod>
+			<AllowedHeader>x-amz-*</AllowedHeader>
+			<ExposeHeader>ETag</ExposeHeader>
+			<MaxAgeSeconds>3600</MaxAgeSeconds>
+		</CORSRule>
+		<CORSRule>
+			<AllowedOrigin>*</AllowedOrigin>
+			<AllowedMethod>HEAD</AllowedMethod>
+		</CORSRule>
+	</CORSConfiguration>`))
+	require.NoError(t, err)
+	require.Equal(t, []corsRule{
+		{
+			ID:             "app",
+			AllowedOrigins: []string{"https://*.example.com"},
+			AllowedMethods: []string{"GET", "PUT"},
+			AllowedHeaders: []string{"x-amz-*"},
+			ExposeHeaders:  []string{"ETag"},
+			MaxAgeSeconds:  &maxAge,
+		},
+		{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"HEAD"},
+		},
+	}, config.Rules)
+
+	// the stored configuration parses back to the same configuration.
+	data, err := xml.Marshal(config)
+	require.NoError(t, err)
+	reparsed, err := parseCORSConfiguration(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	require.Equal(t, config.Rules, reparsed.Rules)
+}
+
+func TestParseCORSConfigurationInvalid(t *testing.T) {
+	rule := func(inner string) string {
+		return "<CORSConfiguration><CORSRule>" + inner + "</CORSRule></CORSConfiguration>"
+	}
+
+	testCases := []struct {
+		desc string
+		body string
+		code string
+	}{
+		{desc: "malformed", body: "<CORSConfiguration><CORSRule>", code: "MalformedXML"},
+		{desc: "no rules", body: "<CORSConfiguration></CORSConfiguration>", code: "MalformedXML"},
+		{desc: "no origin", body: rule("<AllowedMethod>GET</AllowedMethod>"), code: "MalformedXML"},
+		{desc: "no method", body: rule("<AllowedOrigin>*</AllowedOrigin>"), code: "MalformedXML"},
+		{desc: "unsupported method", body: rule("<AllowedOrigin>*</AllowedOrigin><AllowedMethod>PATCH</AllowedMethod>"), code: "InvalidRequest"},
+		{desc: "two origin wildcards", body: rule("<AllowedOrigin>*.*</AllowedOrigin><AllowedMethod>GET</AllowedMethod>"), code: "InvalidRequest"},
+		{desc: "two header wildcards", body: rule("<AllowedOrigin>*</AllowedOrigin><AllowedMethod>GET</AllowedMethod><AllowedHeader>**</AllowedHeader>"), code: "InvalidRequest"},
+		{desc: "negative max age", body: rule("<AllowedOrigin>*</AllowedOrigin><AllowedMethod>GET</AllowedMethod><MaxAgeSeconds>-1</MaxAgeSeconds>"), code: "InvalidRequest"},
+		{desc: "lowercase method", body: rule("<AllowedOrigin>*</AllowedOrigin><AllowedMethod>get</AllowedMethod>"), code: "InvalidRequest"},
+		{desc: "too many origins", body: rule(strings.Repeat("<AllowedOrigin>*</AllowedOrigin>", maxCORSRuleOrigins+1) + "<AllowedMethod>GET</AllowedMethod>"), code: "InvalidRequest"},
+		{desc: "too many rules", body: "<CORSConfiguration>" + strings.Repeat("<CORSRule><AllowedOrigin>*</AllowedOrigin><AllowedMethod>GET</AllowedMethod></CORSRule>", maxCORSRules+1) + "</CORSConfiguration>", code: "InvalidRequest"},
+		{desc: "too large", body: rule("<AllowedOrigin>" + strings.Repeat("a", maxCORSConfigSize) + "</AllowedOrigin><AllowedMethod>GET</AllowedMethod>"), code: "InvalidRequest"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := parseCORSConfiguration(strings.NewReader(tc.body))
+			// cmd.APIError isn't an error, so it's wrapped in corsConfigError.
+			var configErr corsConfigError
+			require.ErrorAs(t, err, &configErr)
+			require.Equal(t, http.StatusBadRequest, configErr.HTTPStatusCode)
+			require.Equal(t, tc.code, configErr.Code)
+		})
+	}
+}
+
+func TestDefaultCORSConfiguration(t *testing.T) {
+	h := objectAPIHandlersWrapper{corsAllowedOrigins: []string{"*"}}
+
+	require.Contains(t, h.defaultCORSConfiguration(), "<AllowedHeader>*</AllowedHeader>")
+	require.NotContains(t, h.defaultCORSConfiguration(), "MaxAgeSeconds")
+
+	h.corsAllowedHeaders = []string{"Authorization", "X-Amz-*"}
+	h.corsMaxAge = 10 * time.Minute
+
+	var parsed corsConfiguration
+	require.NoError(t, xml.Unmarshal([]byte(h.defaultCORSConfiguration()), &parsed))
+	require.Len(t, parsed.Rules, 1)
+	require.Equal(t, []string{"*"}, parsed.Rules[0].AllowedOrigins)
+	require.Equal(t, []string{"Authorization", "X-Amz-*"}, parsed.Rules[0].AllowedHeaders)
+	require.NotNil(t, parsed.Rules[0].MaxAgeSeconds)
+	require.Equal(t, 600, *parsed.Rules[0].MaxAgeSeconds)
+}
+
+func TestCORSConfigurationServeHTTP(t *testing.T) {
+	maxAge := 600
+	config := corsConfiguration{Rules: []corsRule{
+		{
+			AllowedOrigins: []string{"https://*.example.com"},
+			AllowedMethods: []string{"GET", "PUT"},
+			AllowedHeaders: []string{"x-amz-*", "Content-Type"},
+			ExposeHeaders:  []string{"ETag"},
+			MaxAgeSeconds:  &maxAge,
+		},
+		{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"HEAD"},
+		},
+	}}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	serve := func(method, origin string, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/bucket/key", nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		r.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		config.serveHTTP(rec, r, next)
+		return rec
+	}
+
+	t.Run("preflight allowed", func(t *testing.T) {
+		rec := serve(http.MethodOptions, "https://app.example.com", http.Header{
+			"Access-Control-Request-Method":  {"PUT"},
+			"Access-Control-Request-Headers": {"X-Amz-Date, content-type"},
+		})
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+		require.Equal(t, "GET, PUT", rec.Header().Get("Access-Control-Allow-Methods"))
+		require.Equal(t, "X-Amz-Date, content-type", rec.Header().Get("Access-Control-Allow-Headers"))
+		require.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+		require.Contains(t, rec.Header().Values("Vary"), "Origin")
+	})
+
+	t.Run("preflight wildcard origin", func(t *testing.T) {
+		rec := serve(http.MethodOptions, "https://other.com", http.Header{
+			"Access-Control-Request-Method": {"HEAD"},
+		})
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+		require.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	for _, tc := range []struct {
+		desc    string
+		origin  string
+		method  string
+		headers string
+	}{
+		{desc: "origin", origin: "https://example.org", method: "GET"},
+		{desc: "method", origin: "https://app.example.com", method: "DELETE"},
+		{desc: "header", origin: "https://app.example.com", method: "GET", headers: "Authorization"},
+	} {
+		tc := tc
+		t.Run("preflight denied by "+tc.desc, func(t *testing.T) {
+			rec := serve(http.MethodOptions, tc.origin, http.Header{
+				"Access-Control-Request-Method":  {tc.method},
+				"Access-Control-Request-Headers": {tc.headers},
+			})
+			require.Equal(t, http.StatusForbidden, rec.Code)
+			require.Contains(t, rec.Body.String(), "AccessForbidden")
+			require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+		})
+	}
+
+	t.Run("request allowed", func(t *testing.T) {
+		rec := serve(http.MethodGet, "https://app.example.com", nil)
+		require.Equal(t, http.StatusTeapot, rec.Code)
+		require.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "ETag", rec.Header().Get("Access-Control-Expose-Headers"))
+	})
+
+	t.Run("request not allowed", func(t *testing.T) {
+		rec := serve(http.MethodDelete, "https://app.example.com", nil)
+		require.Equal(t, http.StatusTeapot, rec.Code)
+		require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
+
+func TestBucketCORSBucketOf(t *testing.T) {
+	b := &BucketCORS{DomainNames: []string{"gateway.local", "s3.example.com"}}
+
+	for _, tc := range []struct {
+		url    string
+		bucket string
+	}{
+		{url: "http://gateway.local/bucket/key", bucket: "bucket"},
+		{url: "http://gateway.local:7777/bucket", bucket: "bucket"},
+		{url: "http://bucket.gateway.local/key", bucket: "bucket"},
+		{url: "http://my.bucket.s3.example.com:443/key", bucket: "my.bucket"},
+		{url: "http://gateway.local/", bucket: ""},
+	} {
+		r := httptest.NewRequest(http.MethodOptions, tc.url, nil)
+		require.Equal(t, tc.bucket, b.bucketOf(r), tc.url)
+	}
+}
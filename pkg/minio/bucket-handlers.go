@@ -43,7 +43,9 @@ func newListBucketsWithAttributionHandler(layer *gw.MultiTenancyLayer) http.Hand
 	}
 }
 
-func newGetBucketLocationHandler(layer *gw.MultiTenancyLayer) http.HandlerFunc {
+// newGetBucketLocationHandler implements GET operation, returning the location
+// of a bucket or defaultRegion if its placement has none.
+func newGetBucketLocationHandler(layer *gw.MultiTenancyLayer, defaultRegion string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		defer mon.Task()(&ctx)(nil)
@@ -65,6 +67,9 @@ func newGetBucketLocationHandler(layer *gw.MultiTenancyLayer) http.HandlerFunc {
 			cmd.WriteErrorResponse(ctx, w, cmd.ToAPIError(ctx, err), r.URL, false)
 			return
 		}
+		if location == "" {
+			location = defaultRegion
+		}
 
 		cmd.WriteSuccessResponseXML(w, cmd.EncodeResponse(cmd.LocationResponse{
 			Location: location,
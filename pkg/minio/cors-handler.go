@@ -20,11 +20,19 @@
 package minio
 
 import (
+	"bytes"
+	"net"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 
+	"storj.io/edge/pkg/authclient"
+	"storj.io/edge/pkg/bucketconfig"
+	"storj.io/edge/pkg/server/middleware"
+	"storj.io/edge/pkg/trustedip"
 	"storj.io/minio/cmd"
 	xhttp "storj.io/minio/cmd/http"
 	"storj.io/minio/cmd/logger"
@@ -32,7 +40,13 @@ import (
 )
 
 // CorsHandler handler for CORS (Cross Origin Resource Sharing).
-func CorsHandler(allowedOrigins []string) mux.MiddlewareFunc {
+//
+// If allowedHeaders is empty, any header is allowed. If maxAge is zero,
+// browsers aren't told how long they may cache preflight results.
+//
+// Requests to buckets with a CORS configuration stored in bucketCORS are
+// handled according to it instead of the server-wide configuration.
+func CorsHandler(allowedOrigins, allowedHeaders []string, maxAge time.Duration, bucketCORS *BucketCORS) mux.MiddlewareFunc {
 	return func(handler http.Handler) http.Handler {
 		commonS3Headers := []string{
 			xhttp.Date,
@@ -56,7 +70,12 @@ func CorsHandler(allowedOrigins []string) mux.MiddlewareFunc {
 			"*",
 		}
 
-		return cors.New(cors.Options{
+		headers := allowedHeaders
+		if len(headers) == 0 {
+			headers = commonS3Headers
+		}
+
+		global := cors.New(cors.Options{
 			AllowOriginFunc: func(origin string) bool {
 				for _, allowedOrigin := range allowedOrigins {
 					if wildcard.MatchSimple(allowedOrigin, origin) {
@@ -74,11 +93,100 @@ func CorsHandler(allowedOrigins []string) mux.MiddlewareFunc {
 				http.MethodOptions,
 				http.MethodPatch,
 			},
-			AllowedHeaders:   commonS3Headers,
+			AllowedHeaders:   headers,
 			ExposedHeaders:   commonS3Headers,
+			MaxAge:           int(maxAge.Seconds()),
 			AllowCredentials: true,
 		}).Handler(handler)
+
+		if bucketCORS == nil || bucketCORS.AuthClient == nil || bucketCORS.Store == nil {
+			return global
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Origin") != "" {
+				if config, ok := bucketCORS.lookup(r); ok {
+					config.serveHTTP(w, r, handler)
+					return
+				}
+			}
+			global.ServeHTTP(w, r)
+		})
+	}
+}
+
+// BucketCORS looks up the CORS configurations stored for buckets.
+//
+// A bucket's project is only known from the credentials of a request.
+// Browsers send preflight requests without the Authorization header, so only
+// preflight requests of presigned URLs are answered according to the stored
+// configuration; others are answered using the server-wide configuration.
+type BucketCORS struct {
+	AuthClient  *authclient.AuthClient
+	TrustedIPs  trustedip.List
+	DomainNames []string
+	Store       *bucketconfig.Store
+}
+
+// lookup returns the CORS configuration stored for the bucket r is sent to,
+// if it can be determined.
+func (b *BucketCORS) lookup(r *http.Request) (_ corsConfiguration, ok bool) {
+	ctx := r.Context()
+	defer mon.Task()(&ctx)(nil)
+
+	bucket := b.bucketOf(r)
+	if bucket == "" {
+		return corsConfiguration{}, false
+	}
+
+	accessKeyID, err := middleware.GetAccessKeyID(r)
+	if err != nil {
+		return corsConfiguration{}, false
 	}
+
+	authResponse, err := b.AuthClient.ResolveWithCache(ctx, accessKeyID, trustedip.GetClientIP(b.TrustedIPs, r))
+	if err != nil {
+		return corsConfiguration{}, false
+	}
+
+	projectKey, err := bucketConfigProjectKey(authResponse)
+	if err != nil {
+		return corsConfiguration{}, false
+	}
+
+	data, err := b.Store.Get(ctx, projectKey, bucket, corsConfigName)
+	if err != nil {
+		return corsConfiguration{}, false
+	}
+
+	config, err := parseCORSConfiguration(bytes.NewReader(data))
+	if err != nil {
+		return corsConfiguration{}, false
+	}
+	return config, true
+}
+
+// bucketOf returns the bucket r is sent to, addressed either by a virtual
+// host of one of the domain names or by the first path segment.
+func (b *BucketCORS) bucketOf(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	for _, domain := range b.DomainNames {
+		if bucket, ok := strings.CutSuffix(host, "."+domain); ok && bucket != "" {
+			return bucket
+		}
+	}
+
+	bucket, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	return bucket
+}
+
+// bucketConfigProjectKey returns the key of the project of authResponse in
+// the bucket configuration store.
+func bucketConfigProjectKey(authResponse authclient.AuthServiceResponse) (string, error) {
+	return bucketconfig.ProjectKey(authResponse.PublicProjectID)
 }
 
 // CriticalErrorHandler handles critical server failures caused by
@@ -9,6 +9,10 @@ import (
 	"storj.io/common/accesslogs"
 	"storj.io/common/memory"
 	"storj.io/edge/pkg/authclient"
+	"storj.io/edge/pkg/bucketconfig"
+	"storj.io/edge/pkg/health"
+	"storj.io/edge/pkg/server/gw"
+	"storj.io/edge/pkg/server/middleware"
 	"storj.io/edge/pkg/uplinkutil"
 	"storj.io/gateway/miniogw"
 )
@@ -25,16 +29,32 @@ type Config struct {
 	Server               AddrConfig
 	CertDir              string        `help:"directory path to search for TLS certificates" default:"$CONFDIR/certs"`
 	InsecureDisableTLS   bool          `help:"listen using insecure connections" releaseDefault:"false" devDefault:"true"`
+	CertReloadInterval   time.Duration `help:"how often to check the certificates in --cert-dir for changes and reload them without a restart; certificates added to or removed from --cert-dir still require a restart. 0 disables reloading" default:"1m0s"`
+	MinTLSVersion        string        `help:"minimum TLS version accepted: 1.2 or 1.3" default:"1.2"`
+	ClientAuth           string        `help:"whether clients authenticate with certificates signed by --client-ca-file on the TLS listeners: verify-if-given or require; empty disables it"`
+	ClientCAFile         string        `help:"path to a file containing the PEM-encoded CA certificates client certificates are verified with"`
+	OCSPStapling         bool          `help:"staple OCSP responses to the certificates from --cert-dir; certificates managed by CertMagic always have them stapled" default:"false"`
 	DomainName           string        `help:"comma-separated domain suffixes to serve on" releaseDefault:"" devDefault:"localhost"`
+	DefaultRegion        string        `help:"region GetBucketLocation reports for buckets whose placement has no location; signatures are accepted for any region" default:"us-east-1"`
 	OptionalDomainName   string        `help:"comma-separated optional domain suffixes to serve on, certificate errors are not fatal"`
 	CorsOrigins          string        `help:"list of domains (comma separated) other than the gateway's domain, from which a browser should permit loading resources requested from the gateway" default:"*"`
+	CorsAllowedHeaders   string        `help:"list of request headers (comma separated) a browser should permit in requests to the gateway from other domains; empty permits any header"`
+	CorsMaxAge           time.Duration `help:"how long a browser may cache the results of a CORS preflight request; zero leaves it up to the browser" default:"0s"`
 	EncodeInMemory       bool          `help:"tells libuplink to perform in-memory encoding on file upload" releaseDefault:"true" devDefault:"true"`
-	ClientTrustedIPSList []string      `help:"list of clients IPs (without port and comma separated) which are trusted; usually used when the service run behinds gateways, load balancers, etc."`
+	ClientTrustedIPSList []string      `help:"list of clients IPs or CIDR ranges (without port and comma separated) which are trusted; usually used when the service run behinds gateways, load balancers, etc."`
+	ClientTrustedHops    int           `help:"number of proxies in front of the service, including the one connecting to it, whose X-Forwarded-For entries are skipped from the right to find the client IP; 0 uses the first entry" default:"0"`
 	UseClientIPHeaders   bool          `help:"use the headers sent by the client to identify its IP. When true the list of IPs set by --client-trusted-ips-list, when not empty, is used" default:"true"`
 	InsecureLogAll       bool          `help:"insecurely log all errors, paths, and headers" default:"false"`
+	LogObjectPaths       bool          `help:"log bucket names and object keys of requests; unlike --insecure-log-all, confidential headers and query parameters stay sanitized" default:"false"`
+	RedactedHeaders      []string      `help:"list of additional request and response headers (comma separated) whose values are never logged, not even with --insecure-log-all; Authorization, Cookie, Proxy-Authorization, Set-Cookie and X-Amz-Security-Token always are"`
 	IdleTimeout          time.Duration `help:"maximum time to wait for the next request" default:"60s"`
+	ReadHeaderTimeout    time.Duration `help:"maximum time to read request headers; zero means no timeout" default:"0s"`
+	MaxConcurrentStreams int           `help:"maximum number of concurrent HTTP/2 streams per connection; zero uses the default of 250" default:"0"`
+	MaxPutObjectSize     memory.Size   `help:"maximum size of the objects of single PutObject requests and POST policy uploads, larger ones fail with EntityTooLarge; multipart uploads aren't limited. 0 disables the limit" default:"0"`
 	ShutdownDelay        time.Duration `help:"time to delay server shutdown while returning 503s on the health endpoint" devDefault:"1s" releaseDefault:"45s"`
 	DisableHTTP2         bool          `help:"whether support for HTTP/2 should be disabled" default:"false"`
+	HostRewrites         []string      `help:"list of host rewrites (comma separated) applied before virtual-host-style bucket parsing. Usage (colon-delimited): external_host:canonical_host. Subdomains of external_host are rewritten to subdomains of canonical_host, which should be one of --domain-name"`
+	LogOperations        string        `help:"which operations server access logs and events are emitted for: all, mutating or reads" default:"all"`
 	ServerAccessLogging  []string      `help:"list of project IDs and buckets which have access logging enabled. Usage (colon-delimited): watched_project_id:watched_bucket:destination_bucket:destination_access_grant:destination_prefix. destination_prefix can be empty"`
 
 	Auth                    authclient.Config
@@ -46,11 +66,24 @@ type Config struct {
 	CertMagic               certMagic
 	StartupCheck            startupCheck
 	AccessLogsProcessor     accesslogs.Options
+	UploadFanOut            gw.FanOutConfig
+	UploadParts             gw.PartUploadsConfig
+	Health                  health.Config
+	ProgressEvents          middleware.ProgressEventsConfig
+	ErrorResponses          middleware.ErrorResponsesConfig
+	ChecksumTrailers        middleware.ChecksumTrailersConfig
+	RateLimit               middleware.RateLimitConfig
+	BucketMetrics           middleware.BucketMetricsConfig
+	BucketConfigs           bucketconfig.Config
+	RequestID               middleware.RequestIDConfig
+	SecurityHeaders         middleware.SecurityHeadersConfig
+	PresignedURLs           middleware.PresignedURLsConfig
 }
 
 type certMagic struct {
 	Enabled                 bool   `user:"true" help:"use CertMagic to handle TLS certificates" default:"false"`
 	KeyFile                 string `user:"true" help:"path to service account key file (permissions to use Google's Cloud Storage, Certificate Manager Public CA and Cloud DNS)"`
+	DNSProvider             string `user:"true" help:"DNS provider to perform the DNS challenge with (gcloud, cloudflare); cloudflare reads the API token from CLOUDFLARE_API_TOKEN" default:"gcloud"`
 	Project                 string `user:"true" help:"a project where the Google Cloud DNS zone exists"`
 	ChallengeOverrideDomain string `user:"true" help:"domain to set the TXT record on, to delegate the challenge to a different domain"`
 	Email                   string `user:"true" help:"email address to use while creating an ACME account"`
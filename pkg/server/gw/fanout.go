@@ -0,0 +1,168 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package gw
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/zeebo/errs"
+	"golang.org/x/sync/errgroup"
+
+	"storj.io/common/memory"
+	minio "storj.io/minio/cmd"
+	"storj.io/minio/pkg/hash"
+)
+
+// FanOutConfig configures splitting large PutObject uploads into multipart
+// parts that are uploaded to the backend in parallel.
+type FanOutConfig struct {
+	Enabled     bool        `help:"split large PutObject uploads into multipart parts uploaded in parallel" default:"false"`
+	PartSize    memory.Size `help:"size of each part of a split PutObject upload; uploads not larger than this, or that would need more than 10000 parts, are not split. At least 5MiB" default:"64MiB"`
+	Parallelism int         `help:"maximum number of parts of a split PutObject upload buffered in memory and uploaded in parallel" default:"4"`
+}
+
+// validate returns an error if the configuration is invalid.
+func (config FanOutConfig) validate() error {
+	if !config.Enabled {
+		return nil
+	}
+	if config.PartSize < minPartSize {
+		return errs.New("upload fan-out part size must be at least %s, got %s", minPartSize, config.PartSize)
+	}
+	if config.Parallelism < 1 {
+		return errs.New("upload fan-out parallelism must be at least 1, got %d", config.Parallelism)
+	}
+	return nil
+}
+
+// shouldFanOut returns whether an upload of the given size should be split
+// into parallel multipart parts. Uploads that would need more parts than a
+// multipart upload may have are uploaded as they are.
+func (config FanOutConfig) shouldFanOut(size int64) bool {
+	if !config.Enabled || config.PartSize <= 0 {
+		return false
+	}
+	return size > config.PartSize.Int64() && size <= maxPartID*config.PartSize.Int64()
+}
+
+// putObjectFanOut uploads data as a multipart upload, uploading up to
+// Parallelism parts at a time, and completes the upload on the client's
+// behalf. The returned object has a multipart ETag. The upload is aborted if
+// any of the parts fail.
+//
+// ctx must already carry the credentials for the underlying layer.
+func (l *MultiTenancyLayer) putObjectFanOut(ctx context.Context, bucket, object string, data *minio.PutObjReader, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	uploadID, err := l.layer.NewMultipartUpload(ctx, bucket, object, opts)
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	defer func() {
+		if err != nil {
+			abortCtx := context.WithoutCancel(ctx)
+			if abortErr := l.layer.AbortMultipartUpload(abortCtx, bucket, object, uploadID, minio.ObjectOptions{}); abortErr != nil {
+				mon.Event("put_object_fan_out_abort_failed")
+			}
+		}
+	}()
+
+	parts, err := l.putObjectFanOutParts(ctx, bucket, object, uploadID, data, opts)
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	mon.IntVal("put_object_fan_out_parts").Observe(int64(len(parts)))
+
+	return l.layer.CompleteMultipartUpload(ctx, bucket, object, uploadID, parts, opts)
+}
+
+// putObjectFanOutParts reads data sequentially in PartSize chunks and uploads
+// each chunk as a separate part. At most Parallelism parts are buffered and
+// uploaded at the same time.
+func (l *MultiTenancyLayer) putObjectFanOutParts(ctx context.Context, bucket, object, uploadID string, data *minio.PutObjReader, opts minio.ObjectOptions) (_ []minio.CompletePart, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	partSize := l.fanOut.PartSize.Int64()
+
+	// buffers holds the buffers of the parts that aren't being uploaded, so
+	// that reading the next part waits for one of the Parallelism buffers to
+	// be free and no more than Parallelism parts are ever held in memory.
+	buffers := make(chan []byte, l.fanOut.Parallelism)
+	for i := 0; i < l.fanOut.Parallelism; i++ {
+		buffers <- nil
+	}
+
+	var (
+		mu    sync.Mutex
+		parts []minio.CompletePart
+	)
+
+	group, groupCtx := errgroup.WithContext(ctx)
+
+	for partID := 1; ; partID++ {
+		var buf []byte
+		select {
+		case buf = <-buffers:
+		case <-groupCtx.Done():
+			// the parts uploaded so far must not be completed if only ctx
+			// was canceled and none of the parts failed.
+			return nil, errs.Combine(ctx.Err(), group.Wait())
+		}
+		if buf == nil {
+			buf = make([]byte, partSize)
+		}
+
+		n, readErr := io.ReadFull(data, buf)
+		if readErr != nil && !errors.Is(readErr, io.EOF) && !errors.Is(readErr, io.ErrUnexpectedEOF) {
+			// the client's checksum errors must reach minio unwrapped so that
+			// they are reported with the right S3 error code.
+			_ = group.Wait()
+			return nil, readErr
+		}
+		if n == 0 {
+			break
+		}
+
+		group.Go(func() error {
+			defer func() { buffers <- buf }()
+
+			hashReader, err := hash.NewReader(bytes.NewReader(buf[:n]), int64(n), "", "", int64(n))
+			if err != nil {
+				return err
+			}
+
+			info, err := l.layer.PutObjectPart(groupCtx, bucket, object, uploadID, partID, minio.NewPutObjReader(hashReader), opts)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			parts = append(parts, minio.CompletePart{PartNumber: info.PartNumber, ETag: info.ETag})
+			mu.Unlock()
+
+			return nil
+		})
+
+		if readErr != nil || groupCtx.Err() != nil {
+			break
+		}
+	}
+
+	if err := errs.Combine(ctx.Err(), group.Wait()); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].PartNumber < parts[j].PartNumber
+	})
+
+	return parts, nil
+}
@@ -0,0 +1,257 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package gw
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/memory"
+	"storj.io/common/testcontext"
+	minio "storj.io/minio/cmd"
+	"storj.io/minio/pkg/hash"
+)
+
+// fakeMultipartLayer is a multipart upload backend that records the parts it
+// receives.
+type fakeMultipartLayer struct {
+	minio.ObjectLayer
+
+	// putPart, if set, is called before a part is stored.
+	putPart func(partID int) error
+
+	mu        sync.Mutex
+	parts     map[int][]byte
+	completed []minio.CompletePart
+	aborted   bool
+}
+
+func (layer *fakeMultipartLayer) NewMultipartUpload(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (string, error) {
+	layer.parts = make(map[int][]byte)
+	return "upload-id", nil
+}
+
+func (layer *fakeMultipartLayer) PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, data *minio.PutObjReader, opts minio.ObjectOptions) (minio.PartInfo, error) {
+	if layer.putPart != nil {
+		if err := layer.putPart(partID); err != nil {
+			return minio.PartInfo{}, err
+		}
+	}
+
+	b, err := io.ReadAll(data)
+	if err != nil {
+		return minio.PartInfo{}, err
+	}
+
+	layer.mu.Lock()
+	layer.parts[partID] = b
+	layer.mu.Unlock()
+
+	sum := md5.Sum(b)
+	return minio.PartInfo{PartNumber: partID, ETag: hex.EncodeToString(sum[:])}, nil
+}
+
+func (layer *fakeMultipartLayer) CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, parts []minio.CompletePart, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	layer.completed = parts
+
+	var data []byte
+	for _, part := range parts {
+		data = append(data, layer.parts[part.PartNumber]...)
+	}
+	return minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(data)), ETag: multipartETag(parts)}, nil
+}
+
+func (layer *fakeMultipartLayer) AbortMultipartUpload(ctx context.Context, bucket, object, uploadID string, opts minio.ObjectOptions) error {
+	layer.aborted = true
+	return nil
+}
+
+// multipartETag returns the ETag S3 gives to a multipart upload of parts.
+func multipartETag(parts []minio.CompletePart) string {
+	var sums []byte
+	for _, part := range parts {
+		sum, _ := hex.DecodeString(part.ETag)
+		sums = append(sums, sum...)
+	}
+	sum := md5.Sum(sums)
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:]), len(parts))
+}
+
+func newPutObjReader(t *testing.T, r io.Reader, size int64) *minio.PutObjReader {
+	hashReader, err := hash.NewReader(r, size, "", "", size)
+	require.NoError(t, err)
+	return minio.NewPutObjReader(hashReader)
+}
+
+func TestFanOutConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		config FanOutConfig
+		valid  bool
+	}{
+		{config: FanOutConfig{}, valid: true},
+		{config: FanOutConfig{Parallelism: 0, PartSize: 0}, valid: true},
+		{config: FanOutConfig{Enabled: true, PartSize: 5 * memory.MiB, Parallelism: 1}, valid: true},
+		{config: FanOutConfig{Enabled: true, PartSize: 5 * memory.MiB, Parallelism: 0}, valid: false},
+		{config: FanOutConfig{Enabled: true, PartSize: 5 * memory.MiB, Parallelism: -1}, valid: false},
+		{config: FanOutConfig{Enabled: true, PartSize: 0, Parallelism: 4}, valid: false},
+		{config: FanOutConfig{Enabled: true, PartSize: 5*memory.MiB - 1, Parallelism: 4}, valid: false},
+	} {
+		err := tc.config.validate()
+		if tc.valid {
+			require.NoError(t, err, tc.config)
+		} else {
+			require.Error(t, err, tc.config)
+		}
+	}
+}
+
+func TestFanOutConfigShouldFanOut(t *testing.T) {
+	config := FanOutConfig{Enabled: true, PartSize: 5 * memory.MiB, Parallelism: 4}
+
+	require.False(t, config.shouldFanOut(-1))
+	require.False(t, config.shouldFanOut(config.PartSize.Int64()))
+	require.True(t, config.shouldFanOut(config.PartSize.Int64()+1))
+	require.True(t, config.shouldFanOut(maxPartID*config.PartSize.Int64()))
+	require.False(t, config.shouldFanOut(maxPartID*config.PartSize.Int64()+1))
+
+	config.Enabled = false
+	require.False(t, config.shouldFanOut(config.PartSize.Int64()+1))
+}
+
+func TestPutObjectFanOut(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	data := bytes.Repeat([]byte("0123456789"), 10)
+
+	backend := &fakeMultipartLayer{
+		// the first parts finish last, so that the parts are put out of
+		// order.
+		putPart: func(partID int) error {
+			time.Sleep(time.Duration(10-partID) * time.Millisecond)
+			return nil
+		},
+	}
+	layer := &MultiTenancyLayer{layer: backend, fanOut: FanOutConfig{Enabled: true, PartSize: 30, Parallelism: 4}}
+
+	info, err := layer.putObjectFanOut(ctx, "bucket", "object", newPutObjReader(t, bytes.NewReader(data), int64(len(data))), minio.ObjectOptions{})
+	require.NoError(t, err)
+	require.False(t, backend.aborted)
+
+	require.Len(t, backend.completed, 4)
+	var composed []byte
+	for i, part := range backend.completed {
+		require.Equal(t, i+1, part.PartNumber)
+		sum := md5.Sum(backend.parts[part.PartNumber])
+		require.Equal(t, hex.EncodeToString(sum[:]), part.ETag)
+		composed = append(composed, backend.parts[part.PartNumber]...)
+	}
+	require.Equal(t, data, composed)
+	require.Len(t, backend.parts[4], 10)
+
+	require.Equal(t, int64(len(data)), info.Size)
+	require.Equal(t, multipartETag(backend.completed), info.ETag)
+	require.Regexp(t, "^[0-9a-f]{32}-4$", info.ETag)
+}
+
+func TestPutObjectFanOutPartError(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	data := bytes.Repeat([]byte("0123456789"), 10)
+	errPart := errors.New("part failed")
+
+	backend := &fakeMultipartLayer{
+		putPart: func(partID int) error {
+			if partID == 2 {
+				return errPart
+			}
+			return nil
+		},
+	}
+	layer := &MultiTenancyLayer{layer: backend, fanOut: FanOutConfig{Enabled: true, PartSize: 10, Parallelism: 2}}
+
+	_, err := layer.putObjectFanOut(ctx, "bucket", "object", newPutObjReader(t, bytes.NewReader(data), int64(len(data))), minio.ObjectOptions{})
+	require.ErrorIs(t, err, errPart)
+	require.True(t, backend.aborted)
+	require.Nil(t, backend.completed)
+}
+
+func TestPutObjectFanOutCanceled(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	data := bytes.Repeat([]byte("0123456789"), 10)
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	backend := &fakeMultipartLayer{}
+	layer := &MultiTenancyLayer{layer: backend, fanOut: FanOutConfig{Enabled: true, PartSize: 10, Parallelism: 2}}
+
+	_, err := layer.putObjectFanOut(canceledCtx, "bucket", "object", newPutObjReader(t, bytes.NewReader(data), int64(len(data))), minio.ObjectOptions{})
+	require.ErrorIs(t, err, context.Canceled)
+	require.True(t, backend.aborted)
+	require.Nil(t, backend.completed)
+}
+
+// countingReader counts the bytes read from it.
+type countingReader struct {
+	r    io.Reader
+	read atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.read.Add(int64(n))
+	return n, err
+}
+
+func TestPutObjectFanOutBoundsBufferedParts(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	const (
+		partSize    = 10
+		parallelism = 2
+	)
+	data := bytes.Repeat([]byte("0123456789"), 10)
+	source := &countingReader{r: bytes.NewReader(data)}
+
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	backend := &fakeMultipartLayer{
+		putPart: func(partID int) error {
+			started <- struct{}{}
+			<-release
+			return nil
+		},
+	}
+	layer := &MultiTenancyLayer{layer: backend, fanOut: FanOutConfig{Enabled: true, PartSize: partSize, Parallelism: parallelism}}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := layer.putObjectFanOut(ctx, "bucket", "object", newPutObjReader(t, source, int64(len(data))), minio.ObjectOptions{})
+		done <- err
+	}()
+
+	for i := 0; i < parallelism; i++ {
+		<-started
+	}
+	// give the upload the chance to read further ahead than it should.
+	time.Sleep(50 * time.Millisecond)
+	require.LessOrEqual(t, source.read.Load(), int64(parallelism*partSize))
+	require.Len(t, started, 0)
+
+	close(release)
+	require.NoError(t, <-done)
+	require.Len(t, backend.completed, 10)
+}
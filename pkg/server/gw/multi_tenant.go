@@ -5,6 +5,7 @@ package gw
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
@@ -52,7 +53,14 @@ var (
 
 // NewMultiTenantLayer initializes and returns new MultiTenancyLayer. A properly
 // closed object layer will also close connectionPool.
-func NewMultiTenantLayer(gateway minio.Gateway, satelliteConnectionPool *rpcpool.Pool, connectionPool *rpcpool.Pool, config uplink.Config, satelliteIdentities []*identity.FullIdentity) (*MultiTenancyLayer, error) {
+func NewMultiTenantLayer(gateway minio.Gateway, satelliteConnectionPool *rpcpool.Pool, connectionPool *rpcpool.Pool, config uplink.Config, satelliteIdentities []*identity.FullIdentity, fanOut FanOutConfig, partUploads PartUploadsConfig) (*MultiTenancyLayer, error) {
+	if err := fanOut.validate(); err != nil {
+		return nil, err
+	}
+	if err := partUploads.validate(); err != nil {
+		return nil, err
+	}
+
 	layer, err := gateway.NewGatewayLayer(auth.Credentials{})
 
 	signers := make(map[storj.NodeID]signing.Signer, len(satelliteIdentities))
@@ -66,6 +74,8 @@ func NewMultiTenantLayer(gateway minio.Gateway, satelliteConnectionPool *rpcpool
 		connectionPool:          connectionPool,
 		satelliteSigners:        signers,
 		config:                  config,
+		fanOut:                  fanOut,
+		parts:                   newPartLimiter(partUploads),
 	}, err
 }
 
@@ -80,6 +90,8 @@ type MultiTenancyLayer struct {
 	satelliteSigners        map[storj.NodeID]signing.Signer
 
 	config uplink.Config
+	fanOut FanOutConfig
+	parts  *partLimiter
 }
 
 // log all errors and relevant request information.
@@ -442,6 +454,11 @@ func (l *MultiTenancyLayer) PutObject(ctx context.Context, bucket, object string
 
 	defer func() { err = errs.Combine(err, project.Close()) }()
 
+	if l.fanOut.shouldFanOut(data.Size()) {
+		objInfo, err = l.putObjectFanOut(miniogw.WithCredentials(ctx, project, credsInfo), bucket, object, data, opts)
+		return objInfo, l.log(ctx, err)
+	}
+
 	objInfo, err = l.layer.PutObject(miniogw.WithCredentials(ctx, project, credsInfo), bucket, object, data, opts)
 
 	return objInfo, l.log(ctx, err)
@@ -527,6 +544,16 @@ func (l *MultiTenancyLayer) CopyObjectPart(ctx context.Context, srcBucket, srcOb
 
 // PutObjectPart is a multi-tenant wrapping of storj.io/gateway.(*gatewayLayer).PutObjectPart.
 func (l *MultiTenancyLayer) PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, data *minio.PutObjReader, opts minio.ObjectOptions) (info minio.PartInfo, err error) {
+	if err := validatePartID(partID); err != nil {
+		return minio.PartInfo{}, l.log(ctx, err)
+	}
+
+	release, err := l.parts.acquire(ctx, uploadID)
+	if err != nil {
+		return minio.PartInfo{}, l.log(ctx, err)
+	}
+	defer release()
+
 	project, credsInfo, err := l.parseCredentials(ctx, getCredentials(ctx))
 	if err != nil {
 		return minio.PartInfo{}, err
@@ -577,7 +604,14 @@ func (l *MultiTenancyLayer) AbortMultipartUpload(ctx context.Context, bucket, ob
 }
 
 // CompleteMultipartUpload is a multi-tenant wrapping of storj.io/gateway.(*gatewayLayer).CompleteMultipartUpload.
+//
+// Like S3, it rejects parts that are out of order, that weren't uploaded or
+// that are smaller than 5 MiB without being the last part.
 func (l *MultiTenancyLayer) CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
+	if err := validatePartOrder(uploadedParts); err != nil {
+		return minio.ObjectInfo{}, l.log(ctx, err)
+	}
+
 	project, credsInfo, err := l.parseCredentials(ctx, getCredentials(ctx))
 	if err != nil {
 		return minio.ObjectInfo{}, err
@@ -585,7 +619,17 @@ func (l *MultiTenancyLayer) CompleteMultipartUpload(ctx context.Context, bucket,
 
 	defer func() { err = errs.Combine(err, project.Close()) }()
 
-	objInfo, err = l.layer.CompleteMultipartUpload(miniogw.WithCredentials(ctx, project, credsInfo), bucket, object, uploadID, uploadedParts, opts)
+	ctx = miniogw.WithCredentials(ctx, project, credsInfo)
+
+	parts, err := l.listAllObjectParts(ctx, bucket, object, uploadID, opts)
+	if err != nil {
+		return minio.ObjectInfo{}, l.log(ctx, err)
+	}
+	if err := validateCompleteParts(uploadedParts, parts); err != nil {
+		return minio.ObjectInfo{}, l.log(ctx, err)
+	}
+
+	objInfo, err = l.layer.CompleteMultipartUpload(ctx, bucket, object, uploadID, uploadedParts, opts)
 	return objInfo, l.log(ctx, err)
 }
 
@@ -595,7 +639,14 @@ func (l *MultiTenancyLayer) IsTaggingSupported() bool {
 }
 
 // PutObjectTags is a multi-tenant wrapping of storj.io/gateway.(*gatewayLayer).PutObjectTags.
+//
+// Tags beyond the limits of S3 are rejected before they reach the object's
+// metadata.
 func (l *MultiTenancyLayer) PutObjectTags(ctx context.Context, bucketName, objectPath string, tags string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	if err := validateObjectTags(tags); err != nil {
+		return minio.ObjectInfo{}, l.log(ctx, err)
+	}
+
 	project, credsInfo, err := l.parseCredentials(ctx, getCredentials(ctx))
 	if err != nil {
 		return minio.ObjectInfo{}, err
@@ -635,6 +686,25 @@ func (l *MultiTenancyLayer) DeleteObjectTags(ctx context.Context, bucketName, ob
 	return objInfo, l.log(ctx, err)
 }
 
+// validateObjectTags checks that the URL-encoded object tags s stay within
+// the limits of S3: at most 10 tags with unique keys of up to 128 and values
+// of up to 256 characters.
+func validateObjectTags(s string) error {
+	if _, err := tags.ParseObjectTags(s); err != nil {
+		code := "InvalidTag"
+		var coder interface{ Code() string }
+		if errors.As(err, &coder) {
+			code = coder.Code()
+		}
+		return miniogo.ErrorResponse{
+			Code:       code,
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+		}
+	}
+	return nil
+}
+
 func getCredentials(ctx context.Context) *middleware.Credentials {
 	credentials := middleware.GetAccess(ctx)
 	if credentials == nil {
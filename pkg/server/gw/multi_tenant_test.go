@@ -6,7 +6,10 @@ package gw
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 
 	miniogo "github.com/minio/minio-go/v7"
@@ -44,15 +47,53 @@ func TestLogErrors(t *testing.T) {
 	for i, tc := range tests {
 		log := gwlog.New()
 		ctx := log.WithContext(context.Background())
-		require.Error(t, (&MultiTenancyLayer{minio.GatewayUnsupported{}, nil, nil, nil, nil, uplink.Config{}}).log(ctx, tc.input))
+		require.Error(t, (&MultiTenancyLayer{minio.GatewayUnsupported{}, nil, nil, nil, nil, uplink.Config{}, FanOutConfig{}, nil}).log(ctx, tc.input))
 		require.Equal(t, tc.expected, log.TagValue("error"), i)
 	}
 }
 
 func TestInvalidAccessGrant(t *testing.T) {
-	layer := &MultiTenancyLayer{minio.GatewayUnsupported{}, nil, nil, nil, nil, uplink.Config{}}
+	layer := &MultiTenancyLayer{minio.GatewayUnsupported{}, nil, nil, nil, nil, uplink.Config{}, FanOutConfig{}, nil}
 	_, err := layer.ListBuckets(context.Background())
 	require.Error(t, err)
 	require.IsType(t, miniogo.ErrorResponse{}, err)
 	require.Equal(t, http.StatusUnauthorized, miniogo.ToErrorResponse(err).StatusCode)
 }
+
+func TestPutObjectTagsLimits(t *testing.T) {
+	layer := &MultiTenancyLayer{minio.GatewayUnsupported{}, nil, nil, nil, nil, uplink.Config{}, FanOutConfig{}, nil}
+
+	encode := func(n, keyLength, valueLength int) string {
+		values := url.Values{}
+		for i := 0; i < n; i++ {
+			key := fmt.Sprintf("%0*d", keyLength, i)
+			values.Set(key, strings.Repeat("v", valueLength))
+		}
+		return values.Encode()
+	}
+
+	for _, tc := range []struct {
+		name  string
+		tags  string
+		valid bool
+	}{
+		{name: "none", tags: "", valid: true},
+		{name: "maximum", tags: encode(10, 128, 256), valid: true},
+		{name: "too many", tags: encode(11, 8, 8), valid: false},
+		{name: "key too long", tags: encode(1, 129, 8), valid: false},
+		{name: "value too long", tags: encode(1, 8, 257), valid: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// there are no credentials, so tags that pass validation fail
+			// with AccessKeyEmpty instead.
+			_, err := layer.PutObjectTags(context.Background(), "bucket", "object", tc.tags, minio.ObjectOptions{})
+			require.Error(t, err)
+			require.IsType(t, miniogo.ErrorResponse{}, err)
+			if tc.valid {
+				require.Equal(t, ErrAccessKeyEmpty, err)
+			} else {
+				require.Equal(t, http.StatusBadRequest, miniogo.ToErrorResponse(err).StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package gw
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	miniogo "github.com/minio/minio-go/v7"
+
+	"storj.io/common/memory"
+	minio "storj.io/minio/cmd"
+)
+
+const (
+	// minPartSize is the minimum size of every part of a multipart upload
+	// but the last one.
+	minPartSize = 5 * memory.MiB
+	// maxPartID is the highest part number of a multipart upload.
+	maxPartID = 10000
+	// listPartsPageSize is the number of parts listed at a time to validate
+	// the completion of a multipart upload.
+	listPartsPageSize = 1000
+)
+
+// validatePartID returns an error if partID isn't a valid part number.
+func validatePartID(partID int) error {
+	if partID < 1 || partID > maxPartID {
+		return miniogo.ErrorResponse{
+			Code:       "InvalidArgument",
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("Part number must be an integer between 1 and %d, inclusive.", maxPartID),
+		}
+	}
+	return nil
+}
+
+// validatePartOrder returns an error if the parts of a completion request
+// aren't in ascending order.
+func validatePartOrder(uploaded []minio.CompletePart) error {
+	for i := 1; i < len(uploaded); i++ {
+		if uploaded[i].PartNumber <= uploaded[i-1].PartNumber {
+			return miniogo.ErrorResponse{
+				Code:       "InvalidPartOrder",
+				StatusCode: http.StatusBadRequest,
+				Message:    "The list of parts was not in ascending order. The parts list must be specified in order by part number.",
+			}
+		}
+	}
+	return nil
+}
+
+// validateCompleteParts returns an error if the parts of a completion request
+// refer to parts that weren't uploaded or if any of them but the last one is
+// smaller than minPartSize.
+func validateCompleteParts(uploaded []minio.CompletePart, parts []minio.PartInfo) error {
+	byNumber := make(map[int]minio.PartInfo, len(parts))
+	for _, part := range parts {
+		byNumber[part.PartNumber] = part
+	}
+
+	for i, completePart := range uploaded {
+		part, ok := byNumber[completePart.PartNumber]
+		// the backend may not list ETags, in which case only the part numbers
+		// can be checked.
+		if !ok || (part.ETag != "" && canonicalETag(part.ETag) != canonicalETag(completePart.ETag)) {
+			return miniogo.ErrorResponse{
+				Code:       "InvalidPart",
+				StatusCode: http.StatusBadRequest,
+				Message:    fmt.Sprintf("Part %d could not be found or its entity tag does not match.", completePart.PartNumber),
+			}
+		}
+		if i < len(uploaded)-1 && part.Size < minPartSize.Int64() {
+			return miniogo.ErrorResponse{
+				Code:       "EntityTooSmall",
+				StatusCode: http.StatusBadRequest,
+				Message:    fmt.Sprintf("Part %d is smaller than the minimum allowed size of %s.", completePart.PartNumber, minPartSize),
+			}
+		}
+	}
+	return nil
+}
+
+// canonicalETag returns etag without surrounding quotes.
+func canonicalETag(etag string) string {
+	return strings.Trim(etag, `"`)
+}
+
+// listAllObjectParts returns all parts uploaded so far to the multipart upload
+// with uploadID.
+//
+// ctx must already carry the credentials for the underlying layer.
+func (l *MultiTenancyLayer) listAllObjectParts(ctx context.Context, bucket, object, uploadID string, opts minio.ObjectOptions) (parts []minio.PartInfo, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	var marker int
+	for {
+		result, err := l.layer.ListObjectParts(ctx, bucket, object, uploadID, marker, listPartsPageSize, opts)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, result.Parts...)
+		if !result.IsTruncated || result.NextPartNumberMarker <= marker {
+			return parts, nil
+		}
+		marker = result.NextPartNumberMarker
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package gw
+
+import (
+	"context"
+	"testing"
+
+	miniogo "github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+	minio "storj.io/minio/cmd"
+)
+
+func requireErrorCode(t *testing.T, code string, err error) {
+	t.Helper()
+
+	if code == "" {
+		require.NoError(t, err)
+		return
+	}
+	require.Error(t, err)
+	require.Equal(t, code, miniogo.ToErrorResponse(err).Code)
+}
+
+func TestValidatePartID(t *testing.T) {
+	for partID, code := range map[int]string{
+		-1:    "InvalidArgument",
+		0:     "InvalidArgument",
+		1:     "",
+		10000: "",
+		10001: "InvalidArgument",
+	} {
+		requireErrorCode(t, code, validatePartID(partID))
+	}
+}
+
+func TestValidateCompleteParts(t *testing.T) {
+	parts := []minio.PartInfo{
+		{PartNumber: 1, ETag: "etag1", Size: minPartSize.Int64()},
+		{PartNumber: 2, ETag: "etag2", Size: minPartSize.Int64() - 1},
+		{PartNumber: 3, ETag: "etag3", Size: 1},
+		{PartNumber: 5, Size: 1},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		uploaded []minio.CompletePart
+		code     string
+	}{
+		{
+			name:     "single small part",
+			uploaded: []minio.CompletePart{{PartNumber: 3, ETag: "etag3"}},
+		},
+		{
+			name:     "small last part",
+			uploaded: []minio.CompletePart{{PartNumber: 1, ETag: "etag1"}, {PartNumber: 3, ETag: `"etag3"`}},
+		},
+		{
+			name:     "small part before last",
+			uploaded: []minio.CompletePart{{PartNumber: 2, ETag: "etag2"}, {PartNumber: 3, ETag: "etag3"}},
+			code:     "EntityTooSmall",
+		},
+		{
+			name:     "unknown part",
+			uploaded: []minio.CompletePart{{PartNumber: 1, ETag: "etag1"}, {PartNumber: 4, ETag: "etag4"}},
+			code:     "InvalidPart",
+		},
+		{
+			name:     "mismatched etag",
+			uploaded: []minio.CompletePart{{PartNumber: 1, ETag: "etag2"}},
+			code:     "InvalidPart",
+		},
+		{
+			name:     "part without listed etag",
+			uploaded: []minio.CompletePart{{PartNumber: 5, ETag: "anything"}},
+		},
+		{
+			name:     "out of order",
+			uploaded: []minio.CompletePart{{PartNumber: 3, ETag: "etag3"}, {PartNumber: 1, ETag: "etag1"}},
+			code:     "InvalidPartOrder",
+		},
+		{
+			name:     "duplicate part",
+			uploaded: []minio.CompletePart{{PartNumber: 1, ETag: "etag1"}, {PartNumber: 1, ETag: "etag1"}},
+			code:     "InvalidPartOrder",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validatePartOrder(tc.uploaded)
+			if err == nil {
+				err = validateCompleteParts(tc.uploaded, parts)
+			}
+			requireErrorCode(t, tc.code, err)
+		})
+	}
+}
+
+// fakePartsLayer lists parts in pages of at most two parts.
+type fakePartsLayer struct {
+	minio.ObjectLayer
+
+	parts []minio.PartInfo
+}
+
+func (layer *fakePartsLayer) ListObjectParts(ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int, opts minio.ObjectOptions) (result minio.ListPartsInfo, err error) {
+	for _, part := range layer.parts {
+		if part.PartNumber <= partNumberMarker {
+			continue
+		}
+		if len(result.Parts) == 2 {
+			result.IsTruncated = true
+			break
+		}
+		result.Parts = append(result.Parts, part)
+		result.NextPartNumberMarker = part.PartNumber
+	}
+	return result, nil
+}
+
+func TestListAllObjectParts(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	backend := &fakePartsLayer{}
+	for i := 1; i <= 5; i++ {
+		backend.parts = append(backend.parts, minio.PartInfo{PartNumber: i * 2})
+	}
+	layer := &MultiTenancyLayer{layer: backend}
+
+	parts, err := layer.listAllObjectParts(ctx, "bucket", "object", "upload-id", minio.ObjectOptions{})
+	require.NoError(t, err)
+	require.Equal(t, backend.parts, parts)
+}
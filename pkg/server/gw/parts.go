@@ -0,0 +1,132 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package gw
+
+import (
+	"context"
+	"sync"
+
+	"github.com/zeebo/errs"
+)
+
+// PartUploadsConfig configures how many parts of multipart uploads are
+// uploaded to the network at a time. With in-memory encoding, every part
+// being uploaded buffers up to a segment, so the limits bound the memory used
+// by parts at the cost of throughput.
+type PartUploadsConfig struct {
+	MaxInFlight          int `help:"maximum number of parts of all multipart uploads uploaded at a time; further UploadPart requests wait. 0 means no limit" default:"0"`
+	MaxInFlightPerUpload int `help:"maximum number of parts of a single multipart upload uploaded at a time; further UploadPart requests of the upload wait. 0 means no limit" default:"0"`
+}
+
+// validate returns an error if the configuration is invalid.
+func (config PartUploadsConfig) validate() error {
+	if config.MaxInFlight < 0 {
+		return errs.New("maximum number of parts in flight must not be negative, got %d", config.MaxInFlight)
+	}
+	if config.MaxInFlightPerUpload < 0 {
+		return errs.New("maximum number of parts in flight per upload must not be negative, got %d", config.MaxInFlightPerUpload)
+	}
+	return nil
+}
+
+// partLimiter limits the number of parts uploaded at a time, in total and per
+// multipart upload. A nil partLimiter doesn't limit anything.
+type partLimiter struct {
+	total     chan struct{}
+	perUpload int
+
+	mu      sync.Mutex
+	uploads map[string]*uploadSlots
+}
+
+// uploadSlots are the slots of a single multipart upload, shared by the parts
+// currently waiting for or holding one.
+type uploadSlots struct {
+	slots chan struct{}
+	refs  int
+}
+
+// newPartLimiter returns a partLimiter for config or nil if config doesn't
+// limit anything.
+func newPartLimiter(config PartUploadsConfig) *partLimiter {
+	if config.MaxInFlight <= 0 && config.MaxInFlightPerUpload <= 0 {
+		return nil
+	}
+
+	limiter := &partLimiter{
+		perUpload: config.MaxInFlightPerUpload,
+		uploads:   make(map[string]*uploadSlots),
+	}
+	if config.MaxInFlight > 0 {
+		limiter.total = make(chan struct{}, config.MaxInFlight)
+	}
+	return limiter
+}
+
+// acquire waits until a part of the multipart upload with uploadID may be
+// uploaded. The returned function must be called once the part is uploaded.
+func (limiter *partLimiter) acquire(ctx context.Context, uploadID string) (release func(), err error) {
+	if limiter == nil {
+		return func() {}, nil
+	}
+
+	// the slot of the upload is acquired first, so that parts waiting on
+	// their upload don't hold slots other uploads could use.
+	var upload *uploadSlots
+	if limiter.perUpload > 0 {
+		upload = limiter.join(uploadID)
+		select {
+		case upload.slots <- struct{}{}:
+		case <-ctx.Done():
+			limiter.leave(uploadID)
+			return nil, ctx.Err()
+		}
+	}
+
+	if limiter.total != nil {
+		select {
+		case limiter.total <- struct{}{}:
+		case <-ctx.Done():
+			if upload != nil {
+				<-upload.slots
+				limiter.leave(uploadID)
+			}
+			return nil, ctx.Err()
+		}
+	}
+
+	return func() {
+		if limiter.total != nil {
+			<-limiter.total
+		}
+		if upload != nil {
+			<-upload.slots
+			limiter.leave(uploadID)
+		}
+	}, nil
+}
+
+func (limiter *partLimiter) join(uploadID string) *uploadSlots {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	upload, ok := limiter.uploads[uploadID]
+	if !ok {
+		upload = &uploadSlots{slots: make(chan struct{}, limiter.perUpload)}
+		limiter.uploads[uploadID] = upload
+	}
+	upload.refs++
+	return upload
+}
+
+func (limiter *partLimiter) leave(uploadID string) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	upload := limiter.uploads[uploadID]
+	upload.refs--
+	if upload.refs == 0 {
+		delete(limiter.uploads, uploadID)
+	}
+}
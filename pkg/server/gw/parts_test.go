@@ -0,0 +1,91 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package gw
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+)
+
+func TestPartUploadsConfigValidate(t *testing.T) {
+	require.NoError(t, PartUploadsConfig{}.validate())
+	require.NoError(t, PartUploadsConfig{MaxInFlight: 8, MaxInFlightPerUpload: 2}.validate())
+	require.Error(t, PartUploadsConfig{MaxInFlight: -1}.validate())
+	require.Error(t, PartUploadsConfig{MaxInFlightPerUpload: -1}.validate())
+}
+
+func TestPartLimiterUnlimited(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	limiter := newPartLimiter(PartUploadsConfig{})
+	require.Nil(t, limiter)
+
+	for i := 0; i < 10; i++ {
+		_, err := limiter.acquire(ctx, "upload")
+		require.NoError(t, err)
+	}
+}
+
+func TestPartLimiterPerUpload(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	limiter := newPartLimiter(PartUploadsConfig{MaxInFlightPerUpload: 1})
+
+	release, err := limiter.acquire(ctx, "upload1")
+	require.NoError(t, err)
+
+	// other uploads aren't limited by upload1.
+	releaseOther, err := limiter.acquire(ctx, "upload2")
+	require.NoError(t, err)
+	releaseOther()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = limiter.acquire(timeoutCtx, "upload1")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release()
+
+	release, err = limiter.acquire(ctx, "upload1")
+	require.NoError(t, err)
+	release()
+
+	require.Empty(t, limiter.uploads)
+}
+
+func TestPartLimiterTotal(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	limiter := newPartLimiter(PartUploadsConfig{MaxInFlight: 2, MaxInFlightPerUpload: 2})
+
+	release1, err := limiter.acquire(ctx, "upload1")
+	require.NoError(t, err)
+	release2, err := limiter.acquire(ctx, "upload2")
+	require.NoError(t, err)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = limiter.acquire(timeoutCtx, "upload3")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	acquired := make(chan func())
+	go func() {
+		release, err := limiter.acquire(ctx, "upload1")
+		if err == nil {
+			acquired <- release
+		}
+	}()
+
+	release2()
+	(<-acquired)()
+	release1()
+
+	require.Empty(t, limiter.uploads)
+	require.Empty(t, limiter.total)
+}
@@ -52,8 +52,9 @@ type DestinationLogBucket struct {
 // AccessLogConfig is a map of WatchedBucket to DestinationLogBucket configuration.
 type AccessLogConfig map[WatchedBucket]DestinationLogBucket
 
-// AccessLog is a middleware function that logs access information for incoming HTTP requests.
-func AccessLog(log *zap.Logger, p *accesslogs.Processor, config AccessLogConfig) mux.MiddlewareFunc {
+// AccessLog is a middleware function that logs access information for incoming HTTP requests
+// selected by filter.
+func AccessLog(log *zap.Logger, p *accesslogs.Processor, config AccessLogConfig, filter OperationFilter) mux.MiddlewareFunc {
 	return func(h http.Handler) http.Handler {
 		return whmon.MonitorResponse(whroute.HandlerFunc(h, func(w http.ResponseWriter, r *http.Request) {
 			rw := w.(whmon.ResponseWriter)
@@ -77,6 +78,10 @@ func AccessLog(log *zap.Logger, p *accesslogs.Processor, config AccessLogConfig)
 				return
 			}
 
+			if !filter.includes(r, gl) {
+				return
+			}
+
 			parsedPublicProjectID, err := uuid.FromString(publicProjectID)
 			if err != nil {
 				log.Error("Error parsing public project ID from authservice",
@@ -292,7 +292,7 @@ func TestProcessLogEntry(t *testing.T) {
 		BaseURL: authServer.URL,
 	}), trustedip.NewListTrustAll(), log)
 
-	accessLogHandler := AccessLog(log, p, config)
+	accessLogHandler := AccessLog(log, p, config, AllOperations)
 
 	testHandler := accessKeyHandler(accessLogHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		gl, ok := gwlog.FromContext(r.Context())
@@ -0,0 +1,120 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"storj.io/common/memory"
+	"storj.io/minio/cmd"
+	xhttp "storj.io/minio/cmd/http"
+)
+
+// NewPutObjectSizeLimit returns a middleware that responds to single PutObject
+// requests with a body larger than limit with EntityTooLarge errors before
+// any of it is read. A limit of zero disables it.
+//
+// Parts of multipart uploads (PUT requests with an uploadId) aren't limited,
+// so that large objects can still be uploaded in parts. Bodies of unknown
+// length are cut off after limit bytes, which fails the request with
+// EntityTooLarge too.
+//
+// POST policy uploads are limited the same way, except that their bodies may
+// additionally hold the fields of the form preceding the uploaded file.
+func NewPutObjectSizeLimit(limit memory.Size) mux.MiddlewareFunc {
+	if limit <= 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var size, maxSize int64
+			switch {
+			case r.Method == http.MethodPut && !r.URL.Query().Has("uploadId"):
+				size, maxSize = r.ContentLength, limit.Int64()
+				// the content length of streaming signed (aws-chunked) uploads
+				// includes the chunk signatures.
+				if decoded := r.Header.Get(xhttp.AmzDecodedContentLength); decoded != "" {
+					if n, err := strconv.ParseInt(decoded, 10, 64); err == nil {
+						size = n
+					}
+				}
+			case isRequestPostPolicySignature(r):
+				size, maxSize = r.ContentLength, limit.Int64()+postFormMaxSize.Int64()
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if size > maxSize {
+				writeEntityTooLarge(w, r)
+				return
+			}
+			if size < 0 {
+				body := &limitedBody{ReadCloser: http.MaxBytesReader(w, r.Body, maxSize)}
+				r.Body = body
+				w = &entityTooLargeWriter{ResponseWriter: w, r: r, body: body}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeEntityTooLarge(w http.ResponseWriter, r *http.Request) {
+	mon.Event("put_object_too_large")
+	cmd.WriteErrorResponse(r.Context(), w, cmd.GetAPIError(cmd.ErrEntityTooLarge), r.URL, false)
+}
+
+// limitedBody records whether reading a body cut off by http.MaxBytesReader
+// failed because it was too large.
+type limitedBody struct {
+	io.ReadCloser
+
+	tooLarge bool
+}
+
+func (b *limitedBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		b.tooLarge = true
+	}
+	return n, err
+}
+
+// entityTooLargeWriter replaces the error response to a request whose body was
+// too large, which the handler reports as whatever error reading the body
+// maps to, with EntityTooLarge.
+type entityTooLargeWriter struct {
+	http.ResponseWriter
+
+	r        *http.Request
+	body     *limitedBody
+	replaced bool
+}
+
+func (w *entityTooLargeWriter) WriteHeader(status int) {
+	if w.replaced {
+		return
+	}
+	if w.body.tooLarge && status >= http.StatusBadRequest {
+		w.replaced = true
+		writeEntityTooLarge(w.ResponseWriter, w.r)
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *entityTooLargeWriter) Write(p []byte) (int, error) {
+	if w.replaced {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
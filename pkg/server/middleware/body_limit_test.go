@@ -0,0 +1,84 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/memory"
+)
+
+func TestPutObjectSizeLimit(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.Copy(io.Discard, r.Body); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	limited := NewPutObjectSizeLimit(10 * memory.B)(handler)
+
+	const formContentType = "multipart/form-data; boundary=boundary"
+	formLimit := 10 + postFormMaxSize.Int64()
+
+	testCases := []struct {
+		desc        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		length      int64
+		decoded     string
+		status      int
+	}{
+		{desc: "small put", method: http.MethodPut, target: "/bucket/key", body: "0123456789", length: 10, status: http.StatusOK},
+		{desc: "large put", method: http.MethodPut, target: "/bucket/key", body: "0123456789a", length: 11, status: http.StatusBadRequest},
+		{desc: "large part", method: http.MethodPut, target: "/bucket/key?partNumber=1&uploadId=abc", body: "0123456789a", length: 11, status: http.StatusOK},
+		{desc: "large post", method: http.MethodPost, target: "/bucket/key?uploads", body: "0123456789a", length: 11, status: http.StatusOK},
+		{desc: "small streaming put", method: http.MethodPut, target: "/bucket/key", body: "0123456789abcdef", length: 16, decoded: "5", status: http.StatusOK},
+		{desc: "large streaming put", method: http.MethodPut, target: "/bucket/key", body: "0123456789a", length: 11, decoded: "11", status: http.StatusBadRequest},
+		{desc: "small put of unknown length", method: http.MethodPut, target: "/bucket/key", body: "0123456789", length: -1, status: http.StatusOK},
+		{desc: "large put of unknown length", method: http.MethodPut, target: "/bucket/key", body: "0123456789a", length: -1, status: http.StatusBadRequest},
+		{desc: "small post policy upload", method: http.MethodPost, target: "/bucket", contentType: formContentType, body: "0123456789a", length: formLimit, status: http.StatusOK},
+		{desc: "large post policy upload", method: http.MethodPost, target: "/bucket", contentType: formContentType, body: "0123456789a", length: formLimit + 1, status: http.StatusBadRequest},
+		{desc: "large post policy upload of unknown length", method: http.MethodPost, target: "/bucket", contentType: formContentType, body: strings.Repeat("a", int(formLimit)+1), length: -1, status: http.StatusBadRequest},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			req.ContentLength = tc.length
+			if tc.decoded != "" {
+				req.Header.Set("X-Amz-Decoded-Content-Length", tc.decoded)
+			}
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+
+			rr := httptest.NewRecorder()
+			limited.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+			if tc.status == http.StatusBadRequest {
+				require.Contains(t, rr.Body.String(), "EntityTooLarge")
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/bucket/key", strings.NewReader("0123456789a"))
+
+		rr := httptest.NewRecorder()
+		NewPutObjectSizeLimit(0)(handler).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+	})
+}
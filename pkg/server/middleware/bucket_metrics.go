@@ -0,0 +1,113 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/spacemonkeygo/monkit/v3"
+
+	"storj.io/edge/pkg/server/gwlog"
+)
+
+// otherBuckets is the bucket tag of requests of buckets that aren't measured
+// separately.
+const otherBuckets = "other"
+
+// BucketMetricsConfig configures metrics of requests broken down by S3
+// operation and bucket.
+type BucketMetricsConfig struct {
+	Buckets []string `help:"list of bucket names (comma separated) whose requests are measured separately by S3 operation; requests of all other buckets are measured together. Empty disables the metrics"`
+}
+
+// bucketMetricsReader counts bytes read from the request body.
+type bucketMetricsReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *bucketMetricsReader) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	r.n += int64(n)
+	return n, err
+}
+
+// BucketMetrics sends the count, response time, and bytes read and written
+// of requests using monkit, partitioned by the S3 operation (API) and the
+// bucket.
+//
+// Bucket names are chosen by the clients, so only the buckets in
+// config.Buckets are tagged with their name to keep the number of series
+// bounded. Requests of all other buckets, and those without a bucket, share
+// a single series per operation.
+func BucketMetrics(prefix string, config BucketMetricsConfig, next http.Handler) http.Handler {
+	buckets := make(map[string]struct{}, len(config.Buckets))
+	for _, bucket := range config.Buckets {
+		if bucket != "" {
+			buckets[bucket] = struct{}{}
+		}
+	}
+	if len(buckets) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		ctx := r.Context()
+		log, ok := gwlog.FromContext(ctx)
+		if !ok {
+			log = gwlog.New()
+			r = r.WithContext(log.WithContext(ctx))
+		}
+
+		var body *bucketMetricsReader
+		if r.Body != nil && r.Body != http.NoBody {
+			body = &bucketMetricsReader{ReadCloser: r.Body}
+			r.Body = body
+		}
+
+		var written int64
+		d := &flusherDelegator{
+			ResponseWriter: w,
+			afterWrite: func(_ int, n int64) {
+				written += n
+			},
+		}
+
+		next.ServeHTTP(d, r)
+		took := time.Since(start)
+
+		// the bucket is only known once minio has handled the request.
+		bucket := log.BucketName
+		if _, ok := buckets[bucket]; !ok {
+			bucket = otherBuckets
+		}
+
+		tags := []monkit.SeriesTag{
+			monkit.NewSeriesTag("api", log.API),
+			monkit.NewSeriesTag("bucket", bucket),
+			monkit.NewSeriesTag("status_code", strconv.Itoa(d.status)),
+		}
+
+		mon.Counter(makeMetricName(prefix, "bucket_requests"), tags...).Inc(1)
+		mon.DurationVal(makeMetricName(prefix, "bucket_response_time"), tags...).Observe(took)
+		mon.IntVal(makeMetricName(prefix, "bucket_bytes_written"), tags...).Observe(written)
+		if body != nil {
+			mon.IntVal(makeMetricName(prefix, "bucket_bytes_read"), tags...).Observe(body.n)
+		}
+	})
+}
+
+// NewBucketMetrics is a convenience wrapper around BucketMetrics that returns
+// BucketMetrics with prefix and config as mux.MiddlewareFunc.
+func NewBucketMetrics(prefix string, config BucketMetricsConfig) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return BucketMetrics(prefix, config, h)
+	}
+}
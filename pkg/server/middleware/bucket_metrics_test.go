@@ -0,0 +1,75 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"storj.io/edge/pkg/server/gwlog"
+)
+
+func TestBucketMetrics(t *testing.T) {
+	handler := func(api, bucket string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if log, ok := gwlog.FromContext(r.Context()); ok {
+				log.API = api
+				log.BucketName = bucket
+			}
+
+			_, err := io.Copy(io.Discard, r.Body)
+			require.NoError(t, err)
+
+			_, err = w.Write([]byte("written"))
+			require.NoError(t, err)
+		})
+	}
+
+	config := BucketMetricsConfig{Buckets: []string{"watched"}}
+
+	serve := func(api, bucket, body string) {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+		BucketMetrics("bm", config, handler(api, bucket)).ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve("PutObject", "watched", "0123456789")
+	serve("PutObject", "watched", "0123456789")
+	serve("PutObject", "unwatched", "01234")
+	serve("PutObject", "another", "01234")
+	serve("ListBuckets", "", "")
+
+	c := monkit.Collect(monkit.ScopeNamed("storj.io/edge/pkg/server/middleware"))
+
+	const scope = ",scope=storj.io/edge/pkg/server/middleware,status_code=200"
+
+	assert.EqualValues(t, 2, c["bm_bucket_requests,api=PutObject,bucket=watched"+scope+" value"])
+	assert.EqualValues(t, 2, c["bm_bucket_requests,api=PutObject,bucket=other"+scope+" value"])
+	assert.EqualValues(t, 1, c["bm_bucket_requests,api=ListBuckets,bucket=other"+scope+" value"])
+	assert.EqualValues(t, 2, c["bm_bucket_response_time,api=PutObject,bucket=watched"+scope+" count"])
+	assert.EqualValues(t, 20, c["bm_bucket_bytes_read,api=PutObject,bucket=watched"+scope+" sum"])
+	assert.EqualValues(t, 10, c["bm_bucket_bytes_read,api=PutObject,bucket=other"+scope+" sum"])
+	assert.EqualValues(t, 14, c["bm_bucket_bytes_written,api=PutObject,bucket=watched"+scope+" sum"])
+
+	for key := range c {
+		assert.NotContains(t, key, "unwatched")
+		assert.NotContains(t, key, "another")
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		BucketMetrics("disabled", BucketMetricsConfig{Buckets: []string{""}}, handler("GetObject", "watched")).ServeHTTP(httptest.NewRecorder(), req)
+
+		c := monkit.Collect(monkit.ScopeNamed("storj.io/edge/pkg/server/middleware"))
+		for key := range c {
+			assert.NotContains(t, key, "disabled_")
+		}
+	})
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"crypto/sha1" //nolint:gosec // SHA1 is one of the checksums S3 supports
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+	"hash/crc32"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/zeebo/errs"
+)
+
+// checksumModeHeader is the request header clients opt in to checksums with.
+const checksumModeHeader = "X-Amz-Checksum-Mode"
+
+// ChecksumTrailersConfig configures checksum trailers of object downloads.
+type ChecksumTrailersConfig struct {
+	Enabled   bool   `help:"whether to send a checksum of downloaded objects as a trailer to clients that send x-amz-checksum-mode: ENABLED" default:"false"`
+	Algorithm string `help:"checksum algorithm of the trailer: CRC32, CRC32C, SHA1 or SHA256" default:"CRC32C"`
+}
+
+// checksumAlgorithms maps the supported checksum algorithms to their hashes.
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"CRC32":  func() hash.Hash { return crc32.NewIEEE() },
+	"CRC32C": func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) },
+	"SHA1":   sha1.New,
+	"SHA256": sha256.New,
+}
+
+// NewChecksumTrailers returns a middleware that computes a checksum of object
+// downloads while they're streamed and sends it as an x-amz-checksum-*
+// trailer, so that clients can verify what they received without a prior HEAD
+// request.
+//
+// Only clients that send x-amz-checksum-mode: ENABLED get the trailer. Range
+// requests get the checksum of the returned range. HTTP/1.1 responses switch to
+// chunked transfer encoding, which is the only way to send trailers there.
+func NewChecksumTrailers(config ChecksumTrailersConfig) (mux.MiddlewareFunc, error) {
+	if !config.Enabled {
+		return func(next http.Handler) http.Handler { return next }, nil
+	}
+
+	algorithm := strings.ToUpper(config.Algorithm)
+	newHash, ok := checksumAlgorithms[algorithm]
+	if !ok {
+		return nil, errs.New("unsupported checksum algorithm %q", config.Algorithm)
+	}
+	trailer := "X-Amz-Checksum-" + algorithm
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || !strings.EqualFold(r.Header.Get(checksumModeHeader), "ENABLED") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &checksumWriter{
+				ResponseWriter: w,
+				r:              r,
+				trailer:        trailer,
+				hash:           newHash(),
+			}
+			next.ServeHTTP(cw, r)
+
+			if cw.sending {
+				w.Header().Set(trailer, base64.StdEncoding.EncodeToString(cw.hash.Sum(nil)))
+			}
+		})
+	}, nil
+}
+
+// checksumWriter hashes the body of object downloads and declares the
+// checksum trailer before the header is written.
+type checksumWriter struct {
+	http.ResponseWriter
+
+	r       *http.Request
+	trailer string
+	hash    hash.Hash
+
+	wroteHeader bool
+	sending     bool
+}
+
+func (w *checksumWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	if isObjectDownload(w.Header(), status) {
+		w.sending = true
+		w.Header().Add("Trailer", w.trailer)
+		if w.r.ProtoMajor < 2 {
+			w.Header().Del("Content-Length")
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *checksumWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(p)
+	if w.sending {
+		_, _ = w.hash.Write(p[:n])
+	}
+	return n, err
+}
+
+func (w *checksumWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *checksumWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// isObjectDownload returns whether a response to a GET request with the given
+// header and status carries object data. Other GET responses, like listings,
+// don't have an ETag. Responses that already carry a stored checksum are left
+// alone.
+func isObjectDownload(header http.Header, status int) bool {
+	if status != http.StatusOK && status != http.StatusPartialContent {
+		return false
+	}
+	if header.Get("ETag") == "" {
+		return false
+	}
+	for name := range header {
+		if strings.HasPrefix(http.CanonicalHeaderKey(name), "X-Amz-Checksum-") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"encoding/base64"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksumTrailers(t *testing.T) {
+	const object = "some object data"
+
+	mw, err := NewChecksumTrailers(ChecksumTrailersConfig{Enabled: true, Algorithm: "CRC32C"})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := object
+		status := http.StatusOK
+		if r.Header.Get("Range") != "" {
+			body = object[5:11]
+			status = http.StatusPartialContent
+		}
+		if r.URL.Path == "/bucket" {
+			body = "<ListBucketResult></ListBucketResult>"
+		} else {
+			w.Header().Set("ETag", `"etag"`)
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	})))
+	defer server.Close()
+
+	checksum := func(s string) string {
+		sum := crc32.New(crc32.MakeTable(crc32.Castagnoli))
+		_, _ = io.WriteString(sum, s)
+		return base64.StdEncoding.EncodeToString(sum.Sum(nil))
+	}
+
+	get := func(path string, header http.Header) (string, http.Header) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		req.Header = header
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, resp.Body.Close()) }()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body), resp.Trailer
+	}
+
+	body, trailer := get("/bucket/key", http.Header{"X-Amz-Checksum-Mode": {"ENABLED"}})
+	require.Equal(t, object, body)
+	require.Equal(t, checksum(object), trailer.Get("X-Amz-Checksum-Crc32c"))
+
+	body, trailer = get("/bucket/key", http.Header{"X-Amz-Checksum-Mode": {"ENABLED"}, "Range": {"bytes=5-10"}})
+	require.Equal(t, "object", body)
+	require.Equal(t, checksum("object"), trailer.Get("X-Amz-Checksum-Crc32c"))
+
+	// clients have to opt in.
+	body, trailer = get("/bucket/key", http.Header{})
+	require.Equal(t, object, body)
+	require.Empty(t, trailer)
+
+	// only object downloads get a checksum.
+	_, trailer = get("/bucket", http.Header{"X-Amz-Checksum-Mode": {"ENABLED"}})
+	require.Empty(t, trailer)
+}
+
+func TestChecksumTrailersConfig(t *testing.T) {
+	_, err := NewChecksumTrailers(ChecksumTrailersConfig{Enabled: true, Algorithm: "md5"})
+	require.Error(t, err)
+
+	_, err = NewChecksumTrailers(ChecksumTrailersConfig{Algorithm: "md5"})
+	require.NoError(t, err)
+
+	for algorithm := range checksumAlgorithms {
+		_, err = NewChecksumTrailers(ChecksumTrailersConfig{Enabled: true, Algorithm: algorithm})
+		require.NoError(t, err)
+	}
+}
@@ -482,10 +482,13 @@ func ParseV2FromQuery(r *http.Request) (_ *V2, err error) {
 	return v2, nil
 }
 
+// postFormMaxSize is the maximum size of the fields of multipart forms of POST
+// policy uploads preceding the uploaded file.
+const postFormMaxSize = 5 * memory.MiB
+
 // ParseFromForm parses V2 or V4 credentials from multipart form credentials.
 func ParseFromForm(r *http.Request) (string, error) {
 	// create a reset-able body so we don't drain the request body for later
-	const bodyBufferSize = int64(5 * memory.MiB)
 	bodyCache := newBodyCache(r.Body)
 	r.Body = bodyCache
 	var err error
@@ -494,7 +497,7 @@ func ParseFromForm(r *http.Request) (string, error) {
 	}()
 
 	// now read the body
-	reader, err := getLimitMultipartReader(r, bodyBufferSize)
+	reader, err := getLimitMultipartReader(r, postFormMaxSize.Int64())
 	if err != nil {
 		return "", errMalformedPOSTRequest.Wrap(err)
 	}
@@ -0,0 +1,36 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"net/http"
+
+	"gopkg.in/webhelp.v1/whmon"
+	"gopkg.in/webhelp.v1/whroute"
+
+	"storj.io/edge/pkg/health"
+)
+
+// TrackErrorRate records every response in tracker. Server errors, which are
+// what failing auth service or uplink calls surface as, count as failures.
+// NotImplemented is excluded as it only reflects an unsupported request, and
+// so are SlowDown errors, i.e. 503s with a Retry-After header, as they only
+// reflect throttling.
+func TrackErrorRate(tracker *health.Tracker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if tracker == nil {
+			return next
+		}
+		return whmon.MonitorResponse(whroute.HandlerFunc(next,
+			func(w http.ResponseWriter, r *http.Request) {
+				next.ServeHTTP(w, r)
+
+				code := w.(whmon.ResponseWriter).StatusCode()
+				if code == http.StatusServiceUnavailable && w.Header().Get("Retry-After") != "" {
+					return
+				}
+				tracker.Observe(code >= 500 && code != http.StatusNotImplemented)
+			}))
+	}
+}
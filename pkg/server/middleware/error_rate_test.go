@@ -0,0 +1,51 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/edge/pkg/health"
+)
+
+func TestTrackErrorRate(t *testing.T) {
+	tracker, err := health.NewTracker(health.Config{
+		Enabled:            true,
+		Window:             time.Hour,
+		MinRequests:        1,
+		DegradedThreshold:  0.5,
+		UnhealthyThreshold: 1,
+	})
+	require.NoError(t, err)
+
+	serve := func(code int, header http.Header) {
+		handler := TrackErrorRate(tracker)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(code)
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	serve(http.StatusOK, nil)
+
+	// throttling and unsupported requests aren't failures.
+	serve(http.StatusServiceUnavailable, http.Header{"Retry-After": {"120"}})
+	serve(http.StatusServiceUnavailable, http.Header{"Retry-After": {"120"}})
+	serve(http.StatusNotImplemented, nil)
+	status, _ := tracker.Status()
+	require.Equal(t, health.StatusHealthy, status)
+
+	serve(http.StatusServiceUnavailable, nil)
+	serve(http.StatusInternalServerError, nil)
+	status, reason := tracker.Status()
+	require.Equal(t, health.StatusDegraded, status)
+	require.Contains(t, reason, "(2/4)")
+}
@@ -0,0 +1,182 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"storj.io/common/http/requestid"
+	"storj.io/edge/pkg/server/gwlog"
+)
+
+// maxErrorResponseSize is the maximum size of an error response that is
+// completed. Larger responses aren't S3 error responses and are passed
+// through.
+const maxErrorResponseSize = 64 << 10
+
+// ErrorResponsesConfig configures the S3 XML error responses.
+type ErrorResponsesConfig struct {
+	HostID  string `help:"value of HostId in error responses and of the x-amz-id-2 header; empty uses a hash of the hostname"`
+	Verbose bool   `help:"include the S3 operation and the internal error in error responses, for debugging only" default:"false"`
+}
+
+// errorResponse is an S3 XML error response. It matches what minio writes,
+// plus the diagnostic fields of the verbose mode.
+type errorResponse struct {
+	XMLName    xml.Name `xml:"Error"`
+	Code       string   `xml:"Code"`
+	Message    string   `xml:"Message"`
+	Key        string   `xml:"Key,omitempty"`
+	BucketName string   `xml:"BucketName,omitempty"`
+	Resource   string   `xml:"Resource"`
+	Region     string   `xml:"Region,omitempty"`
+	RequestID  string   `xml:"RequestId"`
+	HostID     string   `xml:"HostId"`
+
+	Operation     string `xml:"Operation,omitempty"`
+	InternalError string `xml:"InternalError,omitempty"`
+}
+
+// NewErrorResponses returns a middleware that makes sure S3 XML error
+// responses carry a RequestId, HostId and Resource, like AWS S3 does.
+func NewErrorResponses(config ErrorResponsesConfig) mux.MiddlewareFunc {
+	hostID := config.HostID
+	if hostID == "" {
+		if hostname, err := os.Hostname(); err == nil {
+			sum := sha256.Sum256([]byte(hostname))
+			hostID = hex.EncodeToString(sum[:])
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gl, ok := gwlog.FromContext(r.Context())
+			if !ok {
+				gl = gwlog.New()
+				r = r.WithContext(gl.WithContext(r.Context()))
+			}
+
+			ew := &errorResponseWriter{ResponseWriter: w}
+			next.ServeHTTP(ew, r)
+
+			if ew.buffered {
+				ew.complete(r, gl, hostID, config.Verbose)
+			}
+		})
+	}
+}
+
+// errorResponseWriter buffers XML error responses so that they can be
+// completed before they're written.
+type errorResponseWriter struct {
+	http.ResponseWriter
+
+	wroteHeader bool
+	buffered    bool
+	status      int
+	body        bytes.Buffer
+}
+
+func (w *errorResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	if status >= http.StatusBadRequest && strings.Contains(w.Header().Get("Content-Type"), "xml") {
+		w.buffered = true
+		w.status = status
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *errorResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.buffered {
+		return w.ResponseWriter.Write(p)
+	}
+	if w.body.Len()+len(p) > maxErrorResponseSize {
+		// this isn't an error response we know, so give up on completing it.
+		w.buffered = false
+		w.ResponseWriter.WriteHeader(w.status)
+		if _, err := w.ResponseWriter.Write(w.body.Bytes()); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(p)
+	}
+	return w.body.Write(p)
+}
+
+func (w *errorResponseWriter) Flush() {
+	if w.buffered {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *errorResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// complete fills in the missing fields of the buffered error response and
+// writes it. Responses that don't parse as S3 error responses are written
+// unchanged.
+func (w *errorResponseWriter) complete(r *http.Request, gl *gwlog.Log, hostID string, verbose bool) {
+	body := w.body.Bytes()
+
+	requestID := w.Header().Get("X-Amz-Request-Id")
+	if requestID == "" {
+		requestID = requestid.FromContext(r.Context())
+		if requestID != "" {
+			w.Header().Set("X-Amz-Request-Id", requestID)
+		}
+	}
+	if hostID != "" && w.Header().Get("X-Amz-Id-2") == "" {
+		w.Header().Set("X-Amz-Id-2", hostID)
+	}
+
+	var resp errorResponse
+	if len(body) > 0 && xml.Unmarshal(body, &resp) == nil && resp.Code != "" {
+		if resp.Resource == "" {
+			resp.Resource = r.URL.Path
+		}
+		if resp.RequestID == "" {
+			resp.RequestID = requestID
+		}
+		if resp.HostID == "" {
+			resp.HostID = hostID
+		}
+		if verbose {
+			resp.Operation = gl.API
+			resp.InternalError = gl.TagValue("error")
+		}
+
+		if encoded, err := xml.Marshal(resp); err == nil {
+			body = append([]byte(xml.Header), encoded...)
+			if w.Header().Get("Content-Length") != "" {
+				w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			}
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if r.Method != http.MethodHead {
+		_, _ = w.ResponseWriter.Write(body)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/http/requestid"
+	"storj.io/edge/pkg/server/gwlog"
+)
+
+// minioNoSuchKey is the error response minio writes when the request ID and
+// deployment ID are unknown to it.
+const minioNoSuchKey = `<?xml version="1.0" encoding="UTF-8"?>
+<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message><Key>key</Key><BucketName>bucket</BucketName><Resource></Resource><RequestId></RequestId><HostId></HostId></Error>`
+
+func TestErrorResponses(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config ErrorResponsesConfig
+		golden string
+	}{
+		{
+			desc:   "default",
+			config: ErrorResponsesConfig{HostID: "test-host-id"},
+			golden: "error_response.golden.xml",
+		},
+		{
+			desc:   "verbose",
+			config: ErrorResponsesConfig{HostID: "test-host-id", Verbose: true},
+			golden: "error_response_verbose.golden.xml",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			handler := requestid.AddToContext(NewErrorResponses(tc.config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gl, ok := gwlog.FromContext(r.Context())
+				require.True(t, ok)
+				gl.API = "GetObject"
+				gl.SetTags("error", "object not found")
+
+				w.Header().Set("Content-Type", "application/xml")
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(minioNoSuchKey))
+			})))
+
+			req := httptest.NewRequest(http.MethodGet, "/bucket/key", nil)
+			req.Header.Set(requestid.HeaderKey, "test-request-id")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			expected, err := os.ReadFile(filepath.Join("testdata", tc.golden))
+			require.NoError(t, err)
+
+			require.Equal(t, http.StatusNotFound, rec.Code)
+			require.Equal(t, strings.TrimSuffix(string(expected), "\n"), rec.Body.String())
+			require.Equal(t, "test-request-id", rec.Header().Get("X-Amz-Request-Id"))
+			require.Equal(t, "test-host-id", rec.Header().Get("X-Amz-Id-2"))
+		})
+	}
+}
+
+func TestErrorResponsesPassThrough(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		status      int
+		contentType string
+		body        string
+	}{
+		{desc: "success", status: http.StatusOK, contentType: "application/xml", body: "<ListBucketResult></ListBucketResult>"},
+		{desc: "not xml", status: http.StatusNotFound, contentType: "text/plain", body: "not found"},
+		{desc: "unknown xml", status: http.StatusBadRequest, contentType: "application/xml", body: "<Other></Other>"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			handler := NewErrorResponses(ErrorResponsesConfig{HostID: "test-host-id"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tc.contentType)
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/bucket/key", nil))
+
+			require.Equal(t, tc.status, rec.Code)
+			require.Equal(t, tc.body, rec.Body.String())
+		})
+	}
+}
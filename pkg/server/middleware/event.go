@@ -9,12 +9,12 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/gorilla/mux"
 	"gopkg.in/webhelp.v1/whmon"
 	"gopkg.in/webhelp.v1/whroute"
 
 	"storj.io/common/grant"
 	"storj.io/common/http/requestid"
-	"storj.io/common/useragent"
 	"storj.io/edge/pkg/auth/authdb"
 	"storj.io/edge/pkg/httplog"
 	"storj.io/edge/pkg/server/gwlog"
@@ -26,19 +26,24 @@ var ek = eventkit.Package()
 
 // CollectEvent collects event data to send to eventkit.
 func CollectEvent(h http.Handler) http.Handler {
+	return collectEvent(h, AllOperations)
+}
+
+// NewCollectEvent returns a middleware that collects event data to send to
+// eventkit for the operations selected by filter.
+func NewCollectEvent(filter OperationFilter) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return collectEvent(h, filter)
+	}
+}
+
+func collectEvent(h http.Handler, filter OperationFilter) http.Handler {
 	return whmon.MonitorResponse(whroute.HandlerFunc(h,
 		func(w http.ResponseWriter, r *http.Request) {
 			rw := w.(whmon.ResponseWriter)
 			start := time.Now()
 
-			agents, err := useragent.ParseEntries([]byte(r.UserAgent()))
-			product := "unknown"
-			if err == nil && len(agents) > 0 && agents[0].Product != "" {
-				product = agents[0].Product
-				if len(product) > 32 {
-					product = product[:32]
-				}
-			}
+			product := httplog.UserAgentProduct(r.UserAgent())
 
 			var macHead, encKeyHash, satelliteAddress, publicProjectID string
 			credentials := GetAccess(r.Context())
@@ -70,6 +75,10 @@ func CollectEvent(h http.Handler) http.Handler {
 				rw.WriteHeader(http.StatusOK)
 			}
 
+			if !filter.includes(r, gl) {
+				return
+			}
+
 			var queryJSON, requestHeadersJSON, responseHeadersJSON string
 			if b, err := json.Marshal(&httplog.RequestQueryLogObject{Query: r.URL.Query()}); err == nil {
 				queryJSON = string(b)
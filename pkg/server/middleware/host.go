@@ -0,0 +1,123 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/zeebo/errs"
+)
+
+type originalHostKey struct{}
+
+// originalHost is the Host of a request, and the host of its URL if it's
+// absolute, before they were rewritten.
+type originalHost struct {
+	host    string
+	urlHost string
+}
+
+// HostRewrite maps an external host, and any of its subdomains, to a
+// canonical host, e.g. with From set to s3.example.com and To set to
+// gateway.storjshare.io, bucket.s3.example.com becomes
+// bucket.gateway.storjshare.io.
+type HostRewrite struct {
+	From string
+	To   string
+}
+
+// ParseHostRewrites parses a list of host rewrites in the
+// external_host:canonical_host form.
+func ParseHostRewrites(entries []string) ([]HostRewrite, error) {
+	var rewrites []HostRewrite
+	for _, entry := range entries {
+		from, to, ok := strings.Cut(entry, ":")
+		if !ok || from == "" || to == "" || strings.Contains(to, ":") {
+			return nil, errs.New("invalid host rewrite %q: expected external_host:canonical_host", entry)
+		}
+		rewrites = append(rewrites, HostRewrite{
+			From: strings.ToLower(from),
+			To:   strings.ToLower(to),
+		})
+	}
+	return rewrites, nil
+}
+
+// rewriteHost returns host rewritten according to the first matching rewrite.
+func rewriteHost(rewrites []HostRewrite, host string) (string, bool) {
+	name, port, err := net.SplitHostPort(host)
+	if err != nil {
+		name, port = host, ""
+	}
+	name = strings.ToLower(name)
+
+	for _, rewrite := range rewrites {
+		var rewritten string
+		switch {
+		case name == rewrite.From:
+			rewritten = rewrite.To
+		case strings.HasSuffix(name, "."+rewrite.From):
+			rewritten = strings.TrimSuffix(name, rewrite.From) + rewrite.To
+		default:
+			continue
+		}
+		if port != "" {
+			rewritten = net.JoinHostPort(rewritten, port)
+		}
+		return rewritten, true
+	}
+
+	return host, false
+}
+
+// RewriteHost rewrites the Host of requests matching rewrites so that
+// virtual-host-style requests are routed using the canonical host. It must
+// wrap the router, as routing happens before any router middleware runs.
+//
+// The client signed the request using the original Host, so RestoreHost must
+// be used as router middleware to restore it before the request is
+// authenticated.
+func RewriteHost(rewrites []HostRewrite, next http.Handler) http.Handler {
+	if len(rewrites) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rewritten, ok := rewriteHost(rewrites, r.Host)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), originalHostKey{}, originalHost{
+			host:    r.Host,
+			urlHost: r.URL.Host,
+		})
+
+		r = r.WithContext(ctx)
+		r.Host = rewritten
+		// mux matches hosts against the URL of requests with an absolute
+		// one, e.g. those sent to a proxy.
+		if r.URL.Host != "" {
+			u := *r.URL
+			u.Host = rewritten
+			r.URL = &u
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RestoreHost restores the Host rewritten by RewriteHost.
+func RestoreHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if original, ok := r.Context().Value(originalHostKey{}).(originalHost); ok {
+			r.Host = original.host
+			r.URL.Host = original.urlHost
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHostRewrites(t *testing.T) {
+	rewrites, err := ParseHostRewrites([]string{"S3.example.com:gateway.local", "other.test:gateway.local"})
+	require.NoError(t, err)
+	require.Equal(t, []HostRewrite{
+		{From: "s3.example.com", To: "gateway.local"},
+		{From: "other.test", To: "gateway.local"},
+	}, rewrites)
+
+	for _, entry := range []string{"", "s3.example.com", ":gateway.local", "s3.example.com:", "s3.example.com:gateway.local:443"} {
+		_, err = ParseHostRewrites([]string{entry})
+		require.Error(t, err, entry)
+	}
+}
+
+func TestRewriteHost(t *testing.T) {
+	rewrites := []HostRewrite{{From: "s3.example.com", To: "gateway.local"}}
+
+	testCases := []struct {
+		host     string
+		expected string
+	}{
+		{host: "s3.example.com", expected: "gateway.local"},
+		{host: "s3.example.com:7777", expected: "gateway.local:7777"},
+		{host: "bucket.S3.example.com", expected: "bucket.gateway.local"},
+		{host: "my.bucket.s3.example.com:443", expected: "my.bucket.gateway.local:443"},
+		{host: "bucket.gateway.local", expected: "bucket.gateway.local"},
+		{host: "nots3.example.com", expected: "nots3.example.com"},
+	}
+	for _, tc := range testCases {
+		rewritten, _ := rewriteHost(rewrites, tc.host)
+		require.Equal(t, tc.expected, rewritten, tc.host)
+	}
+}
+
+func TestRewriteAndRestoreHost(t *testing.T) {
+	rewrites := []HostRewrite{{From: "s3.example.com", To: "gateway.local"}}
+
+	var bucket, host string
+
+	r := mux.NewRouter()
+	r.Use(RestoreHost)
+	r.Host("{bucket:.+}.gateway.local").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bucket, host = mux.Vars(r)["bucket"], r.Host
+	})
+
+	rec := httptest.NewRecorder()
+	RewriteHost(rewrites, r).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://bucket.s3.example.com/object", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "bucket", bucket)
+	require.Equal(t, "bucket.s3.example.com", host)
+}
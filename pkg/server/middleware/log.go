@@ -19,6 +19,7 @@ import (
 	"storj.io/common/process/gcloudlogging"
 	"storj.io/edge/pkg/auth/authdb"
 	"storj.io/edge/pkg/httplog"
+	"storj.io/edge/pkg/httpserver"
 	"storj.io/edge/pkg/server/gwlog"
 	"storj.io/edge/pkg/trustedip"
 )
@@ -54,6 +55,12 @@ func LogRequests(log *zap.Logger, h http.Handler, insecureLogPaths bool) http.Ha
 
 // LogResponses logs responses.
 func LogResponses(log *zap.Logger, h http.Handler, insecureLogAll bool) http.Handler {
+	return logResponses(log, h, insecureLogAll, insecureLogAll)
+}
+
+// logResponses logs responses. If logPaths is true, bucket names and object
+// keys of gateway requests are logged even if insecureLogAll is false.
+func logResponses(log *zap.Logger, h http.Handler, insecureLogAll, logPaths bool) http.Handler {
 	return whmon.MonitorResponse(whroute.HandlerFunc(h,
 		func(w http.ResponseWriter, r *http.Request) {
 			rw := w.(whmon.ResponseWriter)
@@ -80,7 +87,7 @@ func LogResponses(log *zap.Logger, h http.Handler, insecureLogAll bool) http.Han
 			}
 
 			if gl.RequestID != "" {
-				logGatewayResponse(log, r, rw, gl, time.Since(start), insecureLogAll)
+				logGatewayResponse(log, r, rw, gl, time.Since(start), insecureLogAll, logPaths)
 				return
 			}
 
@@ -97,14 +104,15 @@ func NewLogRequests(log *zap.Logger, insecureLogPaths bool) mux.MiddlewareFunc {
 }
 
 // NewLogResponses is a convenience wrapper around LogResponses that returns
-// LogResponses as mux.MiddlewareFunc.
-func NewLogResponses(log *zap.Logger, insecureLogPaths bool) mux.MiddlewareFunc {
+// LogResponses as mux.MiddlewareFunc. If logPaths is true, bucket names and
+// object keys are logged without logging other confidential values.
+func NewLogResponses(log *zap.Logger, insecureLogAll, logPaths bool) mux.MiddlewareFunc {
 	return func(h http.Handler) http.Handler {
-		return LogResponses(log, h, insecureLogPaths)
+		return logResponses(log, h, insecureLogAll, insecureLogAll || logPaths)
 	}
 }
 
-func logGatewayResponse(log *zap.Logger, r *http.Request, rw whmon.ResponseWriter, gl *gwlog.Log, d time.Duration, insecureLogAll bool) {
+func logGatewayResponse(log *zap.Logger, r *http.Request, rw whmon.ResponseWriter, gl *gwlog.Log, d time.Duration, insecureLogAll, logPaths bool) {
 	ce := log.Check(httplog.StatusLevel(rw.StatusCode()), "response")
 	if ce == nil {
 		return
@@ -142,7 +150,7 @@ func logGatewayResponse(log *zap.Logger, r *http.Request, rw whmon.ResponseWrite
 		publicProjectID = credentials.PublicProjectID
 	}
 
-	ce.Write([]zapcore.Field{
+	fields := []zapcore.Field{
 		gcloudlogging.LogHTTPRequest(httpRequestLog),
 		gcloudlogging.LogOperation(&gcloudlogging.Operation{
 			ID:       gl.API,
@@ -169,7 +177,15 @@ func logGatewayResponse(log *zap.Logger, r *http.Request, rw whmon.ResponseWrite
 			Headers:                                 rw.Header(),
 			InsecureDisableConfidentialSanitization: true, // we don't need to hide any known response header values.
 		}),
-	}...)
+	}
+	if logPaths {
+		fields = append(fields, zap.String("bucket", gl.BucketName), zap.String("key", gl.ObjectName))
+	}
+	if identity, ok := httpserver.ClientIdentityFromContext(r.Context()); ok {
+		fields = append(fields, zap.String("client-identity", identity.Subject))
+	}
+
+	ce.Write(fields...)
 }
 
 func getRemoteIP(r *http.Request) string {
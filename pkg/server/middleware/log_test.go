@@ -107,6 +107,54 @@ func TestGatewayResponseNoPaths(t *testing.T) {
 	require.Len(t, filteredLogs.All(), 1)
 }
 
+func TestGatewayResponseObjectPaths(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	handler := func() http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if log, ok := gwlog.FromContext(r.Context()); ok {
+				log.RequestID = "ABC123"
+				log.BucketName = "bucket"
+				log.ObjectName = "key"
+			}
+
+			w.WriteHeader(http.StatusOK)
+		})
+	}
+
+	for _, logPaths := range []bool{false, true} {
+		req := httptest.NewRequest(http.MethodGet, "/bucket/key", nil).WithContext(ctx)
+		req.Header.Set("Authorization", "secret")
+		rr := httptest.NewRecorder()
+
+		observedZapCore, observedLogs := observer.New(zap.DebugLevel)
+		observedLogger := zap.New(observedZapCore)
+
+		NewLogResponses(observedLogger, false, logPaths)(handler()).ServeHTTP(rr, req)
+
+		require.Len(t, observedLogs.All(), 1)
+		entry := observedLogs.All()[0].ContextMap()
+
+		fields, ok := entry["httpRequest"].(map[string]interface{})
+		require.True(t, ok)
+		require.Nil(t, fields["requestUrl"])
+
+		headers, ok := entry["request-headers"].(map[string]interface{})
+		require.True(t, ok)
+		require.Equal(t, "[...]", headers["Authorization"])
+
+		if logPaths {
+			require.Equal(t, "bucket", entry["bucket"])
+			require.Equal(t, "key", entry["key"])
+		} else {
+			require.NotContains(t, entry, "bucket")
+			require.NotContains(t, entry, "key")
+		}
+		require.NotContains(t, entry, "client-identity")
+	}
+}
+
 func TestAccessDetailsLogged(t *testing.T) {
 	ctx := testcontext.New(t)
 	defer ctx.Cleanup()
@@ -40,7 +40,8 @@ type flusherDelegator struct {
 	// atWriteHeaderFunc is called at the call to WriteHeader.
 	atWriteHeaderFunc measureFunc
 
-	// atTimeToFirstByteFunc is called when bytes are first written.
+	// atTimeToFirstByteFunc is called once, when bytes are first written.
+	// Empty writes don't count.
 	atTimeToFirstByteFunc measureFunc
 
 	// afterWrite is called every time after Write is called.
@@ -65,7 +66,7 @@ func (f *flusherDelegator) Write(b []byte) (int, error) {
 		f.WriteHeader(http.StatusOK)
 	}
 	n, err := f.ResponseWriter.Write(b)
-	if f.atTimeToFirstByteFunc != nil && !f.observedTimeToFirstByte {
+	if f.atTimeToFirstByteFunc != nil && !f.observedTimeToFirstByte && n > 0 {
 		f.atTimeToFirstByteFunc(f.status)
 		f.observedTimeToFirstByte = true
 	}
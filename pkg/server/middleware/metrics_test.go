@@ -64,3 +64,28 @@ func TestMetrics(t *testing.T) {
 	assert.EqualValues(t, 3*bytesWritten, c["gmt_bytes_written,api=ListObjects,method=get,scope=storj.io/edge/pkg/server/middleware,status_code=500 sum"])
 	assert.EqualValues(t, bytesWritten, c["gmt_bytes_written,api=ListObjects,method=get,scope=storj.io/edge/pkg/server/middleware,status_code=500 recent"])
 }
+
+func TestFlusherDelegatorTimeToFirstByte(t *testing.T) {
+	var headers, firstBytes []int
+
+	d := &flusherDelegator{
+		ResponseWriter:        httptest.NewRecorder(),
+		atWriteHeaderFunc:     func(code int) { headers = append(headers, code) },
+		atTimeToFirstByteFunc: func(code int) { firstBytes = append(firstBytes, code) },
+	}
+
+	// an empty write sends the header, but no bytes yet.
+	_, err := d.Write(nil)
+	require.NoError(t, err)
+	require.Equal(t, []int{http.StatusOK}, headers)
+	require.Empty(t, firstBytes)
+
+	for i := 0; i < 10; i++ {
+		_, err := d.Write([]byte("data"))
+		require.NoError(t, err)
+	}
+	d.WriteHeader(http.StatusInternalServerError)
+
+	require.Equal(t, []int{http.StatusOK}, headers)
+	require.Equal(t, []int{http.StatusOK}, firstBytes)
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/edge/pkg/server/gwlog"
+)
+
+// OperationFilter selects the operations that access logs and events are
+// emitted for.
+type OperationFilter string
+
+const (
+	// AllOperations selects all operations.
+	AllOperations OperationFilter = "all"
+	// MutatingOperations selects operations that modify buckets or objects,
+	// e.g. PutObject, DeleteObject or the multipart upload operations.
+	MutatingOperations OperationFilter = "mutating"
+	// ReadOperations selects operations that don't modify anything.
+	ReadOperations OperationFilter = "reads"
+)
+
+// ParseOperationFilter parses s into an OperationFilter. An empty s selects
+// all operations.
+func ParseOperationFilter(s string) (OperationFilter, error) {
+	switch filter := OperationFilter(s); filter {
+	case "":
+		return AllOperations, nil
+	case AllOperations, MutatingOperations, ReadOperations:
+		return filter, nil
+	default:
+		return "", errs.New("invalid operation filter %q: must be one of %q, %q or %q", s, AllOperations, MutatingOperations, ReadOperations)
+	}
+}
+
+// includes returns whether the request, which must have been served already,
+// is selected by the filter.
+func (filter OperationFilter) includes(r *http.Request, gl *gwlog.Log) bool {
+	switch filter {
+	case MutatingOperations:
+		return isMutatingOperation(r.Method, gl.API)
+	case ReadOperations:
+		return !isMutatingOperation(r.Method, gl.API)
+	default:
+		return true
+	}
+}
+
+// readOperationsWithBody lists the S3 operations that don't modify anything
+// but aren't sent with a safe HTTP method.
+var readOperationsWithBody = map[string]bool{
+	"SelectObjectContent": true,
+}
+
+// isMutatingOperation classifies an S3 operation by its HTTP method and the
+// API name minio logged for it.
+func isMutatingOperation(method, api string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return !readOperationsWithBody[api]
+}
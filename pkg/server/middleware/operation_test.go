@@ -0,0 +1,58 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/edge/pkg/server/gwlog"
+)
+
+func TestParseOperationFilter(t *testing.T) {
+	for s, expected := range map[string]OperationFilter{
+		"":         AllOperations,
+		"all":      AllOperations,
+		"mutating": MutatingOperations,
+		"reads":    ReadOperations,
+	} {
+		filter, err := ParseOperationFilter(s)
+		require.NoError(t, err, s)
+		require.Equal(t, expected, filter, s)
+	}
+
+	_, err := ParseOperationFilter("writes")
+	require.Error(t, err)
+}
+
+func TestOperationFilter(t *testing.T) {
+	testCases := []struct {
+		method   string
+		api      string
+		mutating bool
+	}{
+		{method: http.MethodGet, api: "GetObject", mutating: false},
+		{method: http.MethodHead, api: "HeadObject", mutating: false},
+		{method: http.MethodGet, api: "ListObjectsV2", mutating: false},
+		{method: http.MethodPost, api: "SelectObjectContent", mutating: false},
+		{method: http.MethodPut, api: "PutObject", mutating: true},
+		{method: http.MethodPut, api: "PutObjectPart", mutating: true},
+		{method: http.MethodPost, api: "NewMultipartUpload", mutating: true},
+		{method: http.MethodPost, api: "DeleteMultipleObjects", mutating: true},
+		{method: http.MethodDelete, api: "DeleteObject", mutating: true},
+		{method: http.MethodPut, api: "unknown", mutating: true},
+	}
+	for _, tc := range testCases {
+		r := httptest.NewRequest(tc.method, "/bucket/key", nil)
+		gl := gwlog.New()
+		gl.API = tc.api
+
+		require.True(t, AllOperations.includes(r, gl), tc.api)
+		require.Equal(t, tc.mutating, MutatingOperations.includes(r, gl), tc.api)
+		require.Equal(t, !tc.mutating, ReadOperations.includes(r, gl), tc.api)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/zeebo/errs"
+
+	"storj.io/minio/cmd"
+)
+
+// maxPresignedExpiry is the longest validity S3 allows presigned URLs.
+const maxPresignedExpiry = 7 * 24 * time.Hour
+
+// maxPresignedClockSkew is how far in the future minio accepts the signing
+// time of presigned URLs to be; the gateway can only be stricter.
+const maxPresignedClockSkew = 15 * time.Minute
+
+// PresignedURLsConfig configures which presigned URLs are accepted.
+type PresignedURLsConfig struct {
+	MaxExpiry time.Duration `help:"maximum validity (X-Amz-Expires) of signature V4 presigned URLs; URLs valid for longer are rejected. At most 168h0m0s" default:"168h0m0s"`
+	ClockSkew time.Duration `help:"how far in the future the signing time (X-Amz-Date) of signature V4 presigned URLs may be, to tolerate clients with clocks running ahead. At most 15m0s" default:"15m0s"`
+}
+
+// NewPresignedURLs returns a middleware that rejects signature V4 presigned
+// URLs that are valid for longer than config.MaxExpiry, signed more than
+// config.ClockSkew in the future or expired, before their signatures are
+// verified.
+//
+// Requests whose X-Amz-Date or X-Amz-Expires are missing or malformed are
+// left to the signature verification to reject.
+func NewPresignedURLs(config PresignedURLsConfig) (mux.MiddlewareFunc, error) {
+	if config.MaxExpiry <= 0 || config.MaxExpiry > maxPresignedExpiry {
+		return nil, errs.New("presigned URL max expiry must be positive and at most %s, got %s", maxPresignedExpiry, config.MaxExpiry)
+	}
+	if config.ClockSkew < 0 || config.ClockSkew > maxPresignedClockSkew {
+		return nil, errs.New("presigned URL clock skew must not be negative and at most %s, got %s", maxPresignedClockSkew, config.ClockSkew)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isRequestPresignedSignatureV4(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if apiErr, ok := checkPresignedV4Validity(r, config, time.Now()); !ok {
+				mon.Event("presigned_url_rejected")
+				cmd.WriteErrorResponse(r.Context(), w, apiErr, r.URL, false)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// checkPresignedV4Validity checks whether the signature V4 presigned URL of r
+// is valid at now and returns the error to respond with if it isn't.
+func checkPresignedV4Validity(r *http.Request, config PresignedURLsConfig, now time.Time) (cmd.APIError, bool) {
+	q := r.URL.Query()
+
+	date, err := time.Parse(iso8601Format, q.Get("X-Amz-Date"))
+	if err != nil {
+		return cmd.APIError{}, true
+	}
+	seconds, err := strconv.ParseInt(q.Get("X-Amz-Expires"), 10, 64)
+	if err != nil || seconds < 0 {
+		return cmd.APIError{}, true
+	}
+	expires := time.Duration(seconds) * time.Second
+
+	switch {
+	case expires > config.MaxExpiry:
+		return cmd.APIError{
+			Code:           "AuthorizationQueryParametersError",
+			Description:    fmt.Sprintf("X-Amz-Expires must be less than or equal to %d seconds.", int64(config.MaxExpiry/time.Second)),
+			HTTPStatusCode: http.StatusBadRequest,
+		}, false
+	case date.After(now.Add(config.ClockSkew)):
+		return cmd.GetAPIError(cmd.ErrRequestNotReadyYet), false
+	case now.After(date.Add(expires)):
+		return cmd.GetAPIError(cmd.ErrExpiredPresignRequest), false
+	}
+	return cmd.APIError{}, true
+}
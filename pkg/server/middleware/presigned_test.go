@@ -0,0 +1,70 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresignedURLsConfig(t *testing.T) {
+	for _, config := range []PresignedURLsConfig{
+		{MaxExpiry: 0, ClockSkew: time.Minute},
+		{MaxExpiry: 8 * 24 * time.Hour, ClockSkew: time.Minute},
+		{MaxExpiry: time.Hour, ClockSkew: -time.Minute},
+		{MaxExpiry: time.Hour, ClockSkew: time.Hour},
+	} {
+		_, err := NewPresignedURLs(config)
+		require.Error(t, err, config)
+	}
+}
+
+func TestPresignedURLs(t *testing.T) {
+	presigned, err := NewPresignedURLs(PresignedURLsConfig{MaxExpiry: time.Hour, ClockSkew: 5 * time.Minute})
+	require.NoError(t, err)
+
+	handler := presigned(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	now := time.Now().UTC()
+
+	presignedURL := func(date time.Time, expires time.Duration) string {
+		q := url.Values{}
+		q.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+		q.Set("X-Amz-Credential", "accesskey/"+date.Format("20060102")+"/us-east-1/s3/aws4_request")
+		q.Set("X-Amz-Date", date.Format(iso8601Format))
+		q.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+		q.Set("X-Amz-SignedHeaders", "host")
+		q.Set("X-Amz-Signature", "signature")
+		return "/bucket/object?" + q.Encode()
+	}
+
+	for _, tc := range []struct {
+		name   string
+		url    string
+		status int
+	}{
+		{name: "not presigned", url: "/bucket/object", status: http.StatusOK},
+		{name: "valid", url: presignedURL(now, time.Hour), status: http.StatusOK},
+		{name: "expiry too long", url: presignedURL(now, time.Hour+time.Second), status: http.StatusBadRequest},
+		{name: "expired", url: presignedURL(now.Add(-2*time.Hour), time.Hour), status: http.StatusForbidden},
+		{name: "future-dated within clock skew", url: presignedURL(now.Add(time.Minute), time.Hour), status: http.StatusOK},
+		{name: "future-dated", url: presignedURL(now.Add(10*time.Minute), time.Hour), status: http.StatusForbidden},
+		{name: "malformed date", url: "/bucket/object?X-Amz-Credential=x&X-Amz-Date=yesterday&X-Amz-Expires=60", status: http.StatusOK},
+		{name: "malformed expiry", url: "/bucket/object?X-Amz-Credential=x&X-Amz-Date=" + now.Format(iso8601Format) + "&X-Amz-Expires=soon", status: http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.url, nil))
+			require.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"storj.io/common/http/requestid"
+	"storj.io/common/memory"
+	"storj.io/eventkit"
+)
+
+// ProgressEventsConfig configures progress events of long-running transfers.
+type ProgressEventsConfig struct {
+	Enabled      bool          `help:"whether to send progress events during uploads and downloads" default:"false"`
+	ByteInterval memory.Size   `help:"number of bytes transferred between progress events" default:"256MiB"`
+	TimeInterval time.Duration `help:"time between progress events of a transfer that is still going, including one that stalled" default:"1m0s"`
+}
+
+// progress keeps track of the bytes transferred in one direction of a request
+// and sends a progress event whenever the configured number of bytes were
+// transferred or time passed since the last one. The latter events are sent
+// by a timer, so that transfers that stalled are reported too.
+//
+// Events only carry what's known of the request when the progress is
+// created, as the timer must not read anything the request's handlers may
+// change meanwhile, like its log.
+type progress struct {
+	config      ProgressEventsConfig
+	direction   string
+	method      string
+	requestID   string
+	requestSize int64
+	start       time.Time
+
+	mu            sync.Mutex
+	timer         *time.Timer
+	stopped       bool
+	bytes         int64
+	lastBytes     int64
+	lastEventTime time.Time
+}
+
+func newProgress(config ProgressEventsConfig, r *http.Request, direction string, start time.Time) *progress {
+	return &progress{
+		config:        config,
+		direction:     direction,
+		method:        r.Method,
+		requestID:     requestid.FromContext(r.Context()),
+		requestSize:   r.ContentLength,
+		start:         start,
+		lastEventTime: start,
+	}
+}
+
+// startTimer starts sending progress events every TimeInterval, unless
+// another event was sent in the meantime, until stop is called.
+func (p *progress) startTimer() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.config.TimeInterval <= 0 || p.timer != nil || p.stopped {
+		return
+	}
+	p.timer = time.AfterFunc(p.config.TimeInterval, p.tick)
+}
+
+func (p *progress) tick() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopped {
+		return
+	}
+	now := time.Now()
+	if wait := p.config.TimeInterval - now.Sub(p.lastEventTime); wait > 0 {
+		// an event was sent since the timer was set.
+		p.timer.Reset(wait)
+		return
+	}
+	p.emit(now)
+	p.timer.Reset(p.config.TimeInterval)
+}
+
+// stop stops sending progress events once the transfer is over.
+func (p *progress) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stopped = true
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+}
+
+// add records n more bytes transferred.
+func (p *progress) add(n int64) {
+	if n <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.bytes += n
+
+	if p.config.ByteInterval > 0 && p.bytes-p.lastBytes >= p.config.ByteInterval.Int64() {
+		p.emit(time.Now())
+	}
+}
+
+func (p *progress) emit(now time.Time) {
+	elapsed := now.Sub(p.start)
+
+	var throughput float64
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		throughput = float64(p.bytes) / seconds
+	}
+
+	ek.Event("gmt-progress",
+		eventkit.String("direction", p.direction),
+		eventkit.String("method", p.method),
+		eventkit.String("request-id", p.requestID),
+		eventkit.Int64("request-size", p.requestSize),
+		eventkit.Int64("bytes", p.bytes),
+		eventkit.Bool("stalled", p.bytes == p.lastBytes),
+		eventkit.Duration("elapsed", elapsed),
+		eventkit.Float64("bytes-per-second", throughput))
+
+	p.lastBytes = p.bytes
+	p.lastEventTime = now
+}
+
+// progressReader counts bytes read from the request body.
+type progressReader struct {
+	io.ReadCloser
+	progress *progress
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	r.progress.add(int64(n))
+	if err != nil {
+		// the upload is over, whether it was read completely or not.
+		r.progress.stop()
+	}
+	return n, err
+}
+
+// ProgressEvents sends progress events through eventkit while request bodies
+// are read (uploads) and response bodies are written (downloads), so that
+// long-running transfers can be monitored before they finish.
+func ProgressEvents(config ProgressEventsConfig, next http.Handler) http.Handler {
+	if !config.Enabled {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		if r.Body != nil && r.Body != http.NoBody {
+			upload := newProgress(config, r, "upload", start)
+			upload.startTimer()
+			defer upload.stop()
+
+			r.Body = &progressReader{
+				ReadCloser: r.Body,
+				progress:   upload,
+			}
+		}
+
+		// the download is timed from its first byte on, so that requests
+		// without a response body, e.g. uploads, don't report a stalled one.
+		download := newProgress(config, r, "download", start)
+		defer download.stop()
+
+		next.ServeHTTP(&flusherDelegator{
+			ResponseWriter: w,
+			afterWrite: func(_ int, n int64) {
+				download.startTimer()
+				download.add(n)
+			},
+		}, r)
+	})
+}
+
+// NewProgressEvents is a convenience wrapper around ProgressEvents that
+// returns ProgressEvents with config as mux.MiddlewareFunc.
+func NewProgressEvents(config ProgressEventsConfig) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return ProgressEvents(config, h)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/memory"
+	"storj.io/common/testrand"
+)
+
+func TestProgress(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	p := newProgress(ProgressEventsConfig{
+		Enabled:      true,
+		ByteInterval: 10 * memory.B,
+		TimeInterval: time.Hour,
+	}, r, "download", time.Now())
+
+	p.add(5)
+	require.EqualValues(t, 0, p.lastBytes)
+	p.add(5)
+	require.EqualValues(t, 10, p.lastBytes)
+	p.add(9)
+	require.EqualValues(t, 10, p.lastBytes)
+	p.add(0)
+	require.EqualValues(t, 10, p.lastBytes)
+	p.add(1)
+	require.EqualValues(t, 20, p.lastBytes)
+
+}
+
+func TestProgressTimer(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	start := time.Now()
+	p := newProgress(ProgressEventsConfig{
+		Enabled:      true,
+		ByteInterval: memory.GiB,
+		TimeInterval: 10 * time.Millisecond,
+	}, r, "download", start)
+
+	lastEvent := func() (time.Time, int64) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return p.lastEventTime, p.lastBytes
+	}
+
+	p.add(5)
+	p.startTimer()
+
+	// events are sent without any further bytes transferred, i.e. for a
+	// stalled transfer.
+	var last time.Time
+	require.Eventually(t, func() bool {
+		last, _ = lastEvent()
+		return last.After(start)
+	}, 5*time.Second, time.Millisecond)
+	require.Eventually(t, func() bool {
+		next, bytes := lastEvent()
+		return next.After(last) && bytes == 5
+	}, 5*time.Second, time.Millisecond)
+
+	p.stop()
+	stopped, _ := lastEvent()
+	time.Sleep(50 * time.Millisecond)
+	after, _ := lastEvent()
+	require.Equal(t, stopped, after)
+}
+
+func TestProgressEvents(t *testing.T) {
+	upload := testrand.BytesInt(int(memory.KiB))
+	download := testrand.BytesInt(int(memory.KiB))
+
+	handler := ProgressEvents(ProgressEventsConfig{
+		Enabled:      true,
+		ByteInterval: 100 * memory.B,
+		TimeInterval: time.Minute,
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, upload, body)
+
+		_, err = w.Write(download)
+		require.NoError(t, err)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/bucket/object", bytes.NewReader(upload)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, download, rec.Body.Bytes())
+}
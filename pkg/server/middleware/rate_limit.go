@@ -0,0 +1,107 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/zeebo/errs"
+
+	"storj.io/edge/internal/lrucache"
+	"storj.io/minio/cmd"
+)
+
+// RateLimitConfig configures rate limiting of requests per credential.
+type RateLimitConfig struct {
+	Enabled bool    `help:"whether to rate-limit requests per credential, i.e. project ID or, if unavailable, macaroon head" default:"false"`
+	Rate    float64 `help:"number of requests per second a single credential can make on average" default:"100"`
+	Burst   int     `help:"number of requests a single credential can make at once" default:"200"`
+	MaxKeys int     `help:"maximum number of credentials tracked at once; the least recently used ones are forgotten" default:"100000"`
+}
+
+// NewRateLimit returns a middleware that limits the rate of requests per
+// project ID, or if unavailable, macaroon head with token buckets and
+// responds to requests over the limit with SlowDown errors.
+//
+// It relies on the AccessKey middleware being run before it. Requests without
+// credentials aren't limited, as they fail anyway.
+func NewRateLimit(config RateLimitConfig) (mux.MiddlewareFunc, error) {
+	if !config.Enabled {
+		return func(next http.Handler) http.Handler { return next }, nil
+	}
+	if config.Rate <= 0 || config.Burst <= 0 || config.MaxKeys <= 0 {
+		return nil, errs.New("rate limit rate, burst and max keys must be positive")
+	}
+
+	buckets := lrucache.NewOf[*tokenBucket](lrucache.Options{
+		Capacity: config.MaxKeys,
+		Name:     "rate_limit_buckets",
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			key, err := getLimitKey(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			b, err := buckets.Get(ctx, key, func() (*tokenBucket, error) {
+				return newTokenBucket(config.Rate, config.Burst, time.Now()), nil
+			})
+			if err == nil && !b.take(time.Now()) {
+				mon.Event("rate_limited")
+				cmd.WriteErrorResponse(ctx, w, cmd.GetAPIError(cmd.ErrSlowDown), r.URL, false)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// tokenBucket holds up to burst tokens and refills at rate tokens per second.
+type tokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   now,
+	}
+}
+
+// take refills the bucket for the time passed since it was last used and
+// returns whether a token was available, consuming it if it was.
+func (b *tokenBucket) take(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += b.rate * elapsed.Seconds()
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
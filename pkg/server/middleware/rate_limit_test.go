@@ -0,0 +1,74 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+)
+
+func TestRateLimit(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	_, err := NewRateLimit(RateLimitConfig{Enabled: true})
+	require.Error(t, err)
+
+	rateLimit, err := NewRateLimit(RateLimitConfig{
+		Enabled: true,
+		Rate:    0.001,
+		Burst:   2,
+		MaxKeys: 10,
+	})
+	require.NoError(t, err)
+	limited := rateLimit(handler)
+
+	creds := getCredentials(t, true)
+	require.Equal(t, http.StatusOK, doRequest(ctx, t, creds, limited))
+	require.Equal(t, http.StatusOK, doRequest(ctx, t, creds, limited))
+	require.Equal(t, http.StatusServiceUnavailable, doRequest(ctx, t, creds, limited))
+
+	// credentials of the same project share its bucket.
+	sameProject := getCredentials(t, false)
+	sameProject.PublicProjectID = creds.PublicProjectID
+	require.Equal(t, http.StatusServiceUnavailable, doRequest(ctx, t, sameProject, limited))
+
+	// other credentials have buckets of their own.
+	require.Equal(t, http.StatusOK, doRequest(ctx, t, getCredentials(t, false), limited))
+
+	// requests without credentials aren't limited.
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusOK, doRequest(ctx, t, &Credentials{}, limited))
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(2, 3, now)
+
+	for i := 0; i < 3; i++ {
+		require.True(t, b.take(now))
+	}
+	require.False(t, b.take(now))
+
+	// half a second refills a single token.
+	now = now.Add(500 * time.Millisecond)
+	require.True(t, b.take(now))
+	require.False(t, b.take(now))
+
+	// the bucket never holds more than burst tokens.
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		require.True(t, b.take(now))
+	}
+	require.False(t, b.take(now))
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/spacemonkeygo/monkit/v3"
+
+	"storj.io/common/http/requestid"
+)
+
+// RequestIDConfig configures the header request IDs are exchanged in.
+type RequestIDConfig struct {
+	Header string `help:"header to take the request ID from, if it's valid, and to return it in; it's always returned in X-Request-Id too" default:"X-Request-Id"`
+}
+
+// NewRequestID returns a middleware that adds a request ID to the context of
+// requests, see requestid.AddToContext. The ID sent by the client in the
+// configured header is reused if it's valid, otherwise a new one is
+// generated. Either way it's returned in the configured header and in
+// X-Request-Id.
+func NewRequestID(config RequestIDConfig) mux.MiddlewareFunc {
+	header := http.CanonicalHeaderKey(config.Header)
+	if header == "" {
+		header = requestid.HeaderKey
+	}
+
+	return func(next http.Handler) http.Handler {
+		addToContext := requestid.AddToContext(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if id := requestid.FromContext(r.Context()); id != "" && header != requestid.HeaderKey {
+				w.Header().Set(header, id)
+			}
+			next.ServeHTTP(w, r)
+		}))
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(header)
+			// requestid.AddToContext reads the ID from X-Request-Id, which
+			// the client must not set if the ID is exchanged in another
+			// header.
+			r.Header.Del(requestid.HeaderKey)
+			if validRequestID(id) {
+				r.Header.Set(requestid.HeaderKey, id)
+			}
+			addToContext.ServeHTTP(w, r)
+		})
+	}
+}
+
+// validRequestID returns whether id is short enough and only consists of
+// characters that are safe to log and return in headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > requestid.MaxRequestID {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '+', c == '/', c == '=':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// TraceRequestID annotates the span of requests with their request ID. It
+// must be chained after the middleware that starts the span.
+func TraceRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if span := monkit.SpanFromCtx(r.Context()); span != nil {
+			if id := requestid.FromContext(r.Context()); id != "" {
+				span.Annotate("request_id", id)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,85 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/http/requestid"
+)
+
+func TestRequestID(t *testing.T) {
+	var got string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = requestid.FromContext(r.Context())
+	})
+
+	testCases := []struct {
+		desc    string
+		header  string
+		inbound map[string]string
+		reused  string
+	}{
+		{desc: "default header", inbound: map[string]string{"X-Request-Id": "abc-123"}, reused: "abc-123"},
+		{desc: "default header, generated", inbound: map[string]string{}},
+		{desc: "default header, invalid", inbound: map[string]string{"X-Request-Id": "abc 123"}},
+		{desc: "default header, too long", inbound: map[string]string{"X-Request-Id": strings.Repeat("a", requestid.MaxRequestID+1)}},
+		{desc: "custom header", header: "x-edge-request-id", inbound: map[string]string{"X-Edge-Request-Id": "edge:1"}, reused: "edge:1"},
+		{desc: "custom header, X-Request-Id ignored", header: "X-Edge-Request-Id", inbound: map[string]string{"X-Request-Id": "abc-123"}},
+		{desc: "custom header, invalid", header: "X-Edge-Request-Id", inbound: map[string]string{"X-Edge-Request-Id": "<script>"}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			got = ""
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tc.inbound {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+
+			NewRequestID(RequestIDConfig{Header: tc.header})(handler).ServeHTTP(rr, req)
+
+			require.NotEmpty(t, got)
+			if tc.reused != "" {
+				require.Equal(t, tc.reused, got)
+			} else {
+				for _, v := range tc.inbound {
+					require.NotEqual(t, v, got)
+				}
+			}
+
+			require.Equal(t, got, rr.Header().Get("X-Request-Id"))
+			if tc.header != "" {
+				require.Equal(t, got, rr.Header().Get(tc.header))
+			}
+		})
+	}
+}
+
+func TestTraceRequestID(t *testing.T) {
+	var annotations []monkit.Annotation
+
+	handler := requestid.AddToContext(TraceRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		annotations = monkit.SpanFromCtx(r.Context()).Annotations()
+	})))
+
+	ctx := context.Background()
+	defer mon.Task()(&ctx)(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	req.Header.Set("X-Request-Id", "abc-123")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Contains(t, annotations, monkit.Annotation{Name: "request_id", Value: "abc-123"})
+}
@@ -0,0 +1,67 @@
+// Copyright (C) 2025 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/framed" {
+			w.Header().Del("X-Frame-Options")
+		}
+	})
+
+	config := SecurityHeadersConfig{
+		Enabled:               true,
+		HSTSMaxAge:            time.Hour,
+		FrameOptions:          "DENY",
+		ContentSecurityPolicy: "default-src 'self'",
+	}
+
+	serve := func(config SecurityHeadersConfig, path string, overTLS bool) http.Header {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if overTLS {
+			req.TLS = &tls.ConnectionState{}
+		}
+		rr := httptest.NewRecorder()
+		NewSecurityHeaders(config)(handler).ServeHTTP(rr, req)
+		return rr.Header()
+	}
+
+	h := serve(config, "/", true)
+	require.Equal(t, "nosniff", h.Get("X-Content-Type-Options"))
+	require.Equal(t, "DENY", h.Get("X-Frame-Options"))
+	require.Equal(t, "default-src 'self'", h.Get("Content-Security-Policy"))
+	require.Equal(t, "max-age=3600", h.Get("Strict-Transport-Security"))
+
+	h = serve(config, "/", false)
+	require.Equal(t, "nosniff", h.Get("X-Content-Type-Options"))
+	require.Empty(t, h.Values("Strict-Transport-Security"))
+
+	h = serve(config, "/framed", true)
+	require.Empty(t, h.Values("X-Frame-Options"))
+
+	includeSubdomains := config
+	includeSubdomains.HSTSIncludeSubdomains = true
+	h = serve(includeSubdomains, "/", true)
+	require.Equal(t, "max-age=3600; includeSubDomains", h.Get("Strict-Transport-Security"))
+
+	h = serve(SecurityHeadersConfig{Enabled: true}, "/", true)
+	require.Equal(t, "nosniff", h.Get("X-Content-Type-Options"))
+	require.Empty(t, h.Values("X-Frame-Options"))
+	require.Empty(t, h.Values("Content-Security-Policy"))
+	require.Empty(t, h.Values("Strict-Transport-Security"))
+
+	config.Enabled = false
+	h = serve(config, "/", true)
+	require.Empty(t, h)
+}
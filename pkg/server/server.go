@@ -5,6 +5,7 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -21,10 +22,12 @@ import (
 
 	"storj.io/common/accesslogs"
 	"storj.io/common/errs2"
-	"storj.io/common/http/requestid"
 	"storj.io/common/rpc/rpcpool"
 	"storj.io/common/version"
 	"storj.io/edge/pkg/authclient"
+	"storj.io/edge/pkg/bucketconfig"
+	"storj.io/edge/pkg/health"
+	"storj.io/edge/pkg/httplog"
 	"storj.io/edge/pkg/httpserver"
 	"storj.io/edge/pkg/minio"
 	"storj.io/edge/pkg/server/gw"
@@ -57,6 +60,8 @@ type Peer struct {
 
 	config Config
 
+	errorRate *health.Tracker
+
 	closeLayer func(context.Context) error
 
 	inShutdown int32
@@ -104,7 +109,7 @@ func New(config Config, log *zap.Logger, trustedIPs trustedip.List, corsAllowedO
 		return nil, err
 	}
 
-	layer, err := gw.NewMultiTenantLayer(miniogw.NewStorjGateway(config.S3Compatibility), satelliteConnectionPool, connectionPool, uplinkConfig, satelliteIdentities)
+	layer, err := gw.NewMultiTenantLayer(miniogw.NewStorjGateway(config.S3Compatibility), satelliteConnectionPool, connectionPool, uplinkConfig, satelliteIdentities, config.UploadFanOut, config.UploadParts)
 	if err != nil {
 		return nil, err
 	}
@@ -132,7 +137,17 @@ func New(config Config, log *zap.Logger, trustedIPs trustedip.List, corsAllowedO
 		return nil, err
 	}
 
-	minio.RegisterAPIRouter(r, layer, dedupedDomains, concurrentAllowed, corsAllowedOrigins)
+	var corsAllowedHeaders []string
+	if config.CorsAllowedHeaders != "" {
+		corsAllowedHeaders = strings.Split(config.CorsAllowedHeaders, ",")
+	}
+
+	bucketConfigs, err := bucketconfig.Open(config.BucketConfigs, uplinkConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	minio.RegisterAPIRouter(r, layer, dedupedDomains, concurrentAllowed, corsAllowedOrigins, corsAllowedHeaders, config.CorsMaxAge, bucketConfigs, config.DefaultRegion)
 
 	processor := accesslogs.NewProcessor(log, config.AccessLogsProcessor)
 	accessLogsConfigs, err := middleware.ParseAccessLogConfig(log, config.ServerAccessLogging)
@@ -140,34 +155,94 @@ func New(config Config, log *zap.Logger, trustedIPs trustedip.List, corsAllowedO
 		return nil, err
 	}
 
-	r.Use(requestid.AddToContext)
+	logOperations, err := middleware.ParseOperationFilter(config.LogOperations)
+	if err != nil {
+		return nil, err
+	}
+
+	hostRewrites, err := middleware.ParseHostRewrites(config.HostRewrites)
+	if err != nil {
+		return nil, err
+	}
+
+	checksumTrailers, err := middleware.NewChecksumTrailers(config.ChecksumTrailers)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimit, err := middleware.NewRateLimit(config.RateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	presignedURLs, err := middleware.NewPresignedURLs(config.PresignedURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	errorRate, err := health.NewTracker(config.Health)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Use(middleware.RestoreHost)
+	r.Use(middleware.NewRequestID(config.RequestID))
+	r.Use(middleware.NewSecurityHeaders(config.SecurityHeaders))
+	r.Use(middleware.NewErrorResponses(config.ErrorResponses))
 	r.Use(func(handler http.Handler) http.Handler {
 		return mhttp.TraceHandler(handler, mon)
 	})
+	r.Use(middleware.TraceRequestID)
 	r.Use(middleware.NewMetrics("gmt"))
+	r.Use(middleware.NewBucketMetrics("gmt", config.BucketMetrics))
+
+	r.Use(middleware.NewPutObjectSizeLimit(config.MaxPutObjectSize))
+	r.Use(presignedURLs)
+
 	r.Use(middleware.AccessKey(authClient, trustedIPs, log))
-	r.Use(middleware.CollectEvent)
-	r.Use(middleware.AccessLog(log, processor, accessLogsConfigs))
+	r.Use(rateLimit)
+	// errors are tracked inside the rate limiter, as rate-limited requests
+	// don't reflect the health of the backends.
+	r.Use(middleware.TrackErrorRate(errorRate))
+	r.Use(middleware.NewCollectEvent(logOperations))
+	r.Use(middleware.NewProgressEvents(config.ProgressEvents))
+	r.Use(checksumTrailers)
+	r.Use(middleware.AccessLog(log, processor, accessLogsConfigs, logOperations))
 
 	for i, m := range cmd.GlobalHandlers {
 		r.Use(middleware.MonitorMinioGlobalHandler(i, m))
 	}
 
-	// we deliberately don't log paths for this service because they have
-	// sensitive information. Note that middleware.AccessKey is chained before
+	httplog.SetRedactedHeaders(config.RedactedHeaders)
+
+	// we deliberately don't log paths for this service by default because they
+	// have sensitive information. Note that middleware.AccessKey is chained before
 	// so we can use encrypted credentials while logging requests/responses.
 	r.Use(middleware.NewLogRequests(log, config.InsecureLogAll))
-	r.Use(middleware.NewLogResponses(log, config.InsecureLogAll))
-
-	var handler http.Handler = minio.CriticalErrorHandler{Handler: minio.CorsHandler(corsAllowedOrigins)(r)}
+	r.Use(middleware.NewLogResponses(log, config.InsecureLogAll, config.LogObjectPaths))
+
+	// CORS is handled after host rewrites so that buckets addressed by
+	// virtual hosts of rewritten hosts are found.
+	corsHandler := minio.CorsHandler(corsAllowedOrigins, corsAllowedHeaders, config.CorsMaxAge, &minio.BucketCORS{
+		AuthClient:  authClient,
+		TrustedIPs:  trustedIPs,
+		DomainNames: dedupedDomains,
+		Store:       bucketConfigs,
+	})
+	var handler http.Handler = minio.CriticalErrorHandler{Handler: middleware.RewriteHost(hostRewrites, corsHandler(r))}
 
 	var tlsConfig *httpserver.TLSConfig
 	if !config.InsecureDisableTLS {
 		tlsConfig = &httpserver.TLSConfig{
-			CertDir:                            config.CertDir,
-			CertMagic:                          config.CertMagic.Enabled,
-			CertMagicKeyFile:                   config.CertMagic.KeyFile,
-			CertMagicDNSChallengeWithGCloudDNS: true,
+			CertDir:                       config.CertDir,
+			CertReloadInterval:            config.CertReloadInterval,
+			MinTLSVersion:                 config.MinTLSVersion,
+			EnableOCSPStapling:            config.OCSPStapling,
+			ClientAuth:                    config.ClientAuth,
+			ClientCAFile:                  config.ClientCAFile,
+			CertMagic:                     config.CertMagic.Enabled,
+			CertMagicKeyFile:              config.CertMagic.KeyFile,
+			CertMagicDNSChallengeProvider: config.CertMagic.DNSProvider,
 			CertMagicDNSChallengeWithGCloudDNSProject: config.CertMagic.Project,
 			CertMagicDNSChallengeOverrideDomain:       config.CertMagic.ChallengeOverrideDomain,
 			CertMagicEmail:                            config.CertMagic.Email,
@@ -179,14 +254,16 @@ func New(config Config, log *zap.Logger, trustedIPs trustedip.List, corsAllowedO
 	}
 
 	server, err := httpserver.New(log, handler, nil, httpserver.Config{
-		Address:            config.Server.Address,
-		AddressTLS:         config.Server.AddressTLS,
-		ProxyAddressTLS:    config.Server.ProxyAddressTLS,
-		TLSConfig:          tlsConfig,
-		DisableHTTP2:       config.DisableHTTP2,
-		TrafficLogging:     false, // gateway-mt has its own logging middleware for this
-		StartupCheckConfig: httpserver.StartupCheckConfig(config.StartupCheck),
-		IdleTimeout:        config.IdleTimeout,
+		Address:              config.Server.Address,
+		AddressTLS:           config.Server.AddressTLS,
+		ProxyAddressTLS:      config.Server.ProxyAddressTLS,
+		TLSConfig:            tlsConfig,
+		DisableHTTP2:         config.DisableHTTP2,
+		TrafficLogging:       false, // gateway-mt has its own logging middleware for this
+		StartupCheckConfig:   httpserver.StartupCheckConfig(config.StartupCheck),
+		IdleTimeout:          config.IdleTimeout,
+		ReadHeaderTimeout:    config.ReadHeaderTimeout,
+		MaxConcurrentStreams: uint32(config.MaxConcurrentStreams),
 	})
 	if err != nil {
 		return nil, err
@@ -197,6 +274,7 @@ func New(config Config, log *zap.Logger, trustedIPs trustedip.List, corsAllowedO
 		processor:  processor,
 		server:     server,
 		config:     config,
+		errorRate:  errorRate,
 		closeLayer: layer.Shutdown,
 	}
 	publicServices.HandleFunc("/health", peer.healthCheck)
@@ -241,7 +319,24 @@ func (s *Peer) healthCheck(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "down", http.StatusServiceUnavailable)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
+	if s.errorRate == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	status, reason := s.errorRate.Status()
+
+	code := http.StatusOK
+	if status == health.StatusUnhealthy {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(struct {
+		Status health.Status `json:"status"`
+		Reason string        `json:"reason,omitempty"`
+	}{status, reason})
 }
 
 func versionInfo(w http.ResponseWriter, r *http.Request) {
@@ -4,6 +4,7 @@
 package trustedip
 
 import (
+	"net"
 	"net/http"
 	"regexp"
 	"strings"
@@ -11,10 +12,15 @@ import (
 
 // List is a list of trusted IPs for conveniently verifying if an IP is trusted.
 type List struct {
-	// ips is the list of trusted IPs. It's used when untrustAll is false. When
-	// empty it trusts any IP.
+	// ips and nets are the trusted IPs and IP ranges. They're used when
+	// untrustAll is false. When both are empty it trusts any IP.
 	ips        map[string]struct{}
+	nets       []*net.IPNet
 	untrustAll bool
+
+	// trustedHops is the number of proxies, including the one connecting to
+	// us, whose 'X-Forwarded-For' entries may be skipped to find the client IP.
+	trustedHops int
 }
 
 // NewListUntrustAll creates a new List which doesn't trust in any IP.
@@ -27,32 +33,64 @@ func NewListTrustAll() List {
 	return List{}
 }
 
-// NewList creates a new List which trusts the passed ips.
+// NewList creates a new List which trusts the passed ips. An ip in CIDR
+// notation (e.g. 10.0.0.0/24) trusts the whole range.
 //
 // NOTE: ips are not checked to be well formatted and their values are what they
-// kept in the list.
+// kept in the list; an invalid CIDR is kept as an exact IP.
 func NewList(ips ...string) List {
 	l := List{ips: make(map[string]struct{}, len(ips))}
 
 	for _, ip := range ips {
+		if strings.Contains(ip, "/") {
+			if _, ipNet, err := net.ParseCIDR(ip); err == nil {
+				l.nets = append(l.nets, ipNet)
+				continue
+			}
+		}
+
 		l.ips[ip] = struct{}{}
 	}
 
 	return l
 }
 
+// WithTrustedHops returns a copy of l that gets the client IP from the
+// 'X-Forwarded-For' header by walking it from the right, past the addresses of
+// up to hops trusted proxies, including the one connecting to us, and returning
+// the first untrusted address. It guards against clients that send a made-up
+// 'X-Forwarded-For' header, which proxies append to. Zero or less keeps using
+// the first address.
+func (l List) WithTrustedHops(hops int) List {
+	l.trustedHops = hops
+	return l
+}
+
 // IsTrusted returns true if ip is trusted, otherwise false.
 func (l List) IsTrusted(ip string) bool {
 	if l.untrustAll {
 		return false
 	}
 
-	if len(l.ips) == 0 {
+	if len(l.ips) == 0 && len(l.nets) == 0 {
 		return true
 	}
 
-	_, ok := l.ips[ip]
-	return ok
+	if _, ok := l.ips[ip]; ok {
+		return true
+	}
+
+	if len(l.nets) > 0 {
+		if parsed := net.ParseIP(ip); parsed != nil {
+			for _, ipNet := range l.nets {
+				if ipNet.Contains(parsed) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
 }
 
 // GetClientIP gets the IP of the client from the 'Forwarded',
@@ -70,6 +108,13 @@ func (l List) IsTrusted(ip string) bool {
 func GetClientIP(l List, r *http.Request) string {
 	addr := stripPort(r.RemoteAddr)
 	if l.IsTrusted(addr) {
+		if l.trustedHops > 0 {
+			ip, ok := l.getIPFromForwardedFor(r.Header)
+			if ok {
+				return ip
+			}
+		}
+
 		ip, ok := GetIPFromHeaders(r.Header)
 		if ok {
 			return ip
@@ -132,6 +177,36 @@ func GetIPFromHeaders(headers http.Header) (string, bool) {
 	return "", false
 }
 
+// getIPFromForwardedFor walks the 'X-Forwarded-For' header from the right as
+// described by WithTrustedHops. It returns the IP and true if the header
+// exists, otherwise false.
+func (l List) getIPFromForwardedFor(headers http.Header) (string, bool) {
+	var chain []string
+	for _, h := range headers.Values("X-Forwarded-For") {
+		for _, ip := range strings.Split(h, ",") {
+			if ip = strings.TrimSpace(ip); ip != "" {
+				chain = append(chain, ip)
+			}
+		}
+	}
+	if len(chain) == 0 {
+		return "", false
+	}
+
+	// The proxy connecting to us is the first hop. Each entry to its left is the
+	// address of the hop before, which is skipped if it's a trusted proxy and
+	// there are hops left.
+	hops := 1
+	for i := len(chain) - 1; i > 0; i-- {
+		if hops >= l.trustedHops || !l.IsTrusted(chain[i]) {
+			return chain[i], true
+		}
+		hops++
+	}
+
+	return chain[0], true
+}
+
 // stripPort strips the port from addr when it has it and return the host
 // part. A host can be a hostname or an IP v4 or an IP v6.
 //
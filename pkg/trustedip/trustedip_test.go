@@ -249,6 +249,33 @@ func TestGetClientIP(t *testing.T) {
 			},
 			ip: "6e11:d5a8:b04d:9416:1f51:5262:15bc:4be6",
 		},
+		{
+			desc: "Trusted IP (v4) in CIDR range 'X-Forwarded-For'",
+			l:    trustedip.NewList("192.168.5.2", "10.5.2.0/24"),
+			r: &http.Request{
+				RemoteAddr: "10.5.2.77:5458",
+				Header:     map[string][]string{"X-Forwarded-For": {"172.28.254.80, 10.5.2.77"}},
+			},
+			ip: "172.28.254.80",
+		},
+		{
+			desc: "Trusted IP (v6) in CIDR range 'X-Forwarded-For'",
+			l:    trustedip.NewList("8428:f6d:9d3d:82cf::/64"),
+			r: &http.Request{
+				RemoteAddr: "[8428:f6d:9d3d:82cf:7190:3c31:3326:8484]:5458",
+				Header:     map[string][]string{"X-Forwarded-For": {"172.28.254.80"}},
+			},
+			ip: "172.28.254.80",
+		},
+		{
+			desc: "Untrusted IP (v4) outside CIDR range",
+			l:    trustedip.NewList("10.5.2.0/24"),
+			r: &http.Request{
+				RemoteAddr: "10.5.3.77:5458",
+				Header:     map[string][]string{"X-Forwarded-For": {"172.28.254.80"}},
+			},
+			ip: "10.5.3.77",
+		},
 	}
 
 	for _, tC := range testCases {
@@ -260,6 +287,110 @@ func TestGetClientIP(t *testing.T) {
 	}
 }
 
+func TestGetClientIPTrustedHops(t *testing.T) {
+	// client (172.17.5.10) -> proxy A (10.0.0.1) -> proxy B (10.0.0.2) -> us.
+	testCases := []struct {
+		desc          string
+		l             trustedip.List
+		xForwardedFor []string
+		ip            string
+	}{
+		{
+			desc:          "no hops uses the first address",
+			l:             trustedip.NewListTrustAll(),
+			xForwardedFor: []string{"1.2.3.4, 172.17.5.10, 10.0.0.1"},
+			ip:            "1.2.3.4",
+		},
+		{
+			desc:          "two hops",
+			l:             trustedip.NewListTrustAll().WithTrustedHops(2),
+			xForwardedFor: []string{"172.17.5.10, 10.0.0.1"},
+			ip:            "172.17.5.10",
+		},
+		{
+			desc:          "two hops with a made-up address",
+			l:             trustedip.NewListTrustAll().WithTrustedHops(2),
+			xForwardedFor: []string{"1.2.3.4, 172.17.5.10, 10.0.0.1"},
+			ip:            "172.17.5.10",
+		},
+		{
+			desc:          "two hops across headers",
+			l:             trustedip.NewListTrustAll().WithTrustedHops(2),
+			xForwardedFor: []string{"1.2.3.4", "172.17.5.10,10.0.0.1"},
+			ip:            "172.17.5.10",
+		},
+		{
+			desc:          "one hop",
+			l:             trustedip.NewListTrustAll().WithTrustedHops(1),
+			xForwardedFor: []string{"1.2.3.4, 172.17.5.10, 10.0.0.1"},
+			ip:            "10.0.0.1",
+		},
+		{
+			desc:          "more hops than addresses",
+			l:             trustedip.NewListTrustAll().WithTrustedHops(5),
+			xForwardedFor: []string{"172.17.5.10, 10.0.0.1"},
+			ip:            "172.17.5.10",
+		},
+		{
+			desc:          "trusted proxies",
+			l:             trustedip.NewList("10.0.0.0/24").WithTrustedHops(3),
+			xForwardedFor: []string{"1.2.3.4, 172.17.5.10, 10.0.0.1"},
+			ip:            "172.17.5.10",
+		},
+		{
+			desc:          "untrusted proxy",
+			l:             trustedip.NewList("10.0.0.2").WithTrustedHops(3),
+			xForwardedFor: []string{"1.2.3.4, 172.17.5.10, 10.0.0.1"},
+			ip:            "10.0.0.1",
+		},
+		{
+			desc:          "untrusted peer",
+			l:             trustedip.NewList("10.0.0.1").WithTrustedHops(2),
+			xForwardedFor: []string{"172.17.5.10, 10.0.0.1"},
+			ip:            "10.0.0.2",
+		},
+		{
+			desc: "no header",
+			l:    trustedip.NewListTrustAll().WithTrustedHops(2),
+			ip:   "10.0.0.2",
+		},
+	}
+
+	for _, tC := range testCases {
+		tC := tC
+		t.Run(tC.desc, func(t *testing.T) {
+			r := &http.Request{
+				RemoteAddr: "10.0.0.2:5458",
+				Header:     map[string][]string{},
+			}
+			if len(tC.xForwardedFor) > 0 {
+				r.Header["X-Forwarded-For"] = tC.xForwardedFor
+			}
+			assert.Equal(t, tC.ip, trustedip.GetClientIP(tC.l, r))
+		})
+	}
+}
+
+func TestListIsTrusted(t *testing.T) {
+	l := trustedip.NewList("192.168.5.2", "10.5.2.0/24", "fd00::/8", "10.9.0.0/33")
+
+	assert.True(t, l.IsTrusted("192.168.5.2"))
+	assert.True(t, l.IsTrusted("10.5.2.0"))
+	assert.True(t, l.IsTrusted("10.5.2.255"))
+	assert.True(t, l.IsTrusted("fd12:3456::1"))
+	assert.False(t, l.IsTrusted("192.168.5.3"))
+	assert.False(t, l.IsTrusted("10.5.3.1"))
+	assert.False(t, l.IsTrusted("fe80::1"))
+	assert.False(t, l.IsTrusted("not an ip"))
+	assert.False(t, l.IsTrusted(""))
+
+	// an invalid CIDR is kept as an exact value.
+	assert.True(t, l.IsTrusted("10.9.0.0/33"))
+	assert.False(t, l.IsTrusted("10.9.0.1"))
+
+	assert.True(t, trustedip.NewList().IsTrusted("10.5.2.1"))
+}
+
 func TestGetClientIPFromHeaders(t *testing.T) {
 	testCases := []struct {
 		desc string
@@ -52,7 +52,7 @@ func TestAuthservice(t *testing.T) {
 		require.NoError(t, err)
 
 		runTest := func(addr, serialized string, test func(resp authclient.AuthServiceResponse)) {
-			creds, err := register.Access(ctx, addr, serialized, false)
+			creds, err := register.Access(ctx, addr, serialized, false, 0)
 			require.NoError(t, err)
 
 			resp, err := env.authClient.Resolve(ctx, creds.AccessKeyID, "")
@@ -90,6 +90,36 @@ func TestAuthservice(t *testing.T) {
 	})
 }
 
+func TestValidateAccess(t *testing.T) {
+	t.Parallel()
+
+	runEnvironment(t, reconfigure{}, func(t *testing.T, ctx *testcontext.Context, env *environment) {
+		sat := env.planet.Satellites[0]
+
+		serialized, err := env.planet.Uplinks[0].Access[sat.ID()].Serialize()
+		require.NoError(t, err)
+
+		diagnostics, err := register.Validate(ctx, serialized)
+		require.NoError(t, err)
+		require.Equal(t, env.planet.Uplinks[0].Projects[0].PublicID.String(), diagnostics.PublicProjectID)
+		require.True(t, diagnostics.AllowsWrites)
+		require.Nil(t, diagnostics.Expiration)
+
+		apiKey, err := macaroon.NewAPIKey([]byte("secret"))
+		require.NoError(t, err)
+
+		unknownKey, err := (&grant.Access{
+			SatelliteAddress: sat.NodeURL().String(),
+			APIKey:           apiKey,
+			EncAccess:        grant.NewEncryptionAccess(),
+		}).Serialize()
+		require.NoError(t, err)
+
+		_, err = register.Validate(ctx, unknownKey)
+		require.Error(t, err)
+	})
+}
+
 // TODO(jeremy, artur): this test has a potential to be flaky due to the
 // time-based nature of the test.
 func TestAccessExpiration(t *testing.T) {
@@ -157,7 +187,7 @@ func TestAccessExpiration(t *testing.T) {
 				{name: "DRPC", addr: "drpc://" + env.auth.DRPCAddress()},
 			} {
 				t.Run(tt.name, func(t *testing.T) {
-					creds, err := register.Access(ctx, tt.addr, testCase.serializedAccess, testCase.public)
+					creds, err := register.Access(ctx, tt.addr, testCase.serializedAccess, testCase.public, 0)
 					require.NoError(t, err)
 
 					if testCase.expectedRestrictedExpiration != nil {
@@ -228,6 +228,7 @@ func testHandlerRequests(t *testing.T, ctx *testcontext.Context, planet *testpla
 		body                  []string
 		notContains           []string
 		downloadPrefixEnabled bool
+		disableMap            bool
 		zipContent            map[string]string
 		tarContent            map[string]string
 		listPageLimit         *listPageLimit
@@ -329,6 +330,25 @@ func testHandlerRequests(t *testing.T, ctx *testcontext.Context, planet *testpla
 			body:             []string{"circle"},
 			expectedRPCCalls: []string{"/metainfo.Metainfo/CompressedBatch" /* GetObject */, "/metainfo.Metainfo/GetObjectIPs"},
 		},
+		{
+			name:             "GET success map disabled",
+			method:           "GET",
+			path:             path.Join("s", serializedAccess, "testbucket", "test/foo"),
+			status:           http.StatusOK,
+			disableMap:       true,
+			body:             []string{"This file is ready for download"},
+			notContains:      []string{"?map=1"},
+			expectedRPCCalls: []string{"/metainfo.Metainfo/CompressedBatch" /* GetObject */},
+		},
+		{
+			name:             "GET map only map disabled",
+			method:           "GET",
+			path:             path.Join("s", serializedAccess, "testbucket", "test/foo?map=1"),
+			status:           http.StatusNotFound,
+			disableMap:       true,
+			notContains:      []string{"circle"},
+			expectedRPCCalls: []string{"/metainfo.Metainfo/CompressedBatch" /* GetObject */},
+		},
 		{
 			name:             "GET view",
 			method:           "GET",
@@ -446,6 +466,30 @@ func testHandlerRequests(t *testing.T, ctx *testcontext.Context, planet *testpla
 			body:             []string{"FOO"},
 			expectedRPCCalls: []string{"/metainfo.Metainfo/CompressedBatch" /* DownloadObject */},
 		},
+		{
+			name:   "GET download with multiple ranges",
+			method: "GET",
+			path:   path.Join("raw", serializedAccess, "testbucket", "test/foo"),
+			reqHeader: map[string]string{
+				"Range": "bytes=0-1,-3",
+			},
+			status: http.StatusPartialContent,
+			body: []string{
+				"Content-Range: bytes 0-1/6", "FO",
+				"Content-Range: bytes 3-5/6", "BAR",
+			},
+		},
+		{
+			name:   "GET download with multiple ranges larger than the object",
+			method: "GET",
+			path:   path.Join("raw", serializedAccess, "testbucket", "test/foo"),
+			reqHeader: map[string]string{
+				"Range": "bytes=0-5,0-5",
+			},
+			status:      http.StatusOK,
+			body:        []string{"FOOBAR"},
+			notContains: []string{"Content-Range"},
+		},
 		{
 			name:   "GET download not modified modtime",
 			method: "GET",
@@ -710,6 +754,14 @@ func testHandlerRequests(t *testing.T, ctx *testcontext.Context, planet *testpla
 			body:             []string{""},
 			expectedRPCCalls: []string{"/metainfo.Metainfo/CompressedBatch" /* GetObject */, "/metainfo.Metainfo/GetObjectIPs"},
 		},
+		{
+			name:             "HEAD download success",
+			method:           "HEAD",
+			path:             path.Join("raw", serializedAccess, "testbucket", "test/foo"),
+			status:           http.StatusOK,
+			body:             []string{""},
+			expectedRPCCalls: []string{"/metainfo.Metainfo/CompressedBatch" /* GetObject */},
+		},
 		{
 			name:             "GET download when exceeded bandwidth limit",
 			method:           "GET",
@@ -1287,6 +1339,7 @@ To download a larger number of objects at once, download the prefix using the ta
 				ListPageLimit:         listPageLimit,
 				DownloadPrefixEnabled: testCase.downloadPrefixEnabled,
 				DownloadZipLimit:      6,
+				DisableMap:            testCase.disableMap,
 			})
 			require.Equal(t, testCase.newHandlerErr, err)
 			if testCase.newHandlerErr != nil {
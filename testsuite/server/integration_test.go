@@ -24,6 +24,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/signer"
 	"github.com/minio/minio-go/v7/pkg/tags"
 	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/require"
@@ -1481,6 +1482,80 @@ func TestObjectAttributes(t *testing.T) {
 	})
 }
 
+func TestListObjectsV2Delimiter(t *testing.T) {
+	t.Parallel()
+
+	runTest(t, testplanet.Config{
+		SatelliteCount:   1,
+		StorageNodeCount: 0,
+		UplinkCount:      1,
+	}, nil, func(ctx *testcontext.Context, planet *testplanet.Planet, gateway *server.Peer, auth *auth.Peer, creds register.Credentials) {
+		client := createS3Client(t, gateway.Address(), creds.AccessKeyID, creds.SecretKey)
+
+		bucket := testrand.BucketName()
+		require.NoError(t, createBucket(ctx, client, bucket, false, false))
+
+		for _, key := range []string{"a/1", "a/2", "a/b/3", "b/4", "c", "e/f/5"} {
+			_, err := client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+				Bucket: aws.String(bucket),
+				Key:    aws.String(key),
+				Body:   bytes.NewReader(testrand.Bytes(10 * memory.B)),
+			})
+			require.NoError(t, err)
+		}
+
+		// listAll lists all pages of at most maxKeys entries, returning the
+		// object keys and common prefixes from all of them.
+		listAll := func(t *testing.T, prefix string, maxKeys int64) (keys, prefixes []string) {
+			var token *string
+			for {
+				resp, err := client.ListObjectsV2WithContext(ctx, &s3.ListObjectsV2Input{
+					Bucket:            aws.String(bucket),
+					Prefix:            aws.String(prefix),
+					Delimiter:         aws.String("/"),
+					MaxKeys:           aws.Int64(maxKeys),
+					ContinuationToken: token,
+				})
+				require.NoError(t, err)
+				require.LessOrEqual(t, int64(len(resp.Contents)+len(resp.CommonPrefixes)), maxKeys)
+
+				for _, object := range resp.Contents {
+					keys = append(keys, aws.StringValue(object.Key))
+				}
+				for _, commonPrefix := range resp.CommonPrefixes {
+					prefixes = append(prefixes, aws.StringValue(commonPrefix.Prefix))
+				}
+
+				if !aws.BoolValue(resp.IsTruncated) {
+					return keys, prefixes
+				}
+				require.NotEmpty(t, aws.StringValue(resp.NextContinuationToken))
+				token = resp.NextContinuationToken
+			}
+		}
+
+		for _, tc := range []struct {
+			prefix   string
+			keys     []string
+			prefixes []string
+		}{
+			{prefix: "", keys: []string{"c"}, prefixes: []string{"a/", "b/", "e/"}},
+			{prefix: "a/", keys: []string{"a/1", "a/2"}, prefixes: []string{"a/b/"}},
+			{prefix: "a/b/", keys: []string{"a/b/3"}},
+			{prefix: "e/", prefixes: []string{"e/f/"}},
+			{prefix: "missing/"},
+		} {
+			for _, maxKeys := range []int64{1, 2, 1000} {
+				t.Run(fmt.Sprintf("prefix %q max-keys %d", tc.prefix, maxKeys), func(t *testing.T) {
+					keys, prefixes := listAll(t, tc.prefix, maxKeys)
+					require.Equal(t, tc.keys, keys)
+					require.Equal(t, tc.prefixes, prefixes)
+				})
+			}
+		}
+	})
+}
+
 func TestConditionalWrites(t *testing.T) {
 	t.Parallel()
 
@@ -1743,7 +1818,7 @@ func runTest(
 		serialized, err := planet.Uplinks[0].Access[planet.Satellites[0].ID()].Serialize()
 		require.NoError(t, err)
 
-		creds, err := register.Access(ctx, "http://"+auth.Address(), serialized, false)
+		creds, err := register.Access(ctx, "http://"+auth.Address(), serialized, false, 0)
 		require.NoError(t, err)
 
 		// Set the correct endpoint now that we know where gateway is.
@@ -1757,6 +1832,81 @@ func runTest(
 	})
 }
 
+func TestStreamingSignedUpload(t *testing.T) {
+	t.Parallel()
+
+	runTest(t, testplanet.Config{
+		SatelliteCount:   1,
+		StorageNodeCount: 4,
+		UplinkCount:      1,
+	}, nil, func(ctx *testcontext.Context, planet *testplanet.Planet, gateway *server.Peer, auth *auth.Peer, creds register.Credentials) {
+		client := createS3Client(t, gateway.Address(), creds.AccessKeyID, creds.SecretKey)
+
+		bucket := testrand.BucketName()
+		require.NoError(t, createBucket(ctx, client, bucket, false, false))
+
+		// more than one 64 KiB chunk, so that chunk boundaries are tested too.
+		data := testrand.Bytes(200 * memory.KiB)
+
+		// newRequest returns the body of a PutObject request of data signed
+		// with STREAMING-AWS4-HMAC-SHA256-PAYLOAD, i.e. split into aws-chunked
+		// chunks that are signed one by one.
+		newRequest := func(key string) (*http.Request, []byte) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPut, "http://"+gateway.Address()+"/"+bucket+"/"+key, bytes.NewReader(data))
+			require.NoError(t, err)
+			req = signer.StreamingSignV4(req, creds.AccessKeyID, creds.SecretKey, "", "global", int64(len(data)), time.Now().UTC())
+			require.Equal(t, "STREAMING-AWS4-HMAC-SHA256-PAYLOAD", req.Header.Get("X-Amz-Content-Sha256"))
+
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.Greater(t, len(body), len(data))
+			return req, body
+		}
+
+		send := func(req *http.Request, body []byte) *http.Response {
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			_, err = io.Copy(io.Discard, resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			return resp
+		}
+
+		t.Run("valid", func(t *testing.T) {
+			key := string(testrand.RandAlphaNumeric(16))
+
+			resp := send(newRequest(key))
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			// the chunk framing isn't part of the stored object.
+			download, err := client.GetObjectWithContext(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+			require.NoError(t, err)
+			downloaded, err := io.ReadAll(download.Body)
+			require.NoError(t, err)
+			require.NoError(t, download.Body.Close())
+			require.Equal(t, data, downloaded)
+		})
+
+		t.Run("tampered chunk", func(t *testing.T) {
+			key := string(testrand.RandAlphaNumeric(16))
+
+			req, body := newRequest(key)
+			// flip a byte of the data of the first chunk, right after its
+			// signed header.
+			i := bytes.Index(body, []byte("\r\n"))
+			require.Positive(t, i)
+			body[i+2] ^= 0xff
+
+			resp := send(req, body)
+			require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+			_, err := client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+			require.Error(t, err)
+		})
+	})
+}
+
 func createS3Client(t *testing.T, gatewayAddr, accessKeyID, secretKey string) *s3.S3 {
 	sess, err := session.NewSession(&aws.Config{
 		Region:           aws.String("global"),
@@ -1779,7 +1929,7 @@ func registerAccess(ctx context.Context, t *testing.T, encAccess *grant.Encrypti
 	serialized, err := restrictedAccess.Serialize()
 	require.NoError(t, err)
 
-	creds, err := register.Access(ctx, "http://"+authAddr, serialized, false)
+	creds, err := register.Access(ctx, "http://"+authAddr, serialized, false, 0)
 	require.NoError(t, err)
 
 	return creds